@@ -3,6 +3,7 @@ package agenda
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,14 +26,16 @@ var logger = log.With().Str("component", "agenda").Logger()
 //go:embed agenda-next.md
 var agendaNextTemplate []byte
 
-func Agenda(printOnly bool) {
+// Agenda creates the issue for the next monthly chat and resets the agenda page of the wiki.
+// With `printOnly`, the body of the issue is printed instead.
+func Agenda(printOnly bool) error {
 	opts := api.ClientOptions{
 		Headers: map[string]string{"Accept": "application/vnd.github+json"},
 		Timeout: 30 * time.Second,
 	}
 	client, err := api.NewRESTClient(opts)
 	if err != nil {
-		logger.Fatal().Err(err).Send()
+		return fmt.Errorf("failed to create GH REST client: %w", err)
 	}
 
 	type issueBody struct {
@@ -51,7 +54,7 @@ func Agenda(printOnly bool) {
 	logger.Info().Msg("Cloning wiki repository")
 	tempDir, err := os.MkdirTemp("", "crs-wiki")
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to create temporary directory for cloning the wiki repository")
+		return fmt.Errorf("failed to create temporary directory for cloning the wiki repository: %w", err)
 	}
 	defer func() {
 		err := os.RemoveAll(tempDir)
@@ -59,15 +62,20 @@ func Agenda(printOnly bool) {
 			logger.Error().Err(err).Msgf("Failed to delete wiki directory %s", tempDir)
 		}
 	}()
-	cloneWiki(tempDir, "wiki")
+	if err := cloneWiki(tempDir, "wiki"); err != nil {
+		return err
+	}
 	wikiDir := filepath.Join(tempDir, "wiki")
 
-	agendaBody := buildAgendaBody(client, wikiDir, previousDate, nextDate)
+	agendaBody, err := buildAgendaBody(client, wikiDir, previousDate, nextDate)
+	if err != nil {
+		return err
+	}
 	if printOnly {
 		if _, err := io.WriteString(os.Stdout, agendaBody); err != nil {
 			logger.Error().Err(err).Msg("Failed to print agenda body to terminal")
 		}
-		return
+		return nil
 	}
 
 	bodyJson, err := json.Marshal(&issueBody{
@@ -76,49 +84,62 @@ func Agenda(printOnly bool) {
 		Body:   agendaBody,
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to serialize body of GH REST request")
+		return fmt.Errorf("failed to serialize body of GH REST request: %w", err)
 	}
 
 	logger.Info().Msg("Creating new agenda issue")
 	response, err := client.Request(http.MethodPost, "repos/coreruleset/coreruleset/issues", bytes.NewReader(bodyJson))
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Creating agenda failed")
+		return fmt.Errorf("creating agenda failed: %w", err)
 	}
 	defer response.Body.Close()
 
 	logger.Info().Msg("New Agenda successfully created")
 
 	logger.Info().Msg(`Resetting "Agenda-Next" wiki page`)
-	resetAgendaNext(wikiDir)
+	if err := resetAgendaNext(wikiDir); err != nil {
+		return err
+	}
 	logger.Info().Msg("Done")
+	return nil
 }
 
-func resetAgendaNext(wikiDir string) {
+func resetAgendaNext(wikiDir string) error {
 	if err := os.WriteFile(filepath.Join(wikiDir, "Agenda-Next.md"), agendaNextTemplate, 0644); err != nil {
-		logger.Fatal().Msg(`Failed to write "Agenda-Next.md"`)
+		return fmt.Errorf(`failed to write "Agenda-Next.md": %w`, err)
 	}
 	out, err := utils.RunGit(wikiDir, "commit", "-m", "Reset Agenda-Next.md", "Agenda-Next.md")
 	if err != nil {
-		logger.Fatal().Err(err).Msgf(`Failed to commit "Agenda-Next": %s`, out)
+		return fmt.Errorf(`failed to commit "Agenda-Next": %w: %s`, err, out)
 	}
 	out, err = utils.RunGit(wikiDir, "push")
 	if err != nil {
-		logger.Fatal().Err(err).Msgf(`Failed to push "Agenda-Next": %s`, out)
+		return fmt.Errorf(`failed to push "Agenda-Next": %w: %s`, err, out)
 	}
+	return nil
 }
 
-func buildAgendaBody(client *api.RESTClient, wikiDir string, previousDate time.Time, nextDate time.Time) string {
+func buildAgendaBody(client *api.RESTClient, wikiDir string, previousDate time.Time, nextDate time.Time) (string, error) {
 	logger.Info().Msg("Building issue body")
 	agendaPath := filepath.Join(wikiDir, "Agenda-Next.md")
 	template, err := os.ReadFile(agendaPath)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to read meeting agenda")
+		return "", fmt.Errorf("failed to read meeting agenda: %w", err)
 	}
 
 	logger.Info().Msg("Fetching PR statistics from GitHub")
-	mergedPrs := getMergedPrsString(client, previousDate)
-	openPrs := getOpenPrsString(client)
-	wipPrs := getWipPrsString(client)
+	mergedPrs, err := getMergedPrsString(client, previousDate)
+	if err != nil {
+		return "", err
+	}
+	openPrs, err := getOpenPrsString(client)
+	if err != nil {
+		return "", err
+	}
+	wipPrs, err := getWipPrsString(client)
+	if err != nil {
+		return "", err
+	}
 
 	logger.Info().Msg("Updating agenda with computed information")
 	prsString := fmt.Sprintf(`### PRs that have been merged since the last meeting
@@ -134,10 +155,10 @@ func buildAgendaBody(client *api.RESTClient, wikiDir string, previousDate time.T
 %s`,
 		mergedPrs, openPrs, wipPrs)
 	templateString := strings.Replace(string(template), "{{PR_STATS}}", prsString, 1)
-	return strings.Replace(templateString, "{{CHAT_DATE}}", nextDate.Format(time.DateOnly), 1)
+	return strings.Replace(templateString, "{{CHAT_DATE}}", nextDate.Format(time.DateOnly), 1), nil
 }
 
-func cloneWiki(path string, repoName string) {
+func cloneWiki(path string, repoName string) error {
 	remoteCandidates := []string{
 		"git@github.com:coreruleset/coreruleset.wiki.git",
 		"https://github.com/coreruleset/coreruleset.wiki.git",
@@ -154,39 +175,45 @@ func cloneWiki(path string, repoName string) {
 		break
 	}
 	if !succeeded {
-		logger.Fatal().Msg("Failed to clone wiki using both SSH and HTTPS; giving up")
+		return errors.New("failed to clone wiki using both SSH and HTTPS; giving up")
 	}
-
+	return nil
 }
 
-func getMergedPrsString(client *api.RESTClient, since time.Time) string {
-	prs := searchPrs(client, fmt.Sprintf("is:pr is:merged closed:>=%s", since.Format(time.DateOnly)), "updated", "desc")
+func getMergedPrsString(client *api.RESTClient, since time.Time) (string, error) {
+	prs, err := searchPrs(client, fmt.Sprintf("is:pr is:merged closed:>=%s", since.Format(time.DateOnly)), "updated", "desc")
+	if err != nil {
+		return "", err
+	}
 	logger.Debug().Msgf("Found %d merged PRs", len(prs))
-	return buildPrsString(prs)
+	return buildPrsString(prs), nil
 }
 
-func getOpenPrsString(client *api.RESTClient) string {
-	prs := searchPrs(client, `is:pr is:open draft:false -label:"needs action","work in progress"`, "updated", "desc")
+func getOpenPrsString(client *api.RESTClient) (string, error) {
+	prs, err := searchPrs(client, `is:pr is:open draft:false -label:"needs action","work in progress"`, "updated", "desc")
+	if err != nil {
+		return "", err
+	}
 	logger.Debug().Msgf("Found %d open PRs", len(prs))
-	return buildPrsString(prs)
+	return buildPrsString(prs), nil
 }
 
-func getWipPrsString(client *api.RESTClient) string {
-	prs := searchPrs(client, `is:pr is:open label:"needs action","work in progress"`, "interactions", "desc")
+func getWipPrsString(client *api.RESTClient) (string, error) {
+	prs, err := searchPrs(client, `is:pr is:open label:"needs action","work in progress"`, "interactions", "desc")
+	if err != nil {
+		return "", err
+	}
 	logger.Debug().Msgf("Found %d WIP PRs", len(prs))
-	return buildPrsString(prs)
+	return buildPrsString(prs), nil
 }
 
 func buildPrsString(prs []int) string {
 	sb := strings.Builder{}
 	for i, id := range prs {
-		if _, err := fmt.Fprintf(&sb, "- #%d", id); err != nil {
-			logger.Fatal().Err(err).Msg("Failed to write string buffer")
-		}
+		// Writing to a strings.Builder doesn't fail
+		fmt.Fprintf(&sb, "- #%d", id)
 		if i < len(prs)-1 {
-			if _, err := sb.WriteRune('\n'); err != nil {
-				logger.Fatal().Err(err).Msg("Failed to write string buffer")
-			}
+			sb.WriteRune('\n')
 		}
 	}
 
@@ -198,7 +225,7 @@ func buildPrsString(prs []int) string {
 	return prsString
 }
 
-func searchPrs(client *api.RESTClient, query string, sort string, order string) []int {
+func searchPrs(client *api.RESTClient, query string, sort string, order string) ([]int, error) {
 	searchQuery := url.QueryEscape(query + " repo:coreruleset/coreruleset")
 	// We don't use pagination for now and simply expect that we don't exceed 100 results
 	url := fmt.Sprintf("%s?q=%s&sort=%s&order=%s&per_page=100", "search/issues", searchQuery, sort, order)
@@ -211,14 +238,14 @@ func searchPrs(client *api.RESTClient, query string, sort string, order string)
 	}{}
 	err := client.Get(url, &response)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Fetching PRs failed")
+		return nil, fmt.Errorf("fetching PRs failed: %w", err)
 	}
 
 	ids := []int{}
 	for _, item := range response.Items {
 		ids = append(ids, item.Number)
 	}
-	return ids
+	return ids, nil
 }
 
 func computeNextDate(now time.Time) time.Time {
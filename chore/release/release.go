@@ -32,71 +32,83 @@ const (
 
 var logger = log.With().Str("component", "release").Logger()
 
-func Release(context *context.Context, repositoryPath string, version *semver.Version, sourceRef string) {
-	remoteName := findRemoteName(context.RootDir())
+// Release creates a release branch for `version` from `sourceRef`, updates copyright notices and
+// supported versions, pushes the branch and opens a pull request for it.
+func Release(context *context.Context, repositoryPath string, version *semver.Version, sourceRef string) error {
+	remoteName, err := findRemoteName(context.RootDir())
+	if err != nil {
+		return err
+	}
 	if remoteName == "" {
-		logger.Fatal().Msg("failed to find remote for coreruleset/coreruleset")
+		return errors.New("failed to find remote for coreruleset/coreruleset")
+	}
+	if err := fetchSourceRef(context.RootDir(), remoteName, sourceRef); err != nil {
+		return err
 	}
-	fetchSourceRef(context.RootDir(), remoteName, sourceRef)
 	branchName := fmt.Sprintf(branchNameTemplate, version.Major(), version.Minor(), version.Patch())
-	createAndCheckOutBranch(context, branchName, sourceRef)
-	copyright.UpdateCopyright(context, version, uint16(time.Now().Year()), []string{examplesPath})
+	if err := createAndCheckOutBranch(context, branchName, sourceRef); err != nil {
+		return err
+	}
+	if err := copyright.UpdateCopyright(context, version, uint16(time.Now().Year()), []string{examplesPath}); err != nil {
+		return err
+	}
 	updateSecurityReadme(context, version)
-	createCommit(context, branchName)
-	pushBranch(context.RootDir(), remoteName, branchName)
-	createPullRequest(version, branchName, sourceRef)
+	if err := createCommit(context, branchName); err != nil {
+		return err
+	}
+	if err := pushBranch(context.RootDir(), remoteName, branchName); err != nil {
+		return err
+	}
+	return createPullRequest(version, branchName, sourceRef)
 }
 
-func createAndCheckOutBranch(context *context.Context, branchName string, sourceRef string) {
+func createAndCheckOutBranch(context *context.Context, branchName string, sourceRef string) error {
 	if err := checkForCleanWorkTree(context); err != nil {
-		// FIXME
-		panic(err)
+		return err
 	}
 
 	out, err := utils.RunGit(context.RootDir(), "switch", "-c", branchName, sourceRef)
 	if err != nil {
-		logger.Fatal().Err(err).Bytes("command-output", out).Msg("failed to create commit for release")
+		return fmt.Errorf("failed to create branch %s for release: %w: %s", branchName, err, out)
 	}
+	return nil
 }
 
-func createCommit(context *context.Context, branchName string) {
+func createCommit(context *context.Context, branchName string) error {
 	out, err := utils.RunGit(context.RootDir(), "commit", "-am", "chore: release "+branchName)
 	if err != nil {
-		logger.Fatal().Err(err).Bytes("command-output", out).Msg("failed to create commit for release")
+		return fmt.Errorf("failed to create commit for release: %w: %s", err, out)
 	}
+	return nil
 }
 
 func checkForCleanWorkTree(context *context.Context) error {
 	repositoryPath := context.RootDir()
 	repo, err := git.PlainOpen(repositoryPath)
 	if err != nil {
-		//FIXME
-		panic(err)
+		return fmt.Errorf("failed to open repository %s: %w", repositoryPath, err)
 	}
 	worktree, err := repo.Worktree()
 	if err != nil {
-		//FIXME
-		panic(err)
+		return fmt.Errorf("failed to open worktree of %s: %w", repositoryPath, err)
 	}
 	status, err := worktree.Status()
 	if err != nil {
-		//FIXME
-		panic(err)
+		return fmt.Errorf("failed to read status of %s: %w", repositoryPath, err)
 	}
 	if !status.IsClean() {
-		// FIXME
 		return errors.New("worktree not clean. Please stash or commit your changes first")
 	}
 	return nil
 }
 
-func createPullRequest(version *semver.Version, branchName string, targetBranchName string) {
+func createPullRequest(version *semver.Version, branchName string, targetBranchName string) error {
 	opts := api.ClientOptions{
 		Headers: map[string]string{"Accept": "application/octet-stream"},
 	}
 	client, err := api.NewRESTClient(opts)
 	if err != nil {
-		logger.Fatal().Err(err).Send()
+		return fmt.Errorf("failed to create GH REST client: %w", err)
 	}
 
 	type prBody struct {
@@ -114,34 +126,36 @@ func createPullRequest(version *semver.Version, branchName string, targetBranchN
 		Reviewer: "coreruleset/core-developers",
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to serialize body of GH REST request")
+		return fmt.Errorf("failed to serialize body of GH REST request: %w", err)
 	}
 
 	response, err := client.Request(http.MethodPost, "repos/coreruleset/coreruleset/pulls", bytes.NewReader(bodyJson))
 	if err != nil {
-		logger.Fatal().Err(err).Msg("creating PR failed")
+		return fmt.Errorf("creating PR failed: %w", err)
 	}
-	defer response.Body.Close()
+	return response.Body.Close()
 }
 
-func pushBranch(repositoryDir string, remoteName string, branchName string) {
+func pushBranch(repositoryDir string, remoteName string, branchName string) error {
 	out, err := utils.RunGit(repositoryDir, "push", remoteName, branchName)
 	if err != nil {
-		logger.Fatal().Err(err).Bytes("command-output", out)
+		return fmt.Errorf("failed to push branch %s: %w: %s", branchName, err, out)
 	}
+	return nil
 }
 
-func fetchSourceRef(repositoryDir string, remoteName string, sourceRef string) {
+func fetchSourceRef(repositoryDir string, remoteName string, sourceRef string) error {
 	out, err := utils.RunGit(repositoryDir, "fetch", remoteName, sourceRef)
 	if err != nil {
-		logger.Fatal().Err(err).Bytes("command-output", out)
+		return fmt.Errorf("failed to fetch %s: %w: %s", sourceRef, err, out)
 	}
+	return nil
 }
 
-func findRemoteName(repositoryDir string) string {
+func findRemoteName(repositoryDir string) (string, error) {
 	out, err := utils.RunGit(repositoryDir, "remote", "-v")
 	if err != nil {
-		logger.Fatal().Err(err).Bytes("command-output", out)
+		return "", fmt.Errorf("failed to list remotes: %w: %s", err, out)
 	}
 	var remoteName string
 	scanner := bufio.NewScanner(bytes.NewReader(out))
@@ -152,7 +166,7 @@ func findRemoteName(repositoryDir string) string {
 		}
 	}
 
-	return remoteName
+	return remoteName, nil
 }
 
 func updateSecurityReadme(context *context.Context, newVersion *semver.Version) {
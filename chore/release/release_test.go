@@ -45,7 +45,8 @@ func TestRunChoreReleaseTestSuite(t *testing.T) {
 func (s *choreReleaseTestSuite) TestCreateAndcheckoutBranch() {
 	branchName := "v1.2.3"
 	ctxt := context.New(s.repoDir, "")
-	createAndCheckOutBranch(ctxt, branchName, "main")
+	err := createAndCheckOutBranch(ctxt, branchName, "main")
+	s.Require().NoError(err)
 	repo, err := git.PlainOpen(s.repoDir)
 	s.Require().NoError(err)
 
@@ -73,17 +74,19 @@ func (s *choreReleaseTestSuite) TestCreateAndcheckoutBranch() {
 func (s *choreReleaseTestSuite) TestCreateCommit() {
 	branchName := "v1.2.3"
 	ctxt := context.New(s.repoDir, "")
-	createAndCheckOutBranch(ctxt, branchName, "main")
+	err := createAndCheckOutBranch(ctxt, branchName, "main")
+	s.Require().NoError(err)
 
 	// Add something to commit, as `createCommit` doesn't allow empty commits
-	err := os.WriteFile(path.Join(s.repoDir, "file"), []byte("content"), os.ModePerm)
+	err = os.WriteFile(path.Join(s.repoDir, "file"), []byte("content"), os.ModePerm)
 	s.Require().NoError(err)
 	cmd := exec.Command("git", "add", ".")
 	cmd.Dir = s.repoDir
 	err = cmd.Run()
 	s.Require().NoError(err)
 
-	createCommit(ctxt, branchName)
+	err = createCommit(ctxt, branchName)
+	s.Require().NoError(err)
 
 	repo, err := git.PlainOpen(s.repoDir)
 	s.Require().NoError(err)
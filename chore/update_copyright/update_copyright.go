@@ -20,7 +20,7 @@ import (
 var logger = log.With().Str("component", "update-copyright").Logger()
 
 // UpdateCopyright updates the copyright portion of the rules files to the provided year and version.
-func UpdateCopyright(ctxt *context.Context, version *semver.Version, year uint16, ignoredPaths []string) {
+func UpdateCopyright(ctxt *context.Context, version *semver.Version, year uint16, ignoredPaths []string) error {
 	err := filepath.WalkDir(ctxt.RootDir(), func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			// abort
@@ -47,8 +47,9 @@ func UpdateCopyright(ctxt *context.Context, version *semver.Version, year uint16
 		return nil
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to update copyright")
+		return fmt.Errorf("failed to update copyright: %w", err)
 	}
+	return nil
 }
 
 func processFile(filePath string, version *semver.Version, year uint16) error {
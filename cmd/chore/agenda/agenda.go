@@ -1,7 +1,6 @@
 package agenda
 
 import (
-	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	chore "github.com/coreruleset/crs-toolchain/v2/chore/agenda"
@@ -17,12 +16,12 @@ The command will fetch the "Agenda-Next" wiki page and replace the template stri
 to create the new chat agenda issue.
 Finally, the command will reset the "Agenda-Next" wiki page.`,
 		Args: cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			printOnly, err := cmd.Flags().GetBool("print")
 			if err != nil {
-				log.Fatal().Err(err).Send()
+				return err
 			}
-			chore.Agenda(printOnly)
+			return chore.Agenda(printOnly)
 		},
 	}
 	buildFlags(cmd)
@@ -41,9 +41,9 @@ func New(cmdContext *internal.CommandContext) *cobra.Command {
 
 			return nil
 		},
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			rootContext := context.New(cmdContext.WorkingDirectory, cmdContext.ConfigurationFileName)
-			release.Release(rootContext, repositoryPath, version, sourceRef)
+			return release.Release(rootContext, repositoryPath, version, sourceRef)
 		},
 	}
 	buildFlags(cmd)
@@ -5,6 +5,7 @@ package updateCopyright
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -34,16 +35,16 @@ func New(cmdContext *internal.CommandContext) *cobra.Command {
 			}
 			return nil
 		},
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			year, err := strconv.ParseUint(copyrightVariables.Year, 0, 16)
 			if err != nil {
-				panic("Failed to parse year")
+				return fmt.Errorf("failed to parse year: %w", err)
 			}
 			version, err := semver.NewVersion(copyrightVariables.Version)
 			if err != nil {
-				panic("Failed to parse version as semver")
+				return fmt.Errorf("failed to parse version as semver: %w", err)
 			}
-			updateCopyright.UpdateCopyright(cmdContext.RootContext(), version, uint16(year), []string{})
+			return updateCopyright.UpdateCopyright(cmdContext.RootContext(), version, uint16(year), []string{})
 		},
 	}
 
@@ -40,6 +40,9 @@ type ConfigurationFileNameFlag struct {
 const (
 	Text   string = "text"
 	GitHub string = "github"
+	Json   string = "json"
+	Sarif  string = "sarif"
+	JUnit  string = "junit"
 )
 
 func (o *OutputTypeFlag) String() string {
@@ -48,7 +51,7 @@ func (o *OutputTypeFlag) String() string {
 
 func (o *OutputTypeFlag) Set(value string) error {
 	switch value {
-	case Text, GitHub:
+	case Text, GitHub, Json, Sarif, JUnit:
 		o.Context.Output = value
 		return nil
 	default:
@@ -0,0 +1,103 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The types in this file describe JUnit XML reports, as understood by GitLab, Jenkins and most
+// other CI systems. Each check is a test suite, each checked item a test case. Diagnostics of
+// an item are reported as a single failure of its test case.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes `cases` to `writer` as a JUnit XML report. Test suites and test cases are
+// written in the order they were first recorded.
+func writeJUnit(writer io.Writer, cases []*testCase) error {
+	report := junitTestSuites{Name: toolName, Suites: []junitTestSuite{}}
+	suites := map[string]int{}
+	for _, item := range cases {
+		index, ok := suites[item.check]
+		if !ok {
+			index = len(report.Suites)
+			suites[item.check] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: item.check})
+		}
+		suite := &report.Suites[index]
+		junitCase := junitTestCase{Name: item.name, ClassName: item.check, File: item.file}
+		if len(item.diagnostics) > 0 {
+			junitCase.Failure = newJUnitFailure(item.diagnostics)
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, junitCase)
+		suite.Tests++
+		report.Tests++
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// newJUnitFailure creates a failure with the message of the first diagnostic. The text of the
+// failure lists all diagnostics with their locations.
+func newJUnitFailure(diagnostics []Diagnostic) *junitFailure {
+	var text strings.Builder
+	for _, diagnostic := range diagnostics {
+		location := diagnostic.File
+		if diagnostic.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, diagnostic.Line)
+			if diagnostic.Column > 0 {
+				location = fmt.Sprintf("%s:%d", location, diagnostic.Column)
+			}
+		}
+		if location != "" {
+			text.WriteString(location + ": ")
+		}
+		text.WriteString(diagnostic.Message + "\n")
+	}
+	return &junitFailure{
+		Message: diagnostics[0].Message,
+		Type:    string(diagnostics[0].Level),
+		Text:    text.String(),
+	}
+}
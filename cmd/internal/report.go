@@ -0,0 +1,171 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/coreruleset/crs-toolchain/v2/utils"
+)
+
+// Level is the severity of a diagnostic.
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNote    Level = "note"
+)
+
+// Diagnostic is a single finding of a check-style command, e.g., a rule whose regular
+// expression is out of date. `Check` identifies the kind of finding. `Subject` is the item the
+// finding is about, e.g., a rule ID, if it isn't the file itself. File names are relative to
+// the root directory. Lines and columns are 1-based, zero if unknown.
+type Diagnostic struct {
+	Check   string `json:"check"`
+	Level   Level  `json:"level"`
+	Subject string `json:"subject,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// GitHubAnnotation returns the diagnostic as a GitHub Actions workflow command that annotates
+// its location.
+func (d Diagnostic) GitHubAnnotation() string {
+	command := string(d.Level)
+	if d.Level == LevelNote {
+		command = "notice"
+	}
+	return utils.GitHubAnnotation(command, d.File, d.Line, d.Column, d.Message)
+}
+
+// RelativePath returns `filePath` relative to `directory`, if possible. Commands use it for the
+// file names of their diagnostics.
+func RelativePath(directory string, filePath string) string {
+	relative, err := filepath.Rel(directory, filePath)
+	if err != nil {
+		return filePath
+	}
+	return relative
+}
+
+// Report collects the diagnostics of a check-style command. With the text and GitHub outputs,
+// commands print their findings as they go. With structured outputs (JSON, SARIF, JUnit), the
+// diagnostics are written as a single document by `Write` instead, and commands must not write
+// anything else to stdout, see `Writer`.
+//
+// Commands also record the items they checked without findings with `Pass`, so that every
+// checked item can be reported as a test case in JUnit reports.
+type Report struct {
+	output      string
+	diagnostics []Diagnostic
+	cases       []*testCase
+}
+
+// testCase is an item checked by a command, with the diagnostics about it
+type testCase struct {
+	check       string
+	name        string
+	file        string
+	diagnostics []Diagnostic
+}
+
+// NewReport creates an empty report for the output type `output`.
+func NewReport(output string) *Report {
+	return &Report{output: output, diagnostics: []Diagnostic{}}
+}
+
+// Structured returns true if the diagnostics are written as a single document.
+func (r *Report) Structured() bool {
+	return r.output == Json || r.output == Sarif || r.output == JUnit
+}
+
+// Writer returns `writer` for the human readable output of a command, or a writer that
+// discards everything if the output is structured.
+func (r *Report) Writer(writer io.Writer) io.Writer {
+	if r.Structured() {
+		return io.Discard
+	}
+	return writer
+}
+
+// Add appends `diagnostic` to the report.
+func (r *Report) Add(diagnostic Diagnostic) {
+	r.diagnostics = append(r.diagnostics, diagnostic)
+	name := diagnostic.Subject
+	if name == "" {
+		name = diagnostic.File
+	}
+	item := r.testCase(diagnostic.Check, name, diagnostic.File)
+	item.diagnostics = append(item.diagnostics, diagnostic)
+}
+
+// Pass records that the command checked the item `name` in `file` with `check` and found
+// nothing. `name` is the file itself if empty.
+func (r *Report) Pass(check string, name string, file string) {
+	if name == "" {
+		name = file
+	}
+	r.testCase(check, name, file)
+}
+
+// testCase returns the test case of `name` for `check`, creating it if necessary.
+func (r *Report) testCase(check string, name string, file string) *testCase {
+	for _, item := range r.cases {
+		if item.check == check && item.name == name {
+			return item
+		}
+	}
+	item := &testCase{check: check, name: name, file: file}
+	r.cases = append(r.cases, item)
+	return item
+}
+
+// Diagnostics returns the diagnostics of the report in the order they were added.
+func (r *Report) Diagnostics() []Diagnostic {
+	return r.diagnostics
+}
+
+// Summarize writes `message`, a summary of the findings of the command, to `writer` as a GitHub
+// Actions error annotation without location. Nothing is written for the other outputs.
+func (r *Report) Summarize(writer io.Writer, message string) {
+	if r.output != GitHub {
+		return
+	}
+	fmt.Fprintln(writer, utils.GitHubAnnotation(string(LevelError), "", 0, 0, message))
+}
+
+// Write writes the diagnostics to `writer` as a JSON, SARIF or JUnit document. Nothing is
+// written for the other outputs.
+func (r *Report) Write(writer io.Writer) error {
+	var document any
+	switch r.output {
+	case Json:
+		document = r.diagnostics
+	case Sarif:
+		document = newSarifLog(r.diagnostics)
+	case JUnit:
+		return writeJUnit(writer, r.cases)
+	default:
+		return nil
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// Finish writes the report to `writer` and returns `err`, the result of the command, joined with
+// the error of writing the report, if any.
+func (r *Report) Finish(writer io.Writer, err error) error {
+	if writeErr := r.Write(writer); writeErr != nil {
+		return errors.Join(err, writeErr)
+	}
+	return err
+}
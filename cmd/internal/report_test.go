@@ -0,0 +1,182 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type reportTestSuite struct {
+	suite.Suite
+}
+
+func TestRunReportTestSuite(t *testing.T) {
+	suite.Run(t, new(reportTestSuite))
+}
+
+func (s *reportTestSuite) addDiagnostics(report *Report) {
+	report.Add(Diagnostic{
+		Check:   "regex-compare",
+		Level:   LevelError,
+		File:    "rules/REQUEST-932.conf",
+		Line:    12,
+		Message: "Regex of 932100 has changed!",
+	})
+	report.Add(Diagnostic{
+		Check:   "regex-format",
+		Level:   LevelWarning,
+		Message: "File not properly formatted",
+	})
+}
+
+func (s *reportTestSuite) TestStructured() {
+	s.False(NewReport(Text).Structured())
+	s.False(NewReport(GitHub).Structured())
+	s.True(NewReport(Json).Structured())
+	s.True(NewReport(Sarif).Structured())
+	s.True(NewReport(JUnit).Structured())
+}
+
+func (s *reportTestSuite) TestWriter() {
+	s.Equal(os.Stdout, NewReport(Text).Writer(os.Stdout))
+	s.NotEqual(os.Stdout, NewReport(Json).Writer(os.Stdout))
+}
+
+func (s *reportTestSuite) TestWrite_Text() {
+	report := NewReport(Text)
+	s.addDiagnostics(report)
+	out := &bytes.Buffer{}
+	s.Require().NoError(report.Write(out))
+	s.Empty(out.String())
+}
+
+func (s *reportTestSuite) TestWrite_JsonEmpty() {
+	out := &bytes.Buffer{}
+	s.Require().NoError(NewReport(Json).Write(out))
+	s.Equal("[]\n", out.String())
+}
+
+func (s *reportTestSuite) TestWrite_Json() {
+	report := NewReport(Json)
+	s.addDiagnostics(report)
+	out := &bytes.Buffer{}
+	s.Require().NoError(report.Write(out))
+
+	diagnostics := []Diagnostic{}
+	s.Require().NoError(json.Unmarshal(out.Bytes(), &diagnostics))
+	s.Equal(report.Diagnostics(), diagnostics)
+	s.Contains(out.String(), `"check": "regex-compare"`)
+	s.NotContains(out.String(), `"column"`)
+}
+
+func (s *reportTestSuite) TestWrite_Sarif() {
+	report := NewReport(Sarif)
+	s.addDiagnostics(report)
+	out := &bytes.Buffer{}
+	s.Require().NoError(report.Write(out))
+
+	log := sarifLog{}
+	s.Require().NoError(json.Unmarshal(out.Bytes(), &log))
+	s.Equal("2.1.0", log.Version)
+	s.Equal(sarifSchema, log.Schema)
+	s.Require().Len(log.Runs, 1)
+	run := log.Runs[0]
+	s.Equal("crs-toolchain", run.Tool.Driver.Name)
+	s.Equal([]sarifRule{{Id: "regex-compare"}, {Id: "regex-format"}}, run.Tool.Driver.Rules)
+
+	s.Require().Len(run.Results, 2)
+	s.Equal("regex-compare", run.Results[0].RuleId)
+	s.Equal(LevelError, run.Results[0].Level)
+	s.Equal("Regex of 932100 has changed!", run.Results[0].Message.Text)
+	s.Require().Len(run.Results[0].Locations, 1)
+	location := run.Results[0].Locations[0].PhysicalLocation
+	s.Equal("rules/REQUEST-932.conf", location.ArtifactLocation.Uri)
+	s.Equal(&sarifRegion{StartLine: 12}, location.Region)
+	s.Empty(run.Results[1].Locations)
+}
+
+func (s *reportTestSuite) TestWrite_SarifEmpty() {
+	out := &bytes.Buffer{}
+	s.Require().NoError(NewReport(Sarif).Write(out))
+	s.Contains(out.String(), `"results": []`)
+	s.Contains(out.String(), `"rules": []`)
+}
+
+func (s *reportTestSuite) TestWrite_JUnit() {
+	report := NewReport(JUnit)
+	report.Pass("regex-compare", "932100", "rules/REQUEST-932.conf")
+	report.Add(Diagnostic{
+		Check:   "regex-compare",
+		Level:   LevelError,
+		Subject: "932110",
+		File:    "rules/REQUEST-932.conf",
+		Line:    12,
+		Column:  20,
+		Message: "Regex of 932110 has changed",
+	})
+	report.Add(Diagnostic{
+		Check:   "regex-format",
+		Level:   LevelWarning,
+		File:    "regex-assembly/932110.ra",
+		Message: "932110.ra not properly formatted",
+	})
+	report.Pass("regex-format", "", "regex-assembly/932110.ra")
+	out := &bytes.Buffer{}
+	s.Require().NoError(report.Write(out))
+
+	s.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="crs-toolchain" tests="3" failures="2">
+  <testsuite name="regex-compare" tests="2" failures="1">
+    <testcase name="932100" classname="regex-compare" file="rules/REQUEST-932.conf"></testcase>
+    <testcase name="932110" classname="regex-compare" file="rules/REQUEST-932.conf">
+      <failure message="Regex of 932110 has changed" type="error">rules/REQUEST-932.conf:12:20: Regex of 932110 has changed&#xA;</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="regex-format" tests="1" failures="1">
+    <testcase name="regex-assembly/932110.ra" classname="regex-format" file="regex-assembly/932110.ra">
+      <failure message="932110.ra not properly formatted" type="warning">regex-assembly/932110.ra: 932110.ra not properly formatted&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, out.String())
+}
+
+func (s *reportTestSuite) TestWrite_JUnitEmpty() {
+	out := &bytes.Buffer{}
+	s.Require().NoError(NewReport(JUnit).Write(out))
+	s.Contains(out.String(), `<testsuites name="crs-toolchain" tests="0" failures="0"></testsuites>`)
+}
+
+func (s *reportTestSuite) TestGitHubAnnotation() {
+	diagnostic := Diagnostic{
+		Check:   "regex-compare",
+		Level:   LevelNote,
+		File:    "rules/REQUEST-932.conf",
+		Line:    12,
+		Column:  20,
+		Message: "Regex of 932100 has changed",
+	}
+	s.Equal("::notice file=rules/REQUEST-932.conf,line=12,col=20::Regex of 932100 has changed", diagnostic.GitHubAnnotation())
+}
+
+func (s *reportTestSuite) TestSummarize() {
+	out := &bytes.Buffer{}
+	NewReport(GitHub).Summarize(out, "All rules need to be up to date.")
+	s.Equal("::error::All rules need to be up to date.\n", out.String())
+
+	out.Reset()
+	NewReport(Text).Summarize(out, "All rules need to be up to date.")
+	NewReport(Json).Summarize(out, "All rules need to be up to date.")
+	s.Empty(out.String())
+}
+
+func (s *reportTestSuite) TestRelativePath() {
+	s.Equal("rules/REQUEST-932.conf", RelativePath("/crs", "/crs/rules/REQUEST-932.conf"))
+	s.Equal("rules/REQUEST-932.conf", RelativePath("/crs", "rules/REQUEST-932.conf"))
+}
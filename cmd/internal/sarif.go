@@ -0,0 +1,106 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+// The types in this file are the subset of SARIF 2.1.0 (Static Analysis Results Interchange
+// Format) used to report diagnostics, e.g., to code scanning dashboards.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "crs-toolchain"
+	toolUri      = "https://github.com/coreruleset/crs-toolchain"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     Level           `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// newSarifLog creates a log with a single run that contains a result for each diagnostic. The
+// checks of the diagnostics are the rules of the run.
+func newSarifLog(diagnostics []Diagnostic) *sarifLog {
+	rules := []sarifRule{}
+	seen := map[string]bool{}
+	results := make([]sarifResult, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		if !seen[diagnostic.Check] {
+			seen[diagnostic.Check] = true
+			rules = append(rules, sarifRule{Id: diagnostic.Check})
+		}
+		result := sarifResult{
+			RuleId:  diagnostic.Check,
+			Level:   diagnostic.Level,
+			Message: sarifMessage{Text: diagnostic.Message},
+		}
+		if diagnostic.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: diagnostic.File},
+			}}
+			if diagnostic.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: diagnostic.Line, StartColumn: diagnostic.Column}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: toolName, InformationUri: toolUri, Rules: rules}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lsp
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+	"github.com/coreruleset/crs-toolchain/v2/regex/format"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/regex/validation"
+)
+
+const diagnosticSource = "crs-toolchain"
+
+// includePrefixRegex matches the beginning of an include or include-except line, up to the cursor.
+// The directive is captured in group 1, completed arguments in group 2 and the argument
+// being typed in group 3.
+var includePrefixRegex = regexp.MustCompile(`^\s*##!>\s*(include|include-except)\s+((?:\S+\s+)*)(\S*)$`)
+
+// definitionPrefixRegex matches an incomplete definition reference that ends at the cursor.
+// The partial name is captured in group 1.
+var definitionPrefixRegex = regexp.MustCompile(`{{([a-zA-Z0-9-_]*)$`)
+
+var argumentRegex = regexp.MustCompile(`\S+`)
+
+type argument struct {
+	value string
+	start int
+	end   int
+}
+
+// diagnose reports parser and validation errors of the document.
+func (s *Server) diagnose(uri string, text string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	lines := splitLines(text)
+
+	ctxt := processors.NewContext(s.rootContext)
+	raParser := parser.NewParserForFile(ctxt, uriToPath(uri), strings.NewReader(text))
+	if _, err := raParser.Parse(false); err != nil {
+		var includeError *parser.IncludeError
+		if errors.As(err, &includeError) {
+			for _, parseError := range includeError.Errors {
+				diagnostics = append(diagnostics, parseErrorDiagnostic(lines, parseError))
+			}
+		} else {
+			diagnostics = append(diagnostics, parseErrorDiagnostic(lines, err))
+		}
+	}
+
+	for lineIndex, line := range lines {
+		if regex.CommentRegex.MatchString(line) || regex.TestVectorRegex.MatchString(line) {
+			continue
+		}
+		if err := validation.ValidateAll(strings.NewReader(line)); err != nil {
+			diagnostics = append(diagnostics, newDiagnostic(lines, lineIndex, 0, err.Error()))
+		}
+	}
+	return diagnostics
+}
+
+// parseErrorDiagnostic creates a diagnostic for an error returned by the parser.
+func parseErrorDiagnostic(lines []string, err error) Diagnostic {
+	lineIndex, column := 0, 0
+	var parseError *parser.ParseError
+	if errors.As(err, &parseError) {
+		location := parseError.Location
+		if len(parseError.IncludeStack) > 0 {
+			// The error is located in an included file, report it at the outermost include
+			location = parseError.IncludeStack[0]
+		}
+		lineIndex = location.Line - 1
+		column = location.Column - 1
+	}
+	return newDiagnostic(lines, lineIndex, column, err.Error())
+}
+
+// definition returns the location of the definition referenced at `position`, or of the file
+// included at `position`. Returns nil if there is nothing to navigate to.
+func (s *Server) definition(uri string, text string, position Position) *Location {
+	lines := splitLines(text)
+	if position.Line < 0 || position.Line >= len(lines) {
+		return nil
+	}
+	line := lines[position.Line]
+	offset := byteOffset(line, position.Character)
+
+	for _, match := range regex.DefinitionReferenceRegex.FindAllStringSubmatchIndex(line, -1) {
+		if offset >= match[0] && offset <= match[1] {
+			return s.findDefinition(uri, lines, line[match[2]:match[3]])
+		}
+	}
+
+	for _, include := range includeArguments(line) {
+		if offset >= include.start && offset <= include.end {
+			filePath, err := dependencies.Resolve(s.rootContext, include.value)
+			if err != nil {
+				return nil
+			}
+			return &Location{Uri: pathToUri(filePath)}
+		}
+	}
+	return nil
+}
+
+// findDefinition searches the definition of `name` in the document and, if not found there,
+// in the files included by the document.
+func (s *Server) findDefinition(uri string, lines []string, name string) *Location {
+	if location := findDefinitionInLines(uri, lines, name); location != nil {
+		return location
+	}
+
+	for _, line := range lines {
+		for _, include := range includeArguments(line) {
+			filePath, err := dependencies.Resolve(s.rootContext, include.value)
+			if err != nil {
+				continue
+			}
+			contents, err := os.ReadFile(filePath)
+			if err != nil {
+				logger.Debug().Err(err).Msgf("Failed to read %s", filePath)
+				continue
+			}
+			if location := findDefinitionInLines(pathToUri(filePath), splitLines(string(contents)), name); location != nil {
+				return location
+			}
+		}
+	}
+	return nil
+}
+
+func findDefinitionInLines(uri string, lines []string, name string) *Location {
+	for lineIndex, line := range lines {
+		match := regex.DefinitionRegex.FindStringSubmatchIndex(line)
+		if match == nil || line[match[4]:match[5]] != name {
+			continue
+		}
+		return &Location{
+			Uri: uri,
+			Range: Range{
+				Start: Position{Line: lineIndex, Character: characterOffset(line, match[4])},
+				End:   Position{Line: lineIndex, Character: characterOffset(line, match[5])},
+			},
+		}
+	}
+	return nil
+}
+
+// complete returns include names when the cursor is on an include argument, and definition
+// names when the cursor follows `{{`.
+func (s *Server) complete(text string, position Position) []CompletionItem {
+	items := []CompletionItem{}
+	lines := splitLines(text)
+	if position.Line < 0 || position.Line >= len(lines) {
+		return items
+	}
+	line := lines[position.Line]
+	prefix := line[:byteOffset(line, position.Character)]
+
+	if match := definitionPrefixRegex.FindStringSubmatch(prefix); match != nil {
+		for _, line := range lines {
+			definition := regex.DefinitionRegex.FindStringSubmatch(line)
+			if definition == nil || !strings.HasPrefix(definition[2], match[1]) {
+				continue
+			}
+			items = append(items, CompletionItem{
+				Label:  definition[2],
+				Kind:   completionItemKindVariable,
+				Detail: definition[3],
+			})
+		}
+		return items
+	}
+
+	if match := includePrefixRegex.FindStringSubmatch(prefix); match != nil && !strings.Contains(match[2], "--") {
+		directory := s.rootContext.IncludesDir()
+		if match[1] == "include-except" && len(match[2]) > 0 {
+			directory = s.rootContext.ExcludesDir()
+		}
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			logger.Debug().Err(err).Msgf("Failed to read directory %s", directory)
+			return items
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), ".ra")
+			if entry.IsDir() || path.Ext(entry.Name()) != ".ra" || !strings.HasPrefix(name, match[3]) {
+				continue
+			}
+			items = append(items, CompletionItem{
+				Label:  name,
+				Kind:   completionItemKindFile,
+				Detail: filepath.Join(directory, entry.Name()),
+			})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].Label < items[j].Label
+		})
+	}
+	return items
+}
+
+// format formats the document with the same logic as `regex format` and returns a single edit
+// replacing the entire document, or no edits if the document is already formatted.
+func (s *Server) format(uri string, text string) ([]TextEdit, error) {
+	ctxt := processors.NewContext(s.rootContext)
+	lines, _, err := format.FormatAssembly(ctxt, uriToPath(uri), strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	formatted := strings.Join(lines, "\n")
+	if formatted == text {
+		return []TextEdit{}, nil
+	}
+
+	original := splitLines(text)
+	last := len(original) - 1
+	return []TextEdit{{
+		Range: Range{
+			End: Position{Line: last, Character: characterOffset(original[last], len(original[last]))},
+		},
+		NewText: formatted,
+	}}, nil
+}
+
+// includeArguments returns the file name arguments of an include or include-except line.
+func includeArguments(line string) []argument {
+	if match := regex.IncludeRegex.FindStringSubmatchIndex(line); match != nil {
+		return []argument{{value: line[match[2]:match[3]], start: match[2], end: match[3]}}
+	}
+	match := regex.IncludeExceptRegex.FindStringSubmatchIndex(line)
+	if match == nil {
+		return nil
+	}
+	arguments := []argument{{value: line[match[2]:match[3]], start: match[2], end: match[3]}}
+	if match[4] < 0 {
+		return arguments
+	}
+	for _, exclude := range argumentRegex.FindAllStringIndex(line[match[4]:match[5]], -1) {
+		start := match[4] + exclude[0]
+		end := match[4] + exclude[1]
+		arguments = append(arguments, argument{value: line[start:end], start: start, end: end})
+	}
+	return arguments
+}
+
+// newDiagnostic creates an error diagnostic that starts at byte offset `column` of the line and
+// extends to the end of the line.
+func newDiagnostic(lines []string, lineIndex int, column int, message string) Diagnostic {
+	lineIndex = max(0, min(lineIndex, len(lines)-1))
+	line := lines[lineIndex]
+	column = max(0, min(column, len(line)))
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: lineIndex, Character: characterOffset(line, column)},
+			End:   Position{Line: lineIndex, Character: characterOffset(line, len(line))},
+		},
+		Severity: diagnosticSeverityError,
+		Source:   diagnosticSource,
+		Message:  message,
+	}
+}
+
+// characterOffset converts a byte offset in `line` to an offset in UTF-16 code units.
+func characterOffset(line string, byteOffset int) int {
+	units := 0
+	for _, r := range line[:min(byteOffset, len(line))] {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// byteOffset converts an offset in UTF-16 code units in `line` to a byte offset.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
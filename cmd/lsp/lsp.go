@@ -0,0 +1,46 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lsp
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+)
+
+var logger = log.With().Str("component", "cmd.lsp").Logger()
+
+func New(cmdContext *internal.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Start a language server for regex-assembly files",
+		Long: `Start a language server for regex-assembly files.
+The server speaks the Language Server Protocol over stdin and stdout and is meant
+to be started by an editor.
+
+The server reports diagnostics from the parser and the validation of regex-assembly
+files, supports go-to-definition for definition references ({{name}}) and include
+targets, completes include and definition names, and formats documents the same
+way as 'regex format'.
+
+Logs are written to stderr, stdout is reserved for the protocol.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Errors are not command related
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			server := NewServer(cmdContext.RootContext(), os.Stdin, os.Stdout)
+			if err := server.Run(); err != nil {
+				logger.Error().Err(err).Msg("Language server failed")
+				return err
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+const serverName = "crs-toolchain"
+
+// ExitWithoutShutdownError is returned by `Run` when the client sends the `exit` notification
+// without having sent the `shutdown` request first.
+type ExitWithoutShutdownError struct {
+}
+
+func (e *ExitWithoutShutdownError) Error() string {
+	return "received exit notification before shutdown request"
+}
+
+// Server is a language server for regex-assembly files. It communicates with a single client
+// over a pair of streams, usually stdin and stdout.
+type Server struct {
+	rootContext       *context.Context
+	transport         *transport
+	documents         map[string]string
+	shutdownRequested bool
+}
+
+func NewServer(rootContext *context.Context, reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		rootContext: rootContext,
+		transport:   newTransport(reader, writer),
+		documents:   map[string]string{},
+	}
+}
+
+// Run processes messages until the client sends the `exit` notification or closes the input.
+func (s *Server) Run() error {
+	for {
+		content, err := s.transport.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		request := requestMessage{}
+		if err := json.Unmarshal(content, &request); err != nil {
+			logger.Error().Err(err).Msg("Failed to decode message")
+			continue
+		}
+		logger.Debug().Msgf("Received %s", request.Method)
+
+		if request.Method == "exit" {
+			if !s.shutdownRequested {
+				return &ExitWithoutShutdownError{}
+			}
+			return nil
+		}
+
+		result, responseErr := s.handle(&request)
+		if request.Id == nil {
+			// Notifications don't have responses
+			if responseErr != nil {
+				logger.Error().Msgf("Failed to handle %s: %s", request.Method, responseErr.Message)
+			}
+			continue
+		}
+		if err := s.respond(request.Id, result, responseErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(request *requestMessage) (any, *responseError) {
+	switch request.Method {
+	case "initialize":
+		return &initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:           textDocumentSyncFull,
+				DefinitionProvider:         true,
+				DocumentFormattingProvider: true,
+				CompletionProvider: completionOptions{
+					TriggerCharacters: []string{"{", " "},
+				},
+			},
+			ServerInfo: serverInfo{Name: serverName},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdownRequested = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenTextDocumentParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.documents[params.TextDocument.Uri] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.Uri)
+	case "textDocument/didChange":
+		params := didChangeTextDocumentParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// Full synchronization, the last change contains the entire document
+		s.documents[params.TextDocument.Uri] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.publishDiagnostics(params.TextDocument.Uri)
+	case "textDocument/didSave":
+		params := didCloseTextDocumentParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		// Included files may have changed as well
+		return nil, s.publishDiagnostics(params.TextDocument.Uri)
+	case "textDocument/didClose":
+		params := didCloseTextDocumentParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.Uri)
+		err := s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+			Uri:         params.TextDocument.Uri,
+			Diagnostics: []Diagnostic{},
+		})
+		if err != nil {
+			return nil, &responseError{Code: requestFailedCode, Message: err.Error()}
+		}
+		return nil, nil
+	case "textDocument/definition":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		text, found := s.documents[params.TextDocument.Uri]
+		if !found {
+			return nil, unknownDocument(params.TextDocument.Uri)
+		}
+		location := s.definition(params.TextDocument.Uri, text, params.Position)
+		if location == nil {
+			return nil, nil
+		}
+		return location, nil
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		text, found := s.documents[params.TextDocument.Uri]
+		if !found {
+			return nil, unknownDocument(params.TextDocument.Uri)
+		}
+		return s.complete(text, params.Position), nil
+	case "textDocument/formatting":
+		params := documentFormattingParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		text, found := s.documents[params.TextDocument.Uri]
+		if !found {
+			return nil, unknownDocument(params.TextDocument.Uri)
+		}
+		edits, err := s.format(params.TextDocument.Uri, text)
+		if err != nil {
+			return nil, &responseError{Code: requestFailedCode, Message: err.Error()}
+		}
+		return edits, nil
+	default:
+		if request.Id == nil {
+			// Unknown notifications, e.g., `$/cancelRequest`, can safely be ignored
+			return nil, nil
+		}
+		return nil, &responseError{Code: methodNotFoundCode, Message: fmt.Sprintf("method not supported: %s", request.Method)}
+	}
+}
+
+func (s *Server) publishDiagnostics(uri string) *responseError {
+	text, found := s.documents[uri]
+	if !found {
+		return unknownDocument(uri)
+	}
+	err := s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+		Uri:         uri,
+		Diagnostics: s.diagnose(uri, text),
+	})
+	if err != nil {
+		return &responseError{Code: requestFailedCode, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) respond(id *json.RawMessage, result any, responseErr *responseError) error {
+	response := &responseMessage{
+		JsonRpc: jsonRpcVersion,
+		Id:      id,
+		Error:   responseErr,
+	}
+	if responseErr == nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		response.Result = encoded
+	}
+	return s.transport.write(response)
+}
+
+func (s *Server) notify(method string, params any) error {
+	return s.transport.write(&notificationMessage{
+		JsonRpc: jsonRpcVersion,
+		Method:  method,
+		Params:  params,
+	})
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: invalidParamsCode, Message: err.Error()}
+}
+
+func unknownDocument(uri string) *responseError {
+	return &responseError{Code: invalidRequestCode, Message: fmt.Sprintf("document not open: %s", uri)}
+}
+
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	return parsed.Path
+}
+
+func pathToUri(filePath string) string {
+	return (&url.URL{Scheme: "file", Path: filePath}).String()
+}
+
+func splitLines(text string) []string {
+	return strings.Split(text, "\n")
+}
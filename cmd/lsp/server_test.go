@@ -0,0 +1,284 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/format"
+)
+
+type serverTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	excludeDir string
+	client     *testClient
+	serverDone chan error
+}
+
+// testClient is a minimal language client that talks to the server over pipes.
+type testClient struct {
+	transport *transport
+	input     *io.PipeWriter
+	nextId    int
+	// notifications received while waiting for responses
+	notifications []testMessage
+}
+
+type testMessage struct {
+	Id     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func TestRunServerTestSuite(t *testing.T) {
+	suite.Run(t, new(serverTestSuite))
+}
+
+func (s *serverTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	s.excludeDir = path.Join(s.dataDir, "exclude")
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+
+	serverInput, clientOutput := io.Pipe()
+	clientInput, serverOutput := io.Pipe()
+	server := NewServer(context.New(s.rootDir, "toolchain.yaml"), serverInput, serverOutput)
+	s.serverDone = make(chan error, 1)
+	go func() {
+		err := server.Run()
+		_ = serverOutput.Close()
+		s.serverDone <- err
+	}()
+	s.client = &testClient{
+		transport: newTransport(clientInput, clientOutput),
+		input:     clientOutput,
+	}
+
+	response := s.request("initialize", map[string]any{"capabilities": map[string]any{}})
+	result := initializeResult{}
+	s.Require().NoError(json.Unmarshal(response.Result, &result))
+	s.True(result.Capabilities.DefinitionProvider)
+	s.notify("initialized", map[string]any{})
+}
+
+func (s *serverTestSuite) TearDownTest() {
+	response := s.request("shutdown", nil)
+	s.Nil(response.Error)
+	s.notify("exit", nil)
+	s.NoError(<-s.serverDone)
+	_ = s.client.input.Close()
+}
+
+func (s *serverTestSuite) TestDiagnostics_ParseError() {
+	uri := s.open("123456.ra", "foo\n##!> include missing\n")
+
+	diagnostics := s.diagnostics(uri)
+	s.Require().Len(diagnostics, 1)
+	s.Equal(Range{
+		Start: Position{Line: 1, Character: 13},
+		End:   Position{Line: 1, Character: 20},
+	}, diagnostics[0].Range)
+	s.Contains(diagnostics[0].Message, "cannot open file for parsing")
+}
+
+func (s *serverTestSuite) TestDiagnostics_MissingIncludes() {
+	uri := s.open("123456.ra", "##!> include missing\nfoo\n##!> include other\n")
+
+	diagnostics := s.diagnostics(uri)
+	s.Require().Len(diagnostics, 2)
+	s.Equal(0, diagnostics[0].Range.Start.Line)
+	s.Equal(2, diagnostics[1].Range.Start.Line)
+	s.Contains(diagnostics[1].Message, "other.ra")
+}
+
+func (s *serverTestSuite) TestDiagnostics_Validation() {
+	uri := s.open("123456.ra", "##! comment [é]\nfoo\n[é]\n")
+
+	diagnostics := s.diagnostics(uri)
+	s.Require().Len(diagnostics, 1)
+	s.Equal(2, diagnostics[0].Range.Start.Line)
+}
+
+func (s *serverTestSuite) TestDiagnostics_ClearedOnChange() {
+	uri := s.open("123456.ra", "##!> include missing\n")
+	s.Len(s.diagnostics(uri), 1)
+
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "foo\n"}},
+	})
+	s.Empty(s.diagnostics(uri))
+}
+
+func (s *serverTestSuite) TestDefinition_Reference() {
+	uri := s.open("123456.ra", "##!> define word [a-z]+\n{{word}}-x\n")
+	s.diagnostics(uri)
+
+	response := s.request("textDocument/definition", s.positionParams(uri, 1, 3))
+	location := Location{}
+	s.Require().NoError(json.Unmarshal(response.Result, &location))
+	s.Equal(uri, location.Uri)
+	s.Equal(Range{
+		Start: Position{Line: 0, Character: 12},
+		End:   Position{Line: 0, Character: 16},
+	}, location.Range)
+}
+
+func (s *serverTestSuite) TestDefinition_Include() {
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.excludeDir, "except.ra", "foo\n")
+	uri := s.open("123456.ra", "##!> include words\n##!> include-except words except\n")
+	s.diagnostics(uri)
+
+	response := s.request("textDocument/definition", s.positionParams(uri, 0, 15))
+	location := Location{}
+	s.Require().NoError(json.Unmarshal(response.Result, &location))
+	s.Equal(pathToUri(path.Join(s.includeDir, "words.ra")), location.Uri)
+
+	response = s.request("textDocument/definition", s.positionParams(uri, 1, 28))
+	s.Require().NoError(json.Unmarshal(response.Result, &location))
+	s.Equal(pathToUri(path.Join(s.excludeDir, "except.ra")), location.Uri)
+
+	response = s.request("textDocument/definition", s.positionParams(uri, 1, 1))
+	s.Equal("null", string(response.Result))
+}
+
+func (s *serverTestSuite) TestCompletion_Include() {
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.includeDir, "windows.ra", "foo\n")
+	s.writeFile(s.includeDir, "unix.ra", "foo\n")
+	s.writeFile(s.excludeDir, "except.ra", "foo\n")
+	uri := s.open("123456.ra", "##!> include w\n##!> include-except unix \n")
+	s.diagnostics(uri)
+
+	s.Equal([]string{"windows", "words"}, s.completionLabels(uri, 0, 14))
+	s.Equal([]string{"except"}, s.completionLabels(uri, 1, 25))
+}
+
+func (s *serverTestSuite) TestCompletion_Definition() {
+	uri := s.open("123456.ra", "##!> define word [a-z]+\n##!> define wide [0-9]+\n##!> define other x\nfoo{{w\n")
+	s.diagnostics(uri)
+
+	s.Equal([]string{"word", "wide"}, s.completionLabels(uri, 3, 6))
+}
+
+func (s *serverTestSuite) TestFormatting() {
+	uri := s.open("123456.ra", "##!>   assemble\nfoo\n##!<\n\n\n")
+	s.diagnostics(uri)
+
+	response := s.request("textDocument/formatting", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"options":      map[string]any{"tabSize": 2, "insertSpaces": true},
+	})
+	edits := []TextEdit{}
+	s.Require().NoError(json.Unmarshal(response.Result, &edits))
+	s.Require().Len(edits, 1)
+	s.Equal(Range{End: Position{Line: 5, Character: 0}}, edits[0].Range)
+	s.Equal(format.RegexAssemblyStandardHeader+"\n##!> assemble\n  foo\n##!<\n", edits[0].NewText)
+}
+
+func (s *serverTestSuite) TestUnknownMethod() {
+	response := s.request("workspace/symbol", map[string]any{"query": ""})
+	s.Require().NotNil(response.Error)
+	s.Equal(methodNotFoundCode, response.Error.Code)
+}
+
+func (s *serverTestSuite) open(name string, text string) string {
+	uri := pathToUri(path.Join(s.dataDir, name))
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "regex-assembly", "version": 1, "text": text},
+	})
+	return uri
+}
+
+func (s *serverTestSuite) positionParams(uri string, line int, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func (s *serverTestSuite) completionLabels(uri string, line int, character int) []string {
+	response := s.request("textDocument/completion", s.positionParams(uri, line, character))
+	items := []CompletionItem{}
+	s.Require().NoError(json.Unmarshal(response.Result, &items))
+	labels := []string{}
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+// diagnostics waits for the next diagnostics published for `uri`.
+func (s *serverTestSuite) diagnostics(uri string) []Diagnostic {
+	for {
+		var next testMessage
+		if len(s.client.notifications) > 0 {
+			next = s.client.notifications[0]
+			s.client.notifications = s.client.notifications[1:]
+		} else {
+			next = s.read()
+		}
+		if next.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		params := publishDiagnosticsParams{}
+		s.Require().NoError(json.Unmarshal(next.Params, &params))
+		if params.Uri == uri {
+			return params.Diagnostics
+		}
+	}
+}
+
+func (s *serverTestSuite) request(method string, params any) testMessage {
+	s.client.nextId++
+	id := s.client.nextId
+	err := s.client.transport.write(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	s.Require().NoError(err)
+
+	for {
+		next := s.read()
+		if next.Id == nil {
+			s.client.notifications = append(s.client.notifications, next)
+			continue
+		}
+		s.Require().Equal(id, *next.Id)
+		return next
+	}
+}
+
+func (s *serverTestSuite) notify(method string, params any) {
+	err := s.client.transport.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+	s.Require().NoError(err)
+}
+
+func (s *serverTestSuite) read() testMessage {
+	content, err := s.client.transport.read()
+	s.Require().NoError(err)
+	next := testMessage{}
+	s.Require().NoError(json.Unmarshal(content, &next))
+	return next
+}
+
+func (s *serverTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentLengthHeader = "Content-Length"
+
+// transport reads and writes JSON-RPC messages framed with LSP base protocol headers.
+type transport struct {
+	reader      *bufio.Reader
+	writer      io.Writer
+	writerMutex sync.Mutex
+}
+
+func newTransport(reader io.Reader, writer io.Writer) *transport {
+	return &transport{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+}
+
+// read returns the content of the next message. Returns io.EOF when the input is exhausted.
+func (t *transport) read() ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := t.reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) == 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed header: %s", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), contentLengthHeader) {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("malformed content length: %w", err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, errors.New("missing content length header")
+	}
+
+	content := make([]byte, contentLength)
+	if _, err := io.ReadFull(t.reader, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (t *transport) write(value any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	t.writerMutex.Lock()
+	defer t.writerMutex.Unlock()
+	if _, err := fmt.Fprintf(t.writer, "%s: %d\r\n\r\n", contentLengthHeader, len(content)); err != nil {
+		return err
+	}
+	_, err = t.writer.Write(content)
+	return err
+}
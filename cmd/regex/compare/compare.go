@@ -4,14 +4,14 @@
 package compare
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -21,13 +21,18 @@ import (
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
 	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/equivalence"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
 	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/seclang"
 	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
 const maxGroupSplittingDepth = 2
 
 type ComparisonError struct {
+	// Reason describes the difference, e.g., "Regex of 932100 has changed"
+	Reason string
 }
 
 var logger = log.With().Str("component", "cmd.chore.update_copyright").Logger()
@@ -36,6 +41,12 @@ func (n *ComparisonError) Error() string {
 	return "regular expressions did not match"
 }
 
+// Is reports whether `target` is a ComparisonError, regardless of the reason.
+func (n *ComparisonError) Is(target error) bool {
+	_, ok := target.(*ComparisonError)
+	return ok
+}
+
 func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "compare [RULE_ID]",
@@ -51,12 +62,37 @@ sync with their regex-assembly file.
 RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
 If the rule is a chained rule, RULE_ID must be specified with the
 offset of the chain from the chain starter rule. For example, to
-generate a second level chained rule, RULE_ID would be 932100-chain2.`,
+generate a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --since, only the rules are compared whose regex-assembly file, or
+one of the files it includes, has changed since the given git revision.
+This includes uncommitted and untracked files.
+
+With --semantic, a changed regular expression is only reported if it
+matches different inputs than the regular expression of the rule. For
+such changes, the shortest input that only one of the expressions
+matches is printed. Changes that don't affect which inputs match, e.g.,
+a different order of alternatives, are reported but don't fail the
+command. Regular expressions are compared with the semantics of Go's
+regexp/syntax package (RE2, Perl flags), not PCRE: for example, $ only
+matches at the very end of the input, and lookarounds and backreferences
+are not supported. Regular expressions that can't be compared this way,
+because they use unsupported syntax or are too complex, are compared
+textually instead.
+
+With --output json or --output sarif, only the rules that are out of date
+are reported, as a single JSON or SARIF document. With --output junit, each
+rule is a test case of a JUnit XML report.`,
 		Args: cobra.MatchAll(cobra.MaximumNArgs(1), func(cmd *cobra.Command, args []string) error {
 			allFlag := cmd.Flags().Lookup("all")
-			if !allFlag.Changed && len(args) == 0 {
+			sinceFlag := cmd.Flags().Lookup("since")
+			if allFlag.Changed && sinceFlag.Changed {
+				return errors.New("expected either --all or --since flag, found both")
+			}
+			flagChanged := allFlag.Changed || sinceFlag.Changed
+			if !flagChanged && len(args) == 0 {
 				return errors.New("expected either RULE_ID or flag, found neither")
-			} else if allFlag.Changed && len(args) > 0 {
+			} else if flagChanged && len(args) > 0 {
 				return errors.New("expected either RULE_ID or flag, found both")
 			}
 			return nil
@@ -80,12 +116,33 @@ generate a second level chained rule, RULE_ID would be 932100-chain2.`,
 				logger.Error().Err(err).Msg("Failed to read value for 'all' flag")
 				return err
 			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to read value for 'jobs' flag")
+				return err
+			}
+			since, err := cmd.Flags().GetString("since")
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to read value for 'since' flag")
+				return err
+			}
+			semantic, err := cmd.Flags().GetBool("semantic")
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to read value for 'semantic' flag")
+				return err
+			}
 
 			// Start running. If an error occurs, propagate but don't print anything
 			// command related.
 			cmd.SilenceErrors = true
 			cmd.SilenceUsage = true
-			return performCompare(processAll, ctxt, cmdContext)
+			report := internal.NewReport(cmdContext.OuterContext.Output)
+			if since != "" {
+				err = performCompareSince(since, jobs, semantic, report, ctxt, cmdContext)
+			} else {
+				err = performCompare(processAll, jobs, semantic, report, ctxt, cmdContext)
+			}
+			return report.Finish(os.Stdout, err)
 		},
 	}
 
@@ -96,12 +153,34 @@ generate a second level chained rule, RULE_ID would be 932100-chain2.`,
 func buildFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("all", "a", false, `Instead of supplying a RULE_ID, you can tell the script to
 compare all rules from their regex-assembly files`)
+	cmd.Flags().IntP("jobs", "j", 0, `Number of regex-assembly files to process in parallel with --all or --since.
+Defaults to the number of CPUs. Results are always reported in the same order`)
+	cmd.Flags().String("since", "", `Instead of supplying a RULE_ID, compare only the rules affected by
+changes to regex-assembly, include or exclude files since the given git revision
+(e.g., a branch, tag or commit), including uncommitted changes`)
+	cmd.Flags().Bool("semantic", false, `Only fail for changed regular expressions that match different inputs,
+and print the shortest input that only one of the expressions matches`)
+}
+
+// assembled holds the generated regular expression of a regex-assembly file, or its phrases if
+// the file declares phrase output
+type assembled struct {
+	regex   string
+	phrases []string
+	err     error
+}
+
+// compareItem identifies a rule to compare when processing all rules
+type compareItem struct {
+	id          string
+	chainOffset uint8
+	filePath    string
 }
 
 // FIXME: duplicated in update.go
-func performCompare(processAll bool, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
-	failed := false
+func performCompare(processAll bool, jobs int, semantic bool, report *internal.Report, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
 	if processAll {
+		items := []compareItem{}
 		err := filepath.WalkDir(ctx.RootContext().AssemblyDir(), func(filePath string, dirEntry fs.DirEntry, err error) error {
 			if errors.Is(err, fs.ErrNotExist) {
 				// fail
@@ -109,49 +188,145 @@ func performCompare(processAll bool, ctx *processors.Context, cmdContext *regexI
 			}
 
 			if path.Ext(dirEntry.Name()) == ".ra" {
-				subs := regex.RuleIdFileNameRegex.FindAllStringSubmatch(dirEntry.Name(), -1)
-				if subs == nil {
-					// continue
-					return nil
-				}
-
-				id := subs[0][1]
-				chainOffsetString := subs[0][2]
-
-				chainOffset, err := strconv.ParseUint(chainOffsetString, 10, 8)
-				if err != nil && len(chainOffsetString) > 0 {
-					return errors.New("failed to match chain offset. Value must not be larger than 255")
-				}
-				regex := regexInternal.RunAssemble(filePath, ctx.RootContext(), cmdContext)
-				err = processRegexForCompare(id, uint8(chainOffset), regex, ctx, cmdContext)
-				if err != nil && errors.Is(err, &ComparisonError{}) {
-					failed = true
-					return nil
-				}
+				item, err := newCompareItem(filePath)
 				if err != nil {
 					return err
 				}
-				return nil
+				if item != nil {
+					items = append(items, *item)
+				}
 			}
 			return nil
 		})
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to compare expressions")
-		}
-		if failed {
-			if cmdContext.OuterContext.Output == internal.GitHub {
-				fmt.Println("::error::All rules need to be up to date.",
-					"Please run `crs-toolchain regex update --all`")
-			}
-			return &ComparisonError{}
+			logger.Error().Err(err).Msg("Failed to compare expressions")
+			return err
 		}
+		return compareItems(items, jobs, semantic, report, ctx, cmdContext)
 	} else {
-		regex := regexInternal.RunAssemble(path.Join(ctx.RootContext().AssemblyDir(), cmdContext.FileName), ctx.RootContext(), cmdContext)
-		return processRegexForCompare(cmdContext.Id, cmdContext.ChainOffset, regex, ctx, cmdContext)
+		filePath := path.Join(ctx.RootContext().AssemblyDir(), cmdContext.FileName)
+		result := runAssemble(filePath, ctx, cmdContext)
+		err := result.err
+		if err == nil {
+			err = processRegexForCompare(cmdContext.Id, cmdContext.ChainOffset, result, semantic, report, ctx, cmdContext)
+		}
+		if err != nil && !errors.Is(err, &ComparisonError{}) {
+			addFailure(report, cmdContext.Id, cmdContext.ChainOffset, filePath, err, ctx)
+		}
+		return err
+	}
+}
+
+func performCompareSince(since string, jobs int, semantic bool, report *internal.Report, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+	filePaths, err := regexInternal.ChangedRules(ctx.RootContext(), since)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to find rules affected by changes since %s", since)
+		return err
+	}
+	if len(filePaths) == 0 {
+		logger.Info().Msgf("No rules affected by changes since %s", since)
+		return nil
+	}
+
+	items := []compareItem{}
+	for _, filePath := range filePaths {
+		item, err := newCompareItem(filePath)
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("Rule %s, chain offset %d, is affected by changes since %s", item.id, item.chainOffset, since)
+		items = append(items, *item)
+	}
+	return compareItems(items, jobs, semantic, report, ctx, cmdContext)
+}
+
+// newCompareItem creates the item for the regex-assembly file at `filePath`, or returns nil if
+// the file doesn't belong to a rule.
+func newCompareItem(filePath string) (*compareItem, error) {
+	subs := regex.RuleIdFileNameRegex.FindAllStringSubmatch(path.Base(filePath), -1)
+	if subs == nil {
+		return nil, nil
+	}
+
+	id := subs[0][1]
+	chainOffsetString := subs[0][2]
+
+	chainOffset, err := strconv.ParseUint(chainOffsetString, 10, 8)
+	if err != nil && len(chainOffsetString) > 0 {
+		return nil, errors.New("failed to match chain offset. Value must not be larger than 255")
+	}
+	return &compareItem{id: id, chainOffset: uint8(chainOffset), filePath: filePath}, nil
+}
+
+// compareItems compares the rules of all `items` and returns a ComparisonError if any of them
+// is out of date.
+func compareItems(items []compareItem, jobs int, semantic bool, report *internal.Report, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+	failed := false
+	// Assemble in parallel, compare in order so that the output is deterministic
+	err := regexInternal.RunJobs(items, jobs, func(item compareItem) assembled {
+		return runAssemble(item.filePath, ctx, cmdContext)
+	}, func(item compareItem, result assembled) error {
+		err := result.err
+		if err == nil {
+			err = processRegexForCompare(item.id, item.chainOffset, result, semantic, report, ctx, cmdContext)
+		}
+		if err != nil && errors.Is(err, &ComparisonError{}) {
+			failed = true
+			return nil
+		}
+		if err != nil {
+			addFailure(report, item.id, item.chainOffset, item.filePath, err, ctx)
+		}
+		return err
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to compare expressions")
+		return err
+	}
+	if failed {
+		report.Summarize(os.Stdout, "All rules need to be up to date. Please run `crs-toolchain regex update --all`")
+		return &ComparisonError{}
 	}
 	return nil
 }
-func processRegexForCompare(ruleId string, chainOffset uint8, regex string, ctxt *processors.Context, cmdContext *regexInternal.CommandContext) error {
+
+// addFailure adds an error diagnostic to `report` for a rule that could not be compared, e.g.,
+// because its regex-assembly file at `filePath` could not be assembled.
+func addFailure(report *internal.Report, ruleId string, chainOffset uint8, filePath string, err error, ctx *processors.Context) {
+	report.Add(internal.Diagnostic{
+		Check:   "regex-compare",
+		Level:   internal.LevelError,
+		Subject: compareSubject(ruleId, chainOffset),
+		File:    internal.RelativePath(ctx.RootContext().RootDir(), filePath),
+		Message: err.Error(),
+	})
+}
+
+// compareSubject returns the name of the compared rule, e.g., 932100 or 932100-chain1.
+func compareSubject(ruleId string, chainOffset uint8) string {
+	if chainOffset > 0 {
+		return fmt.Sprintf("%s-chain%d", ruleId, chainOffset)
+	}
+	return ruleId
+}
+
+// runAssemble generates the regular expression, or the phrases, of the regex-assembly file at
+// `filePath`, depending on the output the file declares. Errors are returned in the `err` field
+// of the result.
+func runAssemble(filePath string, ctx *processors.Context, cmdContext *regexInternal.CommandContext) assembled {
+	output, err := regexInternal.Output(filePath, ctx.RootContext())
+	if err != nil {
+		return assembled{err: err}
+	}
+	if output == parser.PhrasesOutput {
+		phrases, err := regexInternal.AssemblePhrases(filePath, ctx.RootContext(), cmdContext)
+		return assembled{phrases: phrases, err: err}
+	}
+	regex, err := regexInternal.Assemble(filePath, ctx.RootContext(), cmdContext)
+	return assembled{regex: regex, err: err}
+}
+
+func processRegexForCompare(ruleId string, chainOffset uint8, result assembled, semantic bool, report *internal.Report, ctxt *processors.Context, cmdContext *regexInternal.CommandContext) error {
 	logger.Info().Msgf("Processing %s, chain offset %d", ruleId, chainOffset)
 
 	rulePrefix := ruleId[:3]
@@ -162,65 +337,185 @@ func processRegexForCompare(ruleId string, chainOffset uint8, regex string, ctxt
 	}
 	if matches == nil || len(matches) > 1 {
 		logger.Error().Msgf("Failed to find rule file for rule id %s", ruleId)
-		return err
+		return fmt.Errorf("failed to find rule file for rule id %s", ruleId)
 	}
 
 	filePath := matches[0]
 	logger.Debug().Msgf("Processing regex-assembly file %s", filePath)
 
-	currentRegex := readCurrentRegex(filePath, ruleId, chainOffset)
-	return compareRegex(ruleId, regex, currentRegex, cmdContext)
+	file, rule, err := readRule(filePath, ruleId, chainOffset)
+	if err != nil {
+		return err
+	}
+	out := report.Writer(os.Stdout)
+	if result.phrases != nil {
+		var currentPhrases []string
+		currentPhrases, err = readCurrentPhrases(filePath, rule, ruleId, chainOffset)
+		if err != nil {
+			return err
+		}
+		err = comparePhrases(out, ruleId, result.phrases, currentPhrases, cmdContext)
+	} else {
+		var currentRegex string
+		currentRegex, err = readCurrentRegex(filePath, rule, ruleId, chainOffset)
+		if err != nil {
+			return err
+		}
+		if semantic {
+			err = compareRegexSemantically(out, ruleId, result.regex, currentRegex, cmdContext)
+		} else {
+			err = compareRegex(out, ruleId, result.regex, currentRegex, cmdContext)
+		}
+	}
+
+	subject := compareSubject(ruleId, chainOffset)
+	relativePath := internal.RelativePath(ctxt.RootContext().RootDir(), filePath)
+	var comparisonError *ComparisonError
+	if errors.As(err, &comparisonError) {
+		line, column := file.Position(rule.Operator.Argument.Span.Start)
+		diagnostic := internal.Diagnostic{
+			Check:   "regex-compare",
+			Level:   internal.LevelError,
+			Subject: subject,
+			File:    relativePath,
+			Line:    line,
+			Column:  column,
+			Message: comparisonError.Reason,
+		}
+		report.Add(diagnostic)
+		if cmdContext.OuterContext.Output == internal.GitHub {
+			fmt.Fprintln(out, diagnostic.GitHubAnnotation())
+		}
+	} else if err == nil {
+		report.Pass("regex-compare", subject, relativePath)
+	}
+	return err
 }
 
-func readCurrentRegex(filePath string, ruleId string, chainOffset uint8) string {
-	contents, err := os.ReadFile(filePath)
+// readRule parses the rule file at `filePath` and returns it together with the rule `ruleId`,
+// or the rule at `chainOffset` in its chain.
+func readRule(filePath string, ruleId string, chainOffset uint8) (*seclang.File, *seclang.Rule, error) {
+	file, err := seclang.ParseFile(filePath)
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("Failed to read rule file %s", filePath)
-	}
-
-	lines := bytes.Split(contents, []byte("\n"))
-
-	idRegex := regexp.MustCompile(fmt.Sprintf("id:%s", ruleId))
-	index := 0
-	var line []byte
-	foundRule := false
-	chainCount := uint8(0)
-	for index, line = range lines {
-		if !foundRule && idRegex.Match(line) {
-			foundRule = true
-			if chainOffset == 0 {
-				index--
-				break
+		return nil, nil, fmt.Errorf("failed to parse rule file %s: %w", filePath, err)
+	}
+	rule, err := file.FindRule(ruleId, chainOffset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find rule %s, chain offset %d: %w", ruleId, chainOffset, err)
+	}
+	return file, rule, nil
+}
+
+func readCurrentRegex(filePath string, rule *seclang.Rule, ruleId string, chainOffset uint8) (string, error) {
+	if rule.Operator.Name != "rx" {
+		return "", fmt.Errorf("rule %s, chain offset %d, in %s doesn't use @rx", ruleId, chainOffset, filePath)
+	}
+	return rule.Operator.Argument.Value, nil
+}
+
+// readCurrentPhrases returns the phrases of the @pm operator of the rule, or the phrases in the
+// data file of the @pmFromFile operator, ignoring comments and empty lines.
+func readCurrentPhrases(filePath string, rule *seclang.Rule, ruleId string, chainOffset uint8) ([]string, error) {
+	switch rule.Operator.Name {
+	case "pm":
+		return strings.Fields(strings.ReplaceAll(rule.Operator.Argument.Value, `\"`, `"`)), nil
+	case "pmFromFile", "pmf":
+		phrases := []string{}
+		for _, dataFileName := range strings.Fields(rule.Operator.Argument.Value) {
+			dataFilePath := path.Join(path.Dir(filePath), dataFileName)
+			contents, err := os.ReadFile(dataFilePath)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("failed to read data file %s: %w", dataFilePath, err)
+			}
+			for _, line := range strings.Split(string(contents), "\n") {
+				line = strings.TrimSpace(line)
+				if line != "" && !strings.HasPrefix(line, "#") {
+					phrases = append(phrases, line)
+				}
 			}
-			continue
 		}
-		if foundRule && regex.SecRuleRegex.Match(line) {
-			chainCount++
+		return phrases, nil
+	default:
+		return nil, fmt.Errorf("rule %s, chain offset %d, in %s doesn't use @pm or @pmFromFile", ruleId, chainOffset, filePath)
+	}
+}
+
+func comparePhrases(out io.Writer, ruleId string, generatedPhrases []string, currentPhrases []string, cmdContext *regexInternal.CommandContext) error {
+	if slices.Equal(generatedPhrases, currentPhrases) {
+		fmt.Fprintln(out, "Phrases of", ruleId, "have not changed")
+		return nil
+	}
+	comparisonError := &ComparisonError{Reason: fmt.Sprintf("Phrases of %s have changed", ruleId)}
+	if cmdContext.OuterContext.Output == internal.GitHub {
+		return comparisonError
+	}
+
+	fmt.Fprintln(out, "Phrases of", ruleId, "have changed!")
+	reordered := true
+	for _, phrase := range currentPhrases {
+		if !slices.Contains(generatedPhrases, phrase) {
+			fmt.Fprintf(out, "removed:    %s\n", phrase)
+			reordered = false
 		}
-		if foundRule && chainCount == chainOffset {
-			break
+	}
+	for _, phrase := range generatedPhrases {
+		if !slices.Contains(currentPhrases, phrase) {
+			fmt.Fprintf(out, "added:      %s\n", phrase)
+			reordered = false
 		}
 	}
-	if !foundRule || chainOffset != chainCount {
-		logger.Fatal().Msgf("Failed to find rule %s, chain offset, %d in %s", ruleId, chainOffset, filePath)
+	if reordered {
+		fmt.Fprintln(out, "The order of the phrases has changed")
+	}
+	return comparisonError
+}
+
+// compareRegexSemantically compares the languages of the regular expressions. Returns a
+// ComparisonError only if they match different inputs. Falls back to compareRegex if the
+// regular expressions can't be compared semantically, e.g., because they contain lookarounds
+// or are too complex.
+func compareRegexSemantically(out io.Writer, ruleId string, generatedRegex string, currentRegex string, cmdContext *regexInternal.CommandContext) error {
+	if currentRegex == generatedRegex {
+		fmt.Fprintln(out, "Regex of", ruleId, "has not changed")
+		return nil
 	}
-	regexLine := lines[index]
-	found := regex.RuleRxRegex.FindAllStringSubmatch(string(regexLine), -1)
-	if len(found) == 0 {
-		logger.Fatal().Msgf("Failed to find rule %s in %s", ruleId, filePath)
+	difference, err := equivalence.Compare(currentRegex, generatedRegex)
+	if err != nil {
+		logger.Warn().Err(err).Msgf("Failed to compare regular expressions of %s semantically, comparing them textually", ruleId)
+		return compareRegex(out, ruleId, generatedRegex, currentRegex, cmdContext)
 	}
-	return found[0][2]
+	if difference == nil {
+		fmt.Fprintln(out, "Regex of", ruleId, "has changed but matches the same inputs")
+		return nil
+	}
+	matchedBy := "generated"
+	if difference.MatchedByFirst {
+		matchedBy = "current"
+	}
+	comparisonError := &ComparisonError{
+		Reason: fmt.Sprintf("Regex of %s matches different inputs, %q is only matched by the %s regular expression",
+			ruleId, difference.Input, matchedBy),
+	}
+	if cmdContext.OuterContext.Output == internal.GitHub {
+		return comparisonError
+	}
+
+	fmt.Fprintln(out, "Regex of", ruleId, "matches different inputs!")
+	fmt.Fprintf(out, "Shortest distinguishing input: %q (only matched by the %s regular expression)\n", difference.Input, matchedBy)
+	return comparisonError
 }
 
-func compareRegex(ruleId string, generatedRegex string, currentRegex string, cmdContext *regexInternal.CommandContext) error {
+func compareRegex(out io.Writer, ruleId string, generatedRegex string, currentRegex string, cmdContext *regexInternal.CommandContext) error {
 	if currentRegex == generatedRegex {
-		fmt.Println("Regex of", ruleId, "has not changed")
+		fmt.Fprintln(out, "Regex of", ruleId, "has not changed")
 		return nil
-	} else if cmdContext.OuterContext.Output == internal.GitHub {
-		return &ComparisonError{}
+	}
+	comparisonError := &ComparisonError{Reason: fmt.Sprintf("Regex of %s has changed", ruleId)}
+	if cmdContext.OuterContext.Output == internal.GitHub {
+		return comparisonError
 	}
 
-	fmt.Println("Regex of", ruleId, "has changed!")
+	fmt.Fprintln(out, "Regex of", ruleId, "has changed!")
 
 	generatedRegexLines := splitByGroupsUpToDepth(generatedRegex, maxGroupSplittingDepth)
 	currentRegexLines := splitByGroupsUpToDepth(currentRegex, maxGroupSplittingDepth)
@@ -241,30 +536,30 @@ func compareRegex(ruleId string, generatedRegex string, currentRegex string, cmd
 			continue
 		} else {
 			if skippedChars > 0 {
-				fmt.Printf("Skipped %d identical characters\n\n", skippedChars)
+				fmt.Fprintf(out, "Skipped %d identical characters\n\n", skippedChars)
 			}
 			skippedChars = 0
 		}
 
-		os.Stdout.WriteString("current:    ")
+		io.WriteString(out, "current:    ")
 		if i < numLinesCurrent {
-			os.Stdout.WriteString(currentLine)
+			io.WriteString(out, currentLine)
 		} else {
-			os.Stdout.WriteString("---")
+			io.WriteString(out, "---")
 		}
 
-		os.Stdout.WriteString("\n")
+		io.WriteString(out, "\n")
 
-		os.Stdout.WriteString("generated:  ")
+		io.WriteString(out, "generated:  ")
 		if i < numLinesGenerated {
-			os.Stdout.WriteString(generatedLine)
+			io.WriteString(out, generatedLine)
 		} else {
-			os.Stdout.WriteString("---")
+			io.WriteString(out, "---")
 		}
-		os.Stdout.WriteString("\n\n")
+		io.WriteString(out, "\n\n")
 	}
 
-	return &ComparisonError{}
+	return comparisonError
 }
 
 func splitByGroupsUpToDepth(input string, maxDepth int) []string {
@@ -5,6 +5,7 @@ package compare
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
 type compareTestSuite struct {
@@ -53,6 +55,8 @@ func (s *compareTestSuite) captureStdout() *os.File {
 
 func (s *compareTestSuite) SetupTest() {
 	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
 	s.dataDir = path.Join(s.rootDir, "regex-assembly")
 	err := os.MkdirAll(s.dataDir, fs.ModePerm)
 	s.Require().NoError(err)
@@ -72,7 +76,7 @@ func TestRunCompareTestSuite(t *testing.T) {
 
 func (s *compareTestSuite) TestCompare_NormalRuleId() {
 	s.writeDataFile("123456.ra", "")
-	s.writeRuleFile("123456", `SecRule... "@rx regex" \\`+"\nid:123456")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex" \`+"\n\t\"id:123456\"")
 	s.cmd.SetArgs([]string{"123456"})
 	cmd, _ := s.cmd.ExecuteC()
 
@@ -90,10 +94,10 @@ func (s *compareTestSuite) TestCompare_NormalRuleId() {
 
 func (s *compareTestSuite) TestCompare_AllFlag() {
 	s.writeRuleFile("123456",
-		`SecRule... "@rx oldfoo" \
-id:123456
-SecRule... "@rx oldbar" \
-id:123457`)
+		`SecRule ARGS "@rx oldfoo" \
+	"id:123456"
+SecRule ARGS "@rx oldbar" \
+	"id:123457"`)
 	s.writeDataFile("123456.ra", "foo")
 	s.writeDataFile("123457.ra", "bar")
 	s.cmd.SetArgs([]string{"--all"})
@@ -125,8 +129,8 @@ func (s *compareTestSuite) TestCompare_BothRuleIdAndAllFlagReturnsError() {
 func (s *compareTestSuite) TestCompare_NoChange() {
 	read := s.captureStdout()
 
-	s.writeRuleFile("123456", `SecRule... "@rx foo" \
-id:123456`)
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo" \
+	"id:123456"`)
 	s.writeDataFile("123456.ra", "foo")
 	s.cmd.SetArgs([]string{"--all"})
 	_, err := s.cmd.ExecuteC()
@@ -144,8 +148,8 @@ id:123456`)
 func (s *compareTestSuite) TestCompare_Change() {
 	read := s.captureStdout()
 
-	s.writeRuleFile("123456", `SecRule... "@rx oldfoo" \
-id:123456`)
+	s.writeRuleFile("123456", `SecRule ARGS "@rx oldfoo" \
+	"id:123456"`)
 	s.writeDataFile("123456.ra", "foo")
 	s.cmd.SetArgs([]string{"--all"})
 	_, err := s.cmd.ExecuteC()
@@ -159,3 +163,295 @@ id:123456`)
 	s.Len(output, 5)
 	s.Equal("Regex of 123456 has changed!", output[0])
 }
+
+func (s *compareTestSuite) TestCompare_WrongOperatorReturnsError() {
+	s.writeRuleFile("123456", `SecRule ARGS "@streq foo" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().Error(err)
+	s.NotErrorIs(err, &ComparisonError{})
+	s.ErrorContains(err, "doesn't use @rx")
+}
+
+func (s *compareTestSuite) TestCompare_SemanticIgnoresEquivalentChange() {
+	read := s.captureStdout()
+
+	s.writeRuleFile("123456", `SecRule ARGS "@rx bar|foo" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "foo\nbar")
+	s.cmd.SetArgs([]string{"123456", "--semantic"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal("Regex of 123456 has changed but matches the same inputs\n", string(output))
+}
+
+func (s *compareTestSuite) TestCompare_SemanticPrintsDistinguishingInput() {
+	read := s.captureStdout()
+
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo|baz" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "foo\nbar")
+	s.cmd.SetArgs([]string{"123456", "--semantic"})
+	_, err := s.cmd.ExecuteC()
+	var comparisonError *ComparisonError
+	s.Require().ErrorAs(err, &comparisonError)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal(`Regex of 123456 matches different inputs!
+Shortest distinguishing input: "bar" (only matched by the generated regular expression)
+`, string(output))
+}
+
+func (s *compareTestSuite) TestCompare_SemanticFallsBackForUnsupportedSyntax() {
+	read := s.captureStdout()
+
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo(?!bar)" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.cmd.SetArgs([]string{"123456", "--semantic"})
+	_, err := s.cmd.ExecuteC()
+	var comparisonError *ComparisonError
+	s.Require().ErrorAs(err, &comparisonError)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), "Regex of 123456 has changed!")
+}
+
+func (s *compareTestSuite) TestCompare_SemanticFallsBackForTooComplexAndContinues() {
+	read := s.captureStdout()
+
+	// The automaton of these expressions has more than `equivalence.DefaultMaxStates` states
+	s.writeRuleFile("123456", `SecRule ARGS "@rx ^(?:a|b)*a[ab]{16}$" \
+	"id:123456"
+SecRule ARGS "@rx bar" \
+	"id:123457"`)
+	s.writeDataFile("123456.ra", "^[ab]*a[ab]{16}$")
+	s.writeDataFile("123457.ra", "bar")
+	s.cmd.SetArgs([]string{"--all", "--semantic"})
+	_, err := s.cmd.ExecuteC()
+	var comparisonError *ComparisonError
+	s.Require().ErrorAs(err, &comparisonError)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), "Regex of 123456 has changed!")
+	s.Contains(string(output), "Regex of 123457 has not changed")
+}
+
+func (s *compareTestSuite) TestCompare_PmPhrasesNoChange() {
+	read := s.captureStdout()
+
+	s.writeRuleFile("123456", `SecRule ARGS "@pm wget curl" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "##!> output phrases\nwget\ncurl")
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal("Phrases of 123456 have not changed\n", string(output))
+}
+
+func (s *compareTestSuite) TestCompare_PmFromFilePhrasesChange() {
+	read := s.captureStdout()
+
+	s.writeRuleFile("123456", `SecRule ARGS "@pmFromFile commands.data" \
+	"id:123456"`)
+	err := os.WriteFile(path.Join(s.rulesDir, "commands.data"), []byte("# comment\nwget\nfetch\n"), fs.ModePerm)
+	s.Require().NoError(err)
+	s.writeDataFile("123456.ra", "##!> output phrases\nwget\ncurl")
+	s.cmd.SetArgs([]string{"--all"})
+	_, err = s.cmd.ExecuteC()
+	s.Require().Error(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal("Phrases of 123456 have changed!\nremoved:    fetch\nadded:      curl\n", string(output))
+}
+
+func (s *compareTestSuite) TestCompare_AllWithJobsReportsInOrder() {
+	read := s.captureStdout()
+
+	ruleFile := ""
+	expected := []string{}
+	for i := range 10 {
+		id := fmt.Sprintf("1234%02d", i)
+		s.writeDataFile(id+".ra", "foo"+id)
+		ruleFile += fmt.Sprintf("SecRule ARGS \"@rx foo%s\" \\\n\t\"id:%s\"\n", id, id)
+		expected = append(expected, fmt.Sprintf("Regex of %s has not changed", id))
+	}
+	s.writeRuleFile("123400", ruleFile)
+	s.cmd.SetArgs([]string{"--all", "--jobs", "4"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal(strings.Join(expected, "\n")+"\n", string(output))
+}
+
+func (s *compareTestSuite) TestCompare_SinceComparesOnlyAffectedRules() {
+	includeDir := path.Join(s.dataDir, "include")
+	s.Require().NoError(os.Mkdir(includeDir, fs.ModePerm))
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("foo"), fs.ModePerm))
+	s.writeDataFile("123456.ra", "##!> include words\n")
+	s.writeDataFile("123457.ra", "bar")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo" \
+	"id:123456"
+SecRule ARGS "@rx bar" \
+	"id:123457"`)
+	s.git("init", "-b", "main")
+	s.git("add", ".")
+	s.git("commit", "-m", "initial")
+
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("baz"), fs.ModePerm))
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--since", "main"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.ErrorIs(err, &ComparisonError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(string(output), "Regex of 123456 has changed!\n"))
+	s.NotContains(string(output), "123457")
+}
+
+func (s *compareTestSuite) TestCompare_SinceAndAllFlagReturnsError() {
+	s.cmd.SetArgs([]string{"--all", "--since", "main"})
+	_, err := s.cmd.ExecuteC()
+	s.Error(err)
+}
+
+func (s *compareTestSuite) git(args ...string) {
+	out, err := utils.RunGit(s.rootDir, append([]string{"-c", "user.name=dummy", "-c", "user.email=dummy@dummy.com"}, args...)...)
+	s.Require().NoError(err, string(out))
+}
+
+func (s *compareTestSuite) TestCompare_JsonOutput() {
+	s.writeRuleFile("123456",
+		`SecRule ARGS "@rx foo" \
+	"id:123456"
+SecRule ARGS "@rx oldbar" \
+	"id:123457"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", "bar")
+	s.cmdContext.OuterContext.Output = internal.Json
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.ErrorIs(err, &ComparisonError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.JSONEq(`[{
+	"check": "regex-compare",
+	"level": "error",
+	"subject": "123457",
+	"file": "rules/prefix-123-suffix.conf",
+	"line": 3,
+	"column": 19,
+	"message": "Regex of 123457 has changed"
+}]`, string(output))
+}
+
+func (s *compareTestSuite) TestCompare_SarifOutput() {
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo" \
+	"id:123456"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.cmdContext.OuterContext.Output = internal.Sarif
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.Require().NoError(err)
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), `"version": "2.1.0"`)
+	s.Contains(string(output), `"results": []`)
+	s.NotContains(string(output), "has not changed")
+}
+
+func (s *compareTestSuite) TestCompare_JUnitOutput() {
+	s.writeRuleFile("123456",
+		`SecRule ARGS "@rx foo" \
+	"id:123456"
+SecRule ARGS "@rx oldbar" \
+	"id:123457"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", "bar")
+	s.cmdContext.OuterContext.Output = internal.JUnit
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.ErrorIs(err, &ComparisonError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), `<testsuite name="regex-compare" tests="2" failures="1">`)
+	s.Contains(string(output), `<testcase name="123456" classname="regex-compare" file="rules/prefix-123-suffix.conf"></testcase>`)
+	s.Contains(string(output), `<failure message="Regex of 123457 has changed" type="error">rules/prefix-123-suffix.conf:3:19: Regex of 123457 has changed&#xA;</failure>`)
+}
+
+func (s *compareTestSuite) TestCompare_JUnitOutputReportsErrors() {
+	s.writeRuleFile("123456",
+		`SecRule ARGS "@rx foo" \
+	"id:123456"
+SecRule ARGS "@pm bar" \
+	"id:123457"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", "bar")
+	s.cmdContext.OuterContext.Output = internal.JUnit
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.ErrorContains(err, "doesn't use @rx")
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), `<testsuite name="regex-compare" tests="2" failures="1">`)
+	s.Contains(string(output), `<testcase name="123457" classname="regex-compare" file="regex-assembly/123457.ra">`)
+	s.Contains(string(output), `doesn&#39;t use @rx`)
+}
+
+func (s *compareTestSuite) TestCompare_GitHubOutput() {
+	s.writeRuleFile("123456",
+		`SecRule ARGS "@rx foo" \
+	"id:123456"
+SecRule ARGS "@rx oldbar" \
+	"id:123457"`)
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", "bar")
+	s.cmdContext.OuterContext.Output = internal.GitHub
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.ErrorIs(err, &ComparisonError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal(`Regex of 123456 has not changed
+::error file=rules/prefix-123-suffix.conf,line=3,col=19::Regex of 123457 has changed
+::error::All rules need to be up to date. Please run `+"`crs-toolchain regex update --all`"+`
+`, string(output))
+}
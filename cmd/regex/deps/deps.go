@@ -0,0 +1,110 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+var logger = log.With().Str("component", "cmd.regex.deps").Logger()
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	format := &regexInternal.GraphFormatFlag{Format: regexInternal.GraphText}
+	cmd := &cobra.Command{
+		Use:   "deps [RULE_ID | --all]",
+		Short: "Show the files a rule includes",
+		Long: `Show the files a rule includes.
+This command resolves the include and include-except directives of the
+regex-assembly file of a rule, transitively, and prints the paths of
+all the files the rule depends on, relative to the regex-assembly directory.
+
+RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
+If the rule is a chained rule, RULE_ID must be specified with the
+offset of the chain from the chain starter rule. For example, for
+a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --all, the dependencies of all rules are printed.
+With --format dot or --format json, the include graph of the rule (or of
+all regex-assembly files with --all) is printed instead, in Graphviz or
+JSON format.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			allFlag := cmd.Flags().Lookup("all")
+			if !allFlag.Changed && len(args) == 0 {
+				return errors.New("expected either RULE_ID or --all flag, found neither")
+			} else if allFlag.Changed && len(args) > 0 {
+				return errors.New("expected either RULE_ID or --all flag, found both")
+			} else if len(args) > 1 {
+				return errors.New("expected a single RULE_ID")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil
+			}
+			err := regexInternal.ParseRuleId(args[0], cmdContext)
+			if err != nil {
+				cmd.PrintErrf("failed to parse the rule ID from the input '%s'\n", args[0])
+				return err
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext := cmdContext.RootContext()
+			graph, err := dependencies.NewGraph(rootContext)
+			if err != nil {
+				return fmt.Errorf("failed to build include graph: %w", err)
+			}
+
+			rules := graph.Rules()
+			if len(args) > 0 {
+				rule := filepath.Join(rootContext.AssemblyDir(), cmdContext.FileName)
+				if !slices.Contains(rules, rule) {
+					return fmt.Errorf("regex-assembly file %s not found", cmdContext.FileName)
+				}
+				rules = []string{rule}
+				graph = graph.Subgraph(append([]string{rule}, graph.Dependencies(rule)...))
+			}
+			for _, missing := range graph.Missing() {
+				logger.Warn().Msgf("%s: include %s not found",
+					internal.RelativePath(rootContext.AssemblyDir(), missing.From), missing.Name)
+			}
+
+			if format.Format != regexInternal.GraphText {
+				return regexInternal.WriteGraph(os.Stdout, graph, format.Format, rootContext.AssemblyDir())
+			}
+			for _, rule := range rules {
+				if len(args) == 0 {
+					fmt.Println(internal.RelativePath(rootContext.AssemblyDir(), rule))
+				}
+				for _, dependency := range graph.Dependencies(rule) {
+					if len(args) == 0 {
+						fmt.Print("  ")
+					}
+					fmt.Println(internal.RelativePath(rootContext.AssemblyDir(), dependency))
+				}
+			}
+			return nil
+		},
+	}
+
+	buildFlags(cmd, format)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command, format *regexInternal.GraphFormatFlag) {
+	cmd.Flags().BoolP("all", "a", false, "Instead of supplying a RULE_ID, print the dependencies of all rules")
+	cmd.Flags().Var(format, "format", "Output format. One of 'text', 'dot', 'json'.")
+}
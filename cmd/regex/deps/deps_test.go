@@ -0,0 +1,151 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package deps
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+)
+
+type depsTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	excludeDir string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+func (s *depsTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	s.excludeDir = path.Join(s.dataDir, "exclude")
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+
+	s.writeFile(s.dataDir, "123456.ra", "##!> include shell\n")
+	s.writeFile(s.dataDir, "123457.ra", "##!> include-except words except\n")
+	s.writeFile(s.includeDir, "shell.ra", "##!> include words\n")
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.excludeDir, "except.ra", "bar\n")
+}
+
+func TestRunDepsTestSuite(t *testing.T) {
+	suite.Run(t, new(depsTestSuite))
+}
+
+func (s *depsTestSuite) TestDeps_NoRuleId() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *depsTestSuite) TestDeps_RuleIdAndAll() {
+	s.cmd.SetArgs([]string{"123456", "--all"})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *depsTestSuite) TestDeps_UnknownRule() {
+	s.cmd.SetArgs([]string{"123458"})
+	_, err := s.cmd.ExecuteC()
+
+	s.ErrorContains(err, "123458.ra not found")
+}
+
+func (s *depsTestSuite) TestDeps_RuleId() {
+	output := s.run("123456")
+	s.Equal("include/shell.ra\ninclude/words.ra\n", output)
+}
+
+func (s *depsTestSuite) TestDeps_All() {
+	output := s.run("--all")
+	s.Equal(`123456.ra
+  include/shell.ra
+  include/words.ra
+123457.ra
+  include/words.ra
+  exclude/except.ra
+`, output)
+}
+
+func (s *depsTestSuite) TestDeps_Dot() {
+	output := s.run("123457", "--format", "dot")
+	s.Equal(`digraph includes {
+	rankdir=LR;
+	"123457.ra" [shape=box];
+	"exclude/except.ra";
+	"include/words.ra";
+	"123457.ra" -> "include/words.ra";
+	"123457.ra" -> "exclude/except.ra" [style=dashed];
+}
+`, output)
+}
+
+func (s *depsTestSuite) TestDeps_Json() {
+	s.writeFile(s.dataDir, "123458.ra", "##!> include missing\n")
+	output := s.run("--all", "--format", "json")
+
+	document := map[string]any{}
+	s.Require().NoError(json.Unmarshal([]byte(output), &document))
+	s.Equal([]any{"123456.ra", "123457.ra", "123458.ra"}, document["rules"])
+	s.Len(document["files"], 6)
+	s.Len(document["edges"], 4)
+	s.Equal([]any{map[string]any{"from": "123458.ra", "name": "missing"}}, document["missing"])
+}
+
+func (s *depsTestSuite) TestDeps_InvalidFormat() {
+	s.cmd.SetArgs([]string{"123456", "--format", "svg"})
+	_, err := s.cmd.ExecuteC()
+
+	s.ErrorContains(err, "invalid option for format")
+}
+
+func (s *depsTestSuite) run(args ...string) string {
+	read := s.captureStdout()
+	s.cmd.SetArgs(args)
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	return string(output)
+}
+
+func (s *depsTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *depsTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
@@ -4,8 +4,6 @@
 package format
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -20,27 +18,16 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	loggerConfig "github.com/coreruleset/crs-toolchain/v2/logger"
 	"github.com/coreruleset/crs-toolchain/v2/regex"
-	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	regexFormat "github.com/coreruleset/crs-toolchain/v2/regex/format"
 	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
-	"github.com/coreruleset/crs-toolchain/v2/regex/validation"
 	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
-const (
-	RegexAssemblyStandardHeader = "##! Please refer to the documentation at\n##! https://coreruleset.org/docs/development/regex_assembly/.\n"
-)
-
 var logger = log.With().Str("component", "cmd.regex.format").Logger()
 
-var blockStartRegex = regex.ProcessorBlockStartRegex
-var blockEndRegex = regex.ProcessorEndRegex
-var includeRegex = regex.IncludeRegex
-var includeExceptRegex = regex.IncludeExceptRegex
 var definitionRegex = regex.DefinitionRegex
-var prefixRegex = regex.PrefixRegex
-var suffixRegex = regex.SuffixRegex
-var flagsRegex = regex.FlagsRegex
 
 func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
@@ -55,7 +42,11 @@ generate a second level chained rule, RULE_ID would be 932100-chain2.
 
 INCLUDE_NAME is the name of the file in the "include" directory, without the extension.
 These files are also regex-assembly files but don't follow the same naming
-scheme, as they don't correspond to any particular rule.`,
+scheme, as they don't correspond to any particular rule.
+
+With --output json or --output sarif, the files that are not properly
+formatted are reported as a single JSON or SARIF document. With
+--output junit, each file is a test case of a JUnit XML report.`,
 		Args: cobra.MatchAll(cobra.MaximumNArgs(1), func(cmd *cobra.Command, args []string) error {
 			allFlag := cmd.Flags().Lookup("all")
 			if !allFlag.Changed && len(args) == 0 {
@@ -81,8 +72,15 @@ scheme, as they don't correspond to any particular rule.`,
 				return err
 			}
 
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read jobs flag")
+				return err
+			}
+
+			report := internal.NewReport(cmdContext.OuterContext.Output)
 			if formatAll {
-				err = processAll(ctxt, checkOnly, cmdContext)
+				err = processAll(ctxt, checkOnly, jobs, report, cmdContext)
 			} else {
 				filename := args[0]
 				if path.Ext(filename) == "" {
@@ -92,7 +90,12 @@ scheme, as they don't correspond to any particular rule.`,
 				if err = regexInternal.ParseRuleId(filename, cmdContext); err == nil {
 					filePath = path.Join(ctxt.RootContext().AssemblyDir(), cmdContext.FileName)
 				}
-				err = processFile(filePath, ctxt, checkOnly, cmdContext)
+				var message string
+				message, err = processFile(filePath, ctxt, checkOnly, cmdContext)
+				if message != "" {
+					fmt.Fprintln(report.Writer(os.Stdout), message)
+				}
+				addResult(report, filePath, err, ctxt)
 			}
 
 			if err != nil {
@@ -100,7 +103,7 @@ scheme, as they don't correspond to any particular rule.`,
 				cmd.SilenceErrors = true
 				cmd.SilenceUsage = true
 			}
-			return err
+			return report.Finish(os.Stdout, err)
 		},
 	}
 
@@ -111,11 +114,20 @@ scheme, as they don't correspond to any particular rule.`,
 func buildFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolP("all", "a", false, `Instead of supplying a RULE_ID, you can tell the script to
 format all assembly files (both regular and include files)`)
+	cmd.Flags().IntP("jobs", "j", 0, `Number of regex-assembly files to process in parallel with --all.
+Defaults to the number of CPUs. Results are always reported in the same order`)
 	cmd.Flags().BoolP("check", "c", false, `Do not write changes, simply report on files that would be formatted`)
 }
 
-func processAll(ctxt *processors.Context, checkOnly bool, cmdContext *regexInternal.CommandContext) error {
+// formatResult is the outcome of formatting a single file
+type formatResult struct {
+	message string
+	err     error
+}
+
+func processAll(ctxt *processors.Context, checkOnly bool, jobs int, report *internal.Report, cmdContext *regexInternal.CommandContext) error {
 	failed := false
+	filePaths := []string{}
 	err := filepath.WalkDir(ctxt.RootContext().AssemblyDir(), func(filePath string, d fs.DirEntry, err error) error {
 		if err != nil {
 			// abort
@@ -128,11 +140,7 @@ func processAll(ctxt *processors.Context, checkOnly bool, cmdContext *regexInter
 		}
 
 		if path.Ext(d.Name()) == ".ra" {
-			err := processFile(filePath, ctxt, checkOnly, cmdContext)
-			if err != nil {
-				failed = true
-			}
-			return nil
+			filePaths = append(filePaths, filePath)
 		}
 		return nil
 	})
@@ -140,17 +148,60 @@ func processAll(ctxt *processors.Context, checkOnly bool, cmdContext *regexInter
 		logger.Error().Err(err).Msg("failed to walk directories")
 		return err
 	}
-	if failed {
-		if cmdContext.OuterContext.Output == internal.GitHub {
-			fmt.Println("::error::All assembly files need to be properly formatted.",
-				"Please run `crs-toolchain regex format --all`")
+
+	// Format in parallel, report in order so that the output is deterministic
+	err = regexInternal.RunJobs(filePaths, jobs, func(filePath string) formatResult {
+		message, err := processFile(filePath, processors.NewContext(ctxt.RootContext()), checkOnly, cmdContext)
+		return formatResult{message: message, err: err}
+	}, func(filePath string, result formatResult) error {
+		if result.message != "" {
+			fmt.Fprintln(report.Writer(os.Stdout), result.message)
 		}
+		addResult(report, filePath, result.err, ctxt)
+		if result.err != nil {
+			failed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failed {
+		report.Summarize(os.Stdout, "All assembly files need to be properly formatted. Please run `crs-toolchain regex format --all`")
 		return &UnformattedFileError{}
 	}
 	return nil
 }
 
-func processFile(filePath string, ctxt *processors.Context, checkOnly bool, cmdContext *regexInternal.CommandContext) error {
+// addResult adds a diagnostic to `report` if `err` is an UnformattedFileError for a single file,
+// an error diagnostic for any other error, or records the file at `filePath` as passed if there
+// is no error.
+func addResult(report *internal.Report, filePath string, err error, ctxt *processors.Context) {
+	relativePath := internal.RelativePath(ctxt.RootContext().RootDir(), filePath)
+	var unformattedFileError *UnformattedFileError
+	if err == nil {
+		report.Pass("regex-format", "", relativePath)
+	} else if errors.As(err, &unformattedFileError) && unformattedFileError.HasPathInfo() {
+		report.Add(internal.Diagnostic{
+			Check:   "regex-format",
+			Level:   internal.LevelWarning,
+			File:    relativePath,
+			Line:    unformattedFileError.line,
+			Message: fmt.Sprintf("%s not properly formatted", path.Base(filePath)),
+		})
+	} else if !errors.As(err, &unformattedFileError) {
+		report.Add(internal.Diagnostic{
+			Check:   "regex-format",
+			Level:   internal.LevelError,
+			File:    relativePath,
+			Message: err.Error(),
+		})
+	}
+}
+
+// processFile formats the file at `filePath`, or only checks its format if `checkOnly` is set.
+// The returned message must be printed by the caller.
+func processFile(filePath string, ctxt *processors.Context, checkOnly bool, cmdContext *regexInternal.CommandContext) (string, error) {
 	var processFileError error
 	message := ""
 	filename := path.Base(filePath)
@@ -158,61 +209,45 @@ func processFile(filePath string, ctxt *processors.Context, checkOnly bool, cmdC
 	file, err := os.Open(filePath)
 	if err != nil {
 		logger.Error().Err(err).Msgf("failed to open file %s", filePath)
-		return err
+		return "", err
 	}
 
-	raParser := parser.NewParser(ctxt, file)
-	parsedBytesBuffer := raParser.Parse(true)
+	lines, raParser, err := regexFormat.FormatAssembly(ctxt, filePath, file)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed to format file %s", filePath)
+		_ = file.Close()
+		return "", err
+	}
 	if err = file.Close(); err != nil {
 		logger.Error().Err(err).Msgf("file already closed %s", filePath)
-		return err
-	}
-
-	logger.Trace().Msg("Validating input")
-	if err := validation.ValidateAll(bytes.NewReader(parsedBytesBuffer.Bytes())); err != nil {
-		return err
-	}
-	logger.Trace().Msg("Successfully validated input")
-
-	scanner := bufio.NewScanner(parsedBytesBuffer)
-	lines := []string{}
-
-	indent := 0
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		line, indent, err = processLine(line, indent)
-		if err != nil {
-			logger.Error().Err(err).Msgf("failed to format %s", filename)
-		}
-		lines = append(lines, string(line))
-	}
-
-	if !checkStandardHeader(lines) {
-		logger.Info().Msgf("file %s does not have standard header", filename)
-		// prepend the standard header
-		lines = append([]string{RegexAssemblyStandardHeader}, lines...)
+		return "", err
 	}
-	lines = formatEndOfFile(lines)
 
 	newContents := []byte(strings.Join(lines, "\n"))
 	if checkOnly {
 		currentContents, err := os.ReadFile(filePath)
 		if err != nil {
 			logger.Error().Err(err).Msgf("failed to read file %s", filePath)
-			return err
+			return "", err
 		}
 		// sanity check: if we are using an ignore-case flag, we don't need to have any uppercase letters in the file
-		foundUppercase, errMessage := findUpperCaseCharacterClassOnIgnoreCaseFlag(lines, raParser.Flags['i'])
+		foundUppercase, errMessage, uppercaseLine := findUpperCaseCharacterClassOnIgnoreCaseFlag(lines, raParser.Flags['i'])
 		if foundUppercase {
-			logger.Warn().Msgf("%s contains uppercase letters in character classes, but ignore-case flag is set. Please check your source files.", filename)
+			logger.Warn().
+				Str(loggerConfig.FileFieldName, internal.RelativePath(ctxt.RootContext().RootDir(), filePath)).
+				Int(loggerConfig.LineFieldName, uppercaseLine).
+				Msgf("%s contains uppercase letters in character classes, but ignore-case flag is set. Please check your source files.", filename)
 			logger.Warn().Msgf("%s", errMessage)
 			logger.Warn().Msg("Be aware that because of file inclusions and definitions, the actual line number or file might be different.")
 		}
-		equalContent := bytes.Equal(currentContents, newContents)
-		if !equalContent || foundUppercase {
-			message = formatMessage(fmt.Sprintf("%s not properly formatted", filename), cmdContext)
-			fmt.Println(message)
-			processFileError = &UnformattedFileError{filePath: filePath}
+		line := utils.FirstDifferentLine(currentContents, newContents)
+		if line > 0 || foundUppercase {
+			if line == 0 {
+				line = uppercaseLine
+			}
+			fileName := internal.RelativePath(ctxt.RootContext().RootDir(), filePath)
+			message = formatMessage(fmt.Sprintf("%s not properly formatted", filename), fileName, line, cmdContext)
+			processFileError = &UnformattedFileError{filePath: filePath, line: line}
 		}
 	} else {
 		err = os.WriteFile(filePath, newContents, fs.ModePerm)
@@ -222,111 +257,30 @@ func processFile(filePath string, ctxt *processors.Context, checkOnly bool, cmdC
 		}
 	}
 
-	return processFileError
+	return message, processFileError
 }
 
-func processLine(line []byte, indent int) ([]byte, int, error) {
-	trimmedLine := bytes.TrimLeft(line, " \t")
-	if len(trimmedLine) == 0 {
-		return trimmedLine, indent, nil
-	}
-
-	blockIndent := indent
-	nextIndent := indent
-	if matches := blockStartRegex.FindSubmatch(line); matches != nil {
-		newLine := fmt.Sprintf("##!> %s", matches[1])
-		if len(matches[2]) > 0 {
-			newLine += " " + string(matches[2])
-		}
-		trimmedLine = []byte(newLine)
-		blockIndent = indent
-		nextIndent = blockIndent + 1
-	} else if blockEndRegex.Match(line) {
-		if blockIndent == 0 {
-			return nil, 0, errors.New("unbalanced processor block")
-		}
-		blockIndent = indent - 1
-		nextIndent = blockIndent
-	} else if matches := flagsRegex.FindSubmatch(line); matches != nil {
-		trimmedLine = []byte(fmt.Sprintf("##!+ %s", matches[1]))
-		blockIndent = 0
-	} else if matches := prefixRegex.FindSubmatch(line); matches != nil {
-		trimmedLine = []byte(fmt.Sprintf("##!^ %s", matches[1]))
-		blockIndent = 0
-	} else if matches := suffixRegex.FindSubmatch(line); matches != nil {
-		trimmedLine = []byte(fmt.Sprintf("##!$ %s", matches[1]))
-		blockIndent = 0
-	} else if matches := definitionRegex.FindSubmatch(line); matches != nil {
-		trimmedLine = []byte(fmt.Sprintf("##!> define %s %s", matches[2], matches[3]))
-	} else if matches := includeRegex.FindSubmatch(line); matches != nil {
-		trimmedLineString := fmt.Sprintf("##!> include %s", matches[1])
-		if len(matches[2]) > 0 {
-			trimmedLineString += fmt.Sprintf(" -- %s", matches[2])
-		}
-		trimmedLine = []byte(trimmedLineString)
-	} else if matches := includeExceptRegex.FindSubmatch(line); matches != nil {
-		trimmedLineString := fmt.Sprintf("##!> include-except %s %s", matches[1], matches[2])
-		if len(matches[3]) > 0 {
-			trimmedLineString += fmt.Sprintf(" -- %s", matches[3])
-		}
-		trimmedLine = []byte(trimmedLineString)
-	}
-
-	adjustment := bytes.Repeat([]byte(" "), blockIndent*2)
-	trimmedLine = append(adjustment, trimmedLine...)
-
-	return trimmedLine, nextIndent, nil
-}
-
-func formatMessage(message string, cmdContext *regexInternal.CommandContext) string {
+// formatMessage returns `message` as an annotation of `line` in `fileName` for GitHub output.
+func formatMessage(message string, fileName string, line int, cmdContext *regexInternal.CommandContext) string {
 	if cmdContext.OuterContext.Output == internal.GitHub {
-		message = fmt.Sprintf("::warning ::%s\n", message)
+		message = utils.GitHubAnnotation("warning", fileName, line, 0, message) + "\n"
 	}
 	return message
 }
 
-func formatEndOfFile(lines []string) []string {
-	eof := len(lines) - 1
-	if eof < 0 {
-		// Lines will be joined with newlines, so
-		// two empty lines will result in a single
-		// newline character
-		return append(lines, "", "")
-	}
-
-	for i := eof; i >= 0; i-- {
-		line := lines[i]
-		if line == "" {
-			eof--
-		} else {
-			break
-		}
-	}
-	// Append a single empty line, which will be joined
-	// to the others by newline
-	return append(lines[:eof+1], "")
-}
-
-func checkStandardHeader(lines []string) bool {
-	if len(lines) >= 3 &&
-		fmt.Sprintf("%s\n%s\n%s", lines[0], lines[1], lines[2]) == RegexAssemblyStandardHeader {
-		return true
-	}
-	return false
-}
-
 // findUpperCaseCharacterClassOnIgnoreCaseFlag checks if the file contains uppercase letters when the ignore-case flag is set
 // returns true if the file contains uppercase letters, and an error message pointing the line where it was found.
-func findUpperCaseCharacterClassOnIgnoreCaseFlag(lines []string, iFlag bool) (bool, string) {
+func findUpperCaseCharacterClassOnIgnoreCaseFlag(lines []string, iFlag bool) (bool, string, int) {
 	res := false
 	definition := false
 	message := ""
+	lineNumber := 0
 	// check if the file contains uppercase letters
 	if iFlag {
 		for i, line := range lines {
-			// if this line is not a definition, then ignore if it is a comment
+			// if this line is not a definition, then ignore if it is a comment or a test vector
 			definition = definitionRegex.MatchString(line)
-			if !definition && regex.CommentRegex.MatchString(line) {
+			if !definition && (regex.CommentRegex.MatchString(line) || regex.TestVectorRegex.MatchString(line)) {
 				continue
 			}
 			if definition {
@@ -348,11 +302,12 @@ func findUpperCaseCharacterClassOnIgnoreCaseFlag(lines []string, iFlag bool) (bo
 				}
 				res = true
 				message = fmt.Sprintf("\n%s\n%s^ [HERE]\n", line, fill)
+				lineNumber = i + 1
 				break
 			}
 		}
 	}
-	return res, message
+	return res, message, lineNumber
 }
 
 // findUppercaseNonEscaped finds an uppercase character that is not escaped in a given line
@@ -5,7 +5,9 @@ package format
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	regexFormat "github.com/coreruleset/crs-toolchain/v2/regex/format"
 )
 
 type formatTestSuite struct {
@@ -100,7 +103,7 @@ func (s *formatTestSuite) TestFormat_TrimsTabs() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 line1	
 line2
 `
@@ -115,7 +118,7 @@ func (s *formatTestSuite) TestFormat_TrimsSpaces() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 line1    
 line2
 `
@@ -131,7 +134,7 @@ func (s *formatTestSuite) TestFormat_IndentsAssembleBlock() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   line
 ##!<
@@ -160,7 +163,7 @@ func (s *formatTestSuite) TestFormat_IndentsNestedAssembleBlocks() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   line
   ##!> assemble
@@ -189,7 +192,7 @@ func (s *formatTestSuite) TestFormat_EndOfFileHasNewLineAfterNoNewLine() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   line
 ##!<
@@ -207,7 +210,7 @@ func (s *formatTestSuite) TestFormat_EndOfFileHasNewLineAfterOneNewLine() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   line
 ##!<
@@ -226,7 +229,7 @@ func (s *formatTestSuite) TestFormat_EndOfFileHasNewLineAfterTwoNewLines() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   line
 ##!<
@@ -241,7 +244,7 @@ func (s *formatTestSuite) TestFormat_EndOfFileHasNewLineIfEmpty() {
 	s.cmd.SetArgs([]string{"123456"})
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
-	expected := RegexAssemblyStandardHeader + "\n"
+	expected := regexFormat.RegexAssemblyStandardHeader + "\n"
 	output := s.readDataFile("123456.ra")
 	s.Equal(expected, output)
 }
@@ -258,7 +261,7 @@ func (s *formatTestSuite) TestFormat_DoesNotRemoveEmptyLines() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 
 
 ##!> assemble
@@ -282,7 +285,7 @@ func (s *formatTestSuite) TestFormat_DoesNotRemoveComments() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##! a comment	
 ##!> assemble
   ##! a comment	
@@ -309,7 +312,7 @@ func (s *formatTestSuite) TestFormat_OnlyIndentsAssembleProcessor() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   ##!> include bart
   ##!> assemble
@@ -336,7 +339,7 @@ func (s *formatTestSuite) TestFormat_FormatsProcessors() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> assemble
   ##!> include bart
   ##!> cmdline windows
@@ -357,7 +360,7 @@ func (s *formatTestSuite) TestFormat_FormatsFlags() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!+ i
 ##!+ i
 ##!+ i
@@ -366,6 +369,20 @@ func (s *formatTestSuite) TestFormat_FormatsFlags() {
 	s.Equal(expected, output)
 }
 
+func (s *formatTestSuite) TestFormat_FormatsOutput() {
+	s.writeDataFile("123456.ra", "  ##!>output   phrases \nfoo\n")
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := regexFormat.RegexAssemblyStandardHeader + `
+##!> output phrases
+foo
+`
+	output := s.readDataFile("123456.ra")
+	s.Equal(expected, output)
+}
+
 func (s *formatTestSuite) TestFormat_FormatsPrefix() {
 	s.writeDataFile("123456.ra", `##!^prefix without separating white space
   ##!^ prefix with leading white space
@@ -375,7 +392,7 @@ func (s *formatTestSuite) TestFormat_FormatsPrefix() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!^ prefix without separating white space
 ##!^ prefix with leading white space
 ##!^ prefix with trailing white space
@@ -393,7 +410,7 @@ func (s *formatTestSuite) TestFormat_FormatsSuffix() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!$ suffix without separating white space
 ##!$ suffix with leading white space
 ##!$ suffix with trailing white space
@@ -411,7 +428,7 @@ func (s *formatTestSuite) TestFormat_FormatsDefinitions() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> define without-separating-white-space homer
 ##!> define with-leading-white-space homer
 ##!> define with-trailing-white-space homer
@@ -429,7 +446,7 @@ func (s *formatTestSuite) TestFormat_FormatsIncludes() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> include without-separating-white-space
 ##!> include with-leading-white-space
 ##!> include with-trailing-white-space
@@ -447,7 +464,7 @@ func (s *formatTestSuite) TestFormat_FormatsIncludes_WithSuffixReplacements() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> include homer
 ##!> include homer -- r s f g
 ##!> include marge
@@ -465,7 +482,7 @@ func (s *formatTestSuite) TestFormat_FormatsExcept() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> include-except without-separating-white-space homer
 ##!> include-except with-leading-white-space homer
 ##!> include-except with-trailing-white-space homer
@@ -483,7 +500,7 @@ func (s *formatTestSuite) TestFormat_FormatsExcept_WithSuffixReplacements() {
 	_, err := s.cmd.ExecuteC()
 	s.Require().NoError(err)
 
-	expected := RegexAssemblyStandardHeader + `
+	expected := regexFormat.RegexAssemblyStandardHeader + `
 ##!> include-except simpson homer
 ##!> include-except includefile exclude1 exclude2 -- @ [\s<>] ~ \S
 ##!> include-except simpson homer
@@ -498,7 +515,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase() {
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 this is a regex
 ^[a-z]this is another regex
@@ -518,7 +535,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_FirstCharacter() {
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 [First] letter is uppercase
 `)
@@ -537,7 +554,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_LastCharacter() {
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 Last letter is upper[casE]
 `)
@@ -556,7 +573,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_PlusDefinitions() {
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!> define homer [simpson]
 ##!+ i
 multiple escape sequences \A\B\S should be good.
@@ -574,7 +591,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_PlusDefinitionsWithUpp
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!> define homer No_[Bueno]
 ##!+ i
 multiple escape sequences \A\B\S should be good.
@@ -594,7 +611,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_ComplexCharacterClass(
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 I'm complex: [^S$%_+-fG-].
 `)
@@ -613,7 +630,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_CharacterClassWithBrac
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 Chara[ct]er class with brackets: [$l\][2R [fl\]iF]
 `)
@@ -632,7 +649,7 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_IgnoreShortHands() {
 	log := zerolog.New(out)
 	logger = log.With().Str("component", "parser-test").Logger()
 
-	s.writeDataFile("123456.ra", RegexAssemblyStandardHeader+`
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+`
 ##!+ i
 [\W\S]
 `)
@@ -643,6 +660,40 @@ func (s *formatTestSuite) TestIgnoreCaseFlagWithUppercase_IgnoreShortHands() {
 	s.Require().NoError(err)
 }
 
+func (s *formatTestSuite) TestFormat_AllWithJobsReportsInOrder() {
+	// Other tests replace the logger with one that writes to an unsynchronized buffer
+	logger = zerolog.New(zerolog.SyncWriter(io.Discard))
+	read := s.captureStdout()
+	expected := ""
+	for i := range 10 {
+		fileName := fmt.Sprintf("1234%02d.ra", i)
+		s.writeDataFile(fileName, "  unformatted\n")
+		expected += fileName + " not properly formatted\n"
+	}
+	s.writeIncludeFile("include.ra", regexFormat.RegexAssemblyStandardHeader+"\nformatted\n")
+	s.cmd.SetArgs([]string{"--all", "--check", "--jobs", "3"})
+
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.EqualError(err, "One or more files are not properly formatted")
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal(expected, string(output))
+}
+
+func (s *formatTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
 func (s *formatTestSuite) writeDataFile(filename string, contents string) {
 	err := os.WriteFile(path.Join(s.dataDir, filename), []byte(contents), fs.ModePerm)
 	s.Require().NoError(err)
@@ -658,3 +709,73 @@ func (s *formatTestSuite) writeIncludeFile(filename string, contents string) {
 	err := os.WriteFile(path.Join(s.includeDir, filename), []byte(contents), fs.ModePerm)
 	s.Require().NoError(err)
 }
+
+func (s *formatTestSuite) TestFormat_JsonOutput() {
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", regexFormat.RegexAssemblyStandardHeader+"\nbar\n")
+	s.cmdContext.OuterContext.Output = internal.Json
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all", "--check"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.Error(err)
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.JSONEq(`[{
+	"check": "regex-format",
+	"level": "warning",
+	"file": "regex-assembly/123456.ra",
+	"line": 1,
+	"message": "123456.ra not properly formatted"
+}]`, string(output))
+}
+
+func (s *formatTestSuite) TestFormat_JsonOutputReportsErrors() {
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+"\nfoo\n[äb]\n")
+	s.cmdContext.OuterContext.Output = internal.Json
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all", "--check"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.Error(err)
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	diagnostics := []internal.Diagnostic{}
+	s.Require().NoError(json.Unmarshal(output, &diagnostics))
+	s.Require().Len(diagnostics, 1)
+	s.Equal(internal.LevelError, diagnostics[0].Level)
+	s.Equal("regex-assembly/123456.ra", diagnostics[0].File)
+	s.NotEmpty(diagnostics[0].Message)
+}
+
+func (s *formatTestSuite) TestFormat_JUnitOutput() {
+	s.writeDataFile("123456.ra", "foo")
+	s.writeDataFile("123457.ra", regexFormat.RegexAssemblyStandardHeader+"\nbar\n")
+	s.cmdContext.OuterContext.Output = internal.JUnit
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--all", "--check"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.Error(err)
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), `<testsuite name="regex-format" tests="2" failures="1">`)
+	s.Contains(string(output), `<testcase name="regex-assembly/123457.ra" classname="regex-format" file="regex-assembly/123457.ra"></testcase>`)
+}
+
+func (s *formatTestSuite) TestFormat_GitHubAnnotationPointsToFirstChange() {
+	s.writeDataFile("123456.ra", regexFormat.RegexAssemblyStandardHeader+"\nfoo\n  bar\n")
+	s.cmdContext.OuterContext.Output = internal.GitHub
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"--check", "123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+	s.Error(err)
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), "::warning file=regex-assembly/123456.ra,line=5::123456.ra not properly formatted\n")
+}
@@ -4,6 +4,8 @@ import "fmt"
 
 type UnformattedFileError struct {
 	filePath string
+	// line is the first line that needs to be fixed, starting at 1, or 0 if unknown
+	line int
 }
 
 func (u *UnformattedFileError) Error() string {
@@ -4,23 +4,23 @@
 package generate
 
 import (
+	"encoding/json"
 	"errors"
-	"io"
+	"fmt"
 	"os"
 	"path"
+	"slices"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
-	"github.com/coreruleset/crs-toolchain/v2/regex/operators"
-	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
 )
 
 var logger = log.With().Str("component", "cmd.regex.generate").Logger()
 
 func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "generate RULE_ID | -",
 		Short: "Generate regular expression from a regex-assembly file",
 		Long: `Generate regular expression from a regex-assembly file.
@@ -33,13 +33,24 @@ offset of the chain from the chain starter rule. For example, to
 generate a second level chained rule, RULE_ID would be 932100-chain2.
 
 The special token '-' will cause the script to accept input
-from stdin.`,
+from stdin.
+
+With --source-map, a JSON document is printed instead, containing the
+generated regular expression and a source map that maps spans of the
+expression (byte offsets) to the lines of the regex-assembly files
+they were generated from.
+
+With --watch, the command keeps running and prints the regular expression
+again whenever the regex-assembly file, or a file it includes, changes.`,
 		Args: cobra.ExactArgs(1),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return errors.New("no argument provided")
 			}
 			if args[0] == "-" {
+				if cmd.Flags().Changed("watch") {
+					return errors.New("--watch can't be used when reading from stdin")
+				}
 				cmdContext.UseStdin = true
 				return nil
 			}
@@ -52,29 +63,81 @@ from stdin.`,
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			ctxt := processors.NewContext(cmdContext.RootContext())
-			assembler := operators.NewAssembler(ctxt)
-			var input []byte
-			var err error
-			if cmdContext.UseStdin {
-				logger.Trace().Msg("Reading from stdin")
-				input, err = io.ReadAll(os.Stdin)
-				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to read from stdin")
+			rootContext := cmdContext.RootContext()
+			filePath := path.Join(rootContext.AssemblyDir(), cmdContext.FileName)
+			withSourceMap, err := cmd.Flags().GetBool("source-map")
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to read value for 'source-map' flag")
+			}
+			watch, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to read value for 'watch' flag")
+			}
+			if !watch {
+				if err := generate(filePath, withSourceMap, cmdContext); err != nil {
+					logger.Fatal().Err(err).Send()
 				}
-			} else {
-				filePath := path.Join(ctxt.RootContext().AssemblyDir(), cmdContext.FileName)
-				logger.Trace().Msgf("Reading from %s", filePath)
-				input, err = os.ReadFile(filePath)
-				if err != nil {
-					logger.Fatal().Err(err).Msgf("Failed to read regex-assembly file %s", filePath)
+				return
+			}
+
+			watcher, err := regexInternal.NewWatcher(cmdContext)
+			if err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			defer watcher.Close()
+			regenerate := func() {
+				if err := generate(filePath, withSourceMap, cmdContext); err != nil {
+					logger.Error().Err(err).Msgf("Failed to generate regular expression for %s", cmdContext.FileName)
+					return
+				}
+				if !withSourceMap {
+					// Separate the expressions of subsequent runs
+					os.Stdout.WriteString("\n")
 				}
 			}
-			assembly, err := assembler.Run(string(input))
+			regenerate()
+			err = watcher.Run(cmd.Context().Done(), func(filePaths []string) {
+				if slices.Contains(filePaths, filePath) {
+					regenerate()
+				}
+			})
 			if err != nil {
 				logger.Fatal().Err(err).Send()
 			}
-			os.Stdout.WriteString(assembly)
 		},
 	}
+
+	buildFlags(cmd)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("source-map", false, `Print a JSON document containing the generated regular expression
+and a source map pointing back to the regex-assembly lines`)
+	cmd.Flags().BoolP("watch", "w", false, `Keep watching the regex-assembly, include and exclude directories
+and print the regular expression again whenever the file or one of its includes changes`)
+}
+
+// generate prints the regular expression for the regex-assembly file at `filePath`, or for stdin,
+// optionally as a JSON document with a source map.
+func generate(filePath string, withSourceMap bool, cmdContext *regexInternal.CommandContext) error {
+	if !withSourceMap {
+		assembly, err := regexInternal.Assemble(filePath, cmdContext.RootContext(), cmdContext)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.WriteString(assembly)
+		return err
+	}
+
+	_, sourceMap, err := regexInternal.AssembleWithSourceMap(filePath, cmdContext.RootContext(), cmdContext)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sourceMap); err != nil {
+		return fmt.Errorf("failed to write source map: %w", err)
+	}
+	return nil
 }
@@ -4,6 +4,8 @@
 package generate
 
 import (
+	"encoding/json"
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -26,6 +28,8 @@ type generateTestSuite struct {
 
 func (s *generateTestSuite) SetupTest() {
 	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
 	s.dataDir = path.Join(s.rootDir, "regex-assembly")
 	err := os.MkdirAll(s.dataDir, fs.ModePerm)
 	s.Require().NoError(err)
@@ -65,6 +69,55 @@ func (s *generateTestSuite) TestGenerate_Dash() {
 	s.True(s.cmdContext.UseStdin)
 }
 
+func (s *generateTestSuite) TestGenerate_DashWithWatch() {
+	s.cmd.SetArgs([]string{"-", "--watch"})
+	_, err := s.cmd.ExecuteC()
+
+	s.ErrorContains(err, "--watch")
+}
+
+func (s *generateTestSuite) TestGenerate_SourceMap() {
+	read := s.captureStdout()
+	s.writeDatafile("123456.ra", "##! comment\nfoo\nbar\n")
+	s.cmd.SetArgs([]string{"123456", "--source-map"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	sourceMap := struct {
+		Regex    string
+		Mappings []struct {
+			Start   int
+			End     int
+			Sources []struct {
+				File string
+				Line int
+			}
+		}
+	}{}
+	s.Require().NoError(json.Unmarshal(output, &sourceMap))
+	s.Equal("foo|bar", sourceMap.Regex)
+	s.Len(sourceMap.Mappings, 3)
+	s.Equal(4, sourceMap.Mappings[2].Start)
+	s.Equal(7, sourceMap.Mappings[2].End)
+	s.Equal(path.Join(s.dataDir, "123456.ra"), sourceMap.Mappings[2].Sources[0].File)
+	s.Equal(3, sourceMap.Mappings[2].Sources[0].Line)
+}
+
+func (s *generateTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
 func (s *generateTestSuite) writeDatafile(filename string, contents string) {
 	err := os.WriteFile(path.Join(s.dataDir, filename), []byte(contents), fs.ModePerm)
 	s.Require().NoError(err)
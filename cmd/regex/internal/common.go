@@ -2,6 +2,7 @@ package internal
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
@@ -9,10 +10,14 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/context"
 	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/cache"
 	"github.com/coreruleset/crs-toolchain/v2/regex/operators"
 	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
+const cacheDirectoryName = "regex-assembly"
+
 func ParseRuleId(idAndChainOffset string, cmdContext *CommandContext) error {
 	cmdContext.UseStdin = false
 
@@ -41,27 +46,100 @@ func ParseRuleId(idAndChainOffset string, cmdContext *CommandContext) error {
 	return nil
 }
 
+// RunAssemble generates the regular expression for the regex-assembly file at `filePath`, or for
+// stdin. Unless caching is disabled, the result is read from the cache if none of the inputs
+// changed since the last run. Exits the process on errors.
 func RunAssemble(filePath string, rootContext *context.Context, cmdContext *CommandContext) string {
+	assembly, err := Assemble(filePath, rootContext, cmdContext)
+	if err != nil {
+		cmdContext.Logger.Fatal().Err(err).Send()
+	}
+	return assembly
+}
+
+// Assemble behaves like RunAssemble but returns errors instead of exiting the process.
+func Assemble(filePath string, rootContext *context.Context, cmdContext *CommandContext) (string, error) {
+	assembler, input, err := prepareAssemble(filePath, rootContext, cmdContext)
+	if err != nil {
+		return "", err
+	}
+	assemblyCache, key := openCache(rootContext, input, cmdContext)
+	if assemblyCache != nil {
+		if assembly, found := assemblyCache.Get(key); found {
+			cmdContext.Logger.Debug().Msgf("Using cached regular expression for %s", filePath)
+			return assembly, nil
+		}
+	}
+
+	assembly, err := assembler.Run(input)
+	if err != nil {
+		return "", err
+	}
+	if assemblyCache != nil {
+		if err := assemblyCache.Put(key, assembly); err != nil {
+			cmdContext.Logger.Debug().Err(err).Msg("Failed to write cache entry")
+		}
+	}
+	return assembly, nil
+}
+
+// RunAssembleWithSourceMap behaves like RunAssemble but also returns the source map of the
+// generated expression.
+func RunAssembleWithSourceMap(filePath string, rootContext *context.Context, cmdContext *CommandContext) (string, *operators.SourceMap) {
+	assembly, sourceMap, err := AssembleWithSourceMap(filePath, rootContext, cmdContext)
+	if err != nil {
+		cmdContext.Logger.Fatal().Err(err).Send()
+	}
+	return assembly, sourceMap
+}
+
+// AssembleWithSourceMap behaves like RunAssembleWithSourceMap but returns errors instead of
+// exiting the process.
+func AssembleWithSourceMap(filePath string, rootContext *context.Context, cmdContext *CommandContext) (string, *operators.SourceMap, error) {
+	assembler, input, err := prepareAssemble(filePath, rootContext, cmdContext)
+	if err != nil {
+		return "", nil, err
+	}
+	return assembler.RunWithSourceMap(input)
+}
+
+// openCache returns the cache and the key for `input`, or nil if caching is disabled or not possible.
+func openCache(rootContext *context.Context, input string, cmdContext *CommandContext) (*cache.Cache, string) {
+	if cmdContext.NoCache {
+		return nil, ""
+	}
+	directory, err := utils.GetCacheFilePath(cacheDirectoryName)
+	if err != nil {
+		cmdContext.Logger.Debug().Err(err).Msg("Failed to find cache directory, not using the cache")
+		return nil, ""
+	}
+	assemblyCache := cache.New(rootContext, directory)
+	key, err := assemblyCache.Key([]byte(input))
+	if err != nil {
+		cmdContext.Logger.Debug().Err(err).Msg("Failed to compute cache key, not using the cache")
+		return nil, ""
+	}
+	return assemblyCache, key
+}
+
+func prepareAssemble(filePath string, rootContext *context.Context, cmdContext *CommandContext) (*operators.Operator, string, error) {
 	ctxt := processors.NewContext(rootContext)
-	assembler := operators.NewAssembler(ctxt)
+	assembler := operators.NewAssemblerForFile(ctxt, filePath)
 	var input []byte
 	var err error
 	if cmdContext.UseStdin {
+		assembler = operators.NewAssembler(ctxt)
 		cmdContext.Logger.Trace().Msg("Reading from stdin")
 		input, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			cmdContext.Logger.Fatal().Err(err).Msg("Failed to read from stdin")
+			return nil, "", fmt.Errorf("failed to read from stdin: %w", err)
 		}
 	} else {
 		cmdContext.Logger.Trace().Msgf("Reading from %s", filePath)
 		input, err = os.ReadFile(filePath)
 		if err != nil {
-			cmdContext.Logger.Fatal().Err(err).Msgf("Failed to read regex-assembly file %s", filePath)
+			return nil, "", fmt.Errorf("failed to read regex-assembly file %s: %w", filePath, err)
 		}
 	}
-	assembly, err := assembler.Run(string(input))
-	if err != nil {
-		cmdContext.Logger.Fatal().Err(err).Send()
-	}
-	return assembly
+	return assembler, string(input), nil
 }
@@ -0,0 +1,30 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// FindAssemblyFiles returns the paths of all regex-assembly files in `directory` and its
+// subdirectories, in lexical order.
+func FindAssemblyFiles(directory string) ([]string, error) {
+	filePaths := []string{}
+	err := filepath.WalkDir(directory, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && path.Ext(d.Name()) == ".ra" {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find regex-assembly files: %w", err)
+	}
+	return filePaths, nil
+}
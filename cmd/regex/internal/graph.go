@@ -0,0 +1,124 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+const (
+	GraphText string = "text"
+	GraphDot  string = "dot"
+	GraphJson string = "json"
+)
+
+// GraphFormatFlag selects the format in which include graphs are printed. It satisfies the
+// interface of pflag.Value.
+type GraphFormatFlag struct {
+	Format string
+}
+
+// graphDocument is the JSON representation of an include graph. All paths are relative to the
+// regex-assembly directory.
+type graphDocument struct {
+	Files   []string                      `json:"files"`
+	Rules   []string                      `json:"rules"`
+	Edges   []dependencies.Edge           `json:"edges"`
+	Missing []dependencies.MissingInclude `json:"missing"`
+}
+
+func (g *GraphFormatFlag) String() string {
+	return g.Format
+}
+
+func (g *GraphFormatFlag) Set(value string) error {
+	switch value {
+	case GraphText, GraphDot, GraphJson:
+		g.Format = value
+		return nil
+	default:
+		return fmt.Errorf("invalid option for format: '%s'", value)
+	}
+}
+
+func (g *GraphFormatFlag) Type() string {
+	return "graph format"
+}
+
+// WriteGraph writes `graph` to `writer` in Graphviz (`dot`) or JSON format. Paths are written
+// relative to `directory`.
+func WriteGraph(writer io.Writer, graph *dependencies.Graph, format string, directory string) error {
+	document := graphDocument{
+		Files:   []string{},
+		Rules:   []string{},
+		Edges:   []dependencies.Edge{},
+		Missing: []dependencies.MissingInclude{},
+	}
+	for _, filePath := range graph.Files() {
+		document.Files = append(document.Files, internal.RelativePath(directory, filePath))
+		for _, edge := range graph.Edges(filePath) {
+			document.Edges = append(document.Edges, dependencies.Edge{
+				From: internal.RelativePath(directory, edge.From),
+				To:   internal.RelativePath(directory, edge.To),
+				Kind: edge.Kind,
+			})
+		}
+	}
+	for _, filePath := range graph.Rules() {
+		document.Rules = append(document.Rules, internal.RelativePath(directory, filePath))
+	}
+	for _, missing := range graph.Missing() {
+		document.Missing = append(document.Missing, dependencies.MissingInclude{
+			From: internal.RelativePath(directory, missing.From),
+			Name: missing.Name,
+		})
+	}
+
+	switch format {
+	case GraphJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case GraphDot:
+		return writeDot(writer, &document)
+	default:
+		return fmt.Errorf("unsupported graph format: '%s'", format)
+	}
+}
+
+func writeDot(writer io.Writer, document *graphDocument) error {
+	lines := []string{"digraph includes {", "\trankdir=LR;"}
+	rules := map[string]bool{}
+	for _, rule := range document.Rules {
+		rules[rule] = true
+	}
+	for _, file := range document.Files {
+		if rules[file] {
+			lines = append(lines, fmt.Sprintf("\t%s [shape=box];", strconv.Quote(file)))
+		} else {
+			lines = append(lines, fmt.Sprintf("\t%s;", strconv.Quote(file)))
+		}
+	}
+	for _, edge := range document.Edges {
+		attributes := ""
+		if edge.Kind == dependencies.ExcludeEdge {
+			attributes = " [style=dashed]"
+		}
+		lines = append(lines, fmt.Sprintf("\t%s -> %s%s;", strconv.Quote(edge.From), strconv.Quote(edge.To), attributes))
+	}
+	lines = append(lines, "}")
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import "runtime"
+
+// RunJobs calls `work` for every item, using up to `jobs` goroutines, and calls `report` with
+// the result of every item in the order of `items`. If `jobs` is smaller than 1, the number of
+// CPUs is used.
+// `report` is always called from the calling goroutine, so it can safely print results or
+// write files that are shared between items. If `report` returns an error, no further results
+// are reported and the error is returned.
+func RunJobs[T any, R any](items []T, jobs int, work func(T) R, report func(T, R) error) error {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+
+	results := make([]chan R, len(items))
+	for i := range results {
+		results[i] = make(chan R, 1)
+	}
+	indices := make(chan int)
+	done := make(chan struct{})
+	defer close(done)
+
+	for range min(jobs, len(items)) {
+		go func() {
+			for index := range indices {
+				results[index] <- work(items[index])
+			}
+		}()
+	}
+	go func() {
+		defer close(indices)
+		for i := range items {
+			select {
+			case indices <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for i, item := range items {
+		if err := report(item, <-results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
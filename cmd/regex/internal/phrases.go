@@ -0,0 +1,38 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+// Output returns the output kind that the regex-assembly file at `filePath` declares
+// (##!> output <kind>), `parser.RegexOutput` if it doesn't declare any.
+func Output(filePath string, rootContext *context.Context) (string, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read regex-assembly file %s: %w", filePath, err)
+	}
+	outputParser := parser.NewParserForFile(processors.NewContext(rootContext), filePath, bytes.NewReader(contents))
+	if _, err := outputParser.Parse(true); err != nil {
+		return "", err
+	}
+	return outputParser.Output, nil
+}
+
+// AssemblePhrases returns the phrases of the regex-assembly file at `filePath`, or of stdin, which
+// must declare phrase output (##!> output phrases).
+func AssemblePhrases(filePath string, rootContext *context.Context, cmdContext *CommandContext) ([]string, error) {
+	assembler, input, err := prepareAssemble(filePath, rootContext, cmdContext)
+	if err != nil {
+		return nil, err
+	}
+	return assembler.RunPhrases(input)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+// ChangedRules returns the paths of the regex-assembly files of the rules that are affected by
+// changes since the git revision `since`, i.e., the rules whose own regex-assembly file, or one
+// of the files it includes, differs between `since` and the work tree. Uncommitted and untracked
+// files count as changes.
+func ChangedRules(rootContext *context.Context, since string) ([]string, error) {
+	changed, err := changedFiles(rootContext.RootDir(), since)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := dependencies.NewGraph(rootContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build include graph: %w", err)
+	}
+	return graph.AffectedRules(changed...), nil
+}
+
+// changedFiles returns the paths of the regex-assembly files that differ between the git
+// revision `since` and the work tree of the repository that contains `directory`.
+func changedFiles(directory string, since string) ([]string, error) {
+	repository, err := git.PlainOpenWithOptions(directory, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", directory, err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	sinceTree, err := resolveTree(repository, since)
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := resolveTree(repository, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	changes, err := object.DiffTree(sinceTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with HEAD: %w", since, err)
+	}
+	for _, change := range changes {
+		// Either name is empty for insertions and deletions
+		names[change.From.Name] = true
+		names[change.To.Name] = true
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status of work tree: %w", err)
+	}
+	for name, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			names[name] = true
+		}
+	}
+
+	root := worktree.Filesystem.Root()
+	changed := []string{}
+	for name := range names {
+		if path.Ext(name) == ".ra" {
+			changed = append(changed, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return changed, nil
+}
+
+func resolveTree(repository *git.Repository, revision string) (*object.Tree, error) {
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve git revision %s: %w", revision, err)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", revision, err)
+	}
+	return commit.Tree()
+}
@@ -14,6 +14,7 @@ type CommandContext struct {
 	FileName     string
 	ChainOffset  uint8
 	UseStdin     bool
+	NoCache      bool
 }
 
 func NewCommandContext(cmdContext *internal.CommandContext, logger *zerolog.Logger) *CommandContext {
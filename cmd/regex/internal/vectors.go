@@ -0,0 +1,82 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+// TestVectorError is returned when a generated regular expression doesn't behave as expected by
+// the test vectors of its regex-assembly file.
+type TestVectorError struct {
+	Failed []parser.TestVector
+}
+
+func (e *TestVectorError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "regular expression fails %d test vectors:", len(e.Failed))
+	for _, vector := range e.Failed {
+		fmt.Fprintf(&sb, "\n  %s: %s", vector.Location, vector)
+	}
+	return sb.String()
+}
+
+// TestVectors returns the test vectors of the regex-assembly file at `filePath`. Includes are not
+// resolved, test vectors of included files don't apply to the including file.
+func TestVectors(filePath string, rootContext *context.Context) ([]parser.TestVector, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read regex-assembly file %s: %w", filePath, err)
+	}
+	vectorParser := parser.NewParserForFile(processors.NewContext(rootContext), filePath, bytes.NewReader(contents))
+	if _, err := vectorParser.Parse(true); err != nil {
+		return nil, err
+	}
+	return vectorParser.TestVectors(), nil
+}
+
+// FailedTestVectors returns the vectors of `vectors` that the regular expression `assembly`
+// doesn't satisfy.
+func FailedTestVectors(assembly string, vectors []parser.TestVector) ([]parser.TestVector, error) {
+	expression, err := regexp.Compile(assembly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile generated regular expression: %w", err)
+	}
+	failed := []parser.TestVector{}
+	for _, vector := range vectors {
+		if !vector.Verify(expression) {
+			failed = append(failed, vector)
+		}
+	}
+	return failed, nil
+}
+
+// VerifyTestVectors checks `assembly`, the regular expression generated from the regex-assembly
+// file at `filePath`, against the test vectors of the file. Returns a *TestVectorError if any
+// of the vectors fails.
+func VerifyTestVectors(filePath string, assembly string, rootContext *context.Context) error {
+	vectors, err := TestVectors(filePath, rootContext)
+	if err != nil {
+		return err
+	}
+	if len(vectors) == 0 {
+		return nil
+	}
+	failed, err := FailedTestVectors(assembly, vectors)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return &TestVectorError{Failed: failed}
+	}
+	return nil
+}
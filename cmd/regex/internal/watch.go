@@ -0,0 +1,115 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+// watchDebounce is the time to wait for further events before processing changes. Editors
+// usually produce several events for a single save.
+const watchDebounce = 100 * time.Millisecond
+
+// Watcher watches the regex-assembly, include and exclude directories for changes.
+type Watcher struct {
+	cmdContext *CommandContext
+	watcher    *fsnotify.Watcher
+}
+
+// NewWatcher creates a watcher for the directories of the root context. Changes are recorded
+// from the moment the watcher has been created. The include and exclude directories are
+// optional.
+func NewWatcher(cmdContext *CommandContext) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	rootContext := cmdContext.RootContext()
+	for _, directory := range []string{rootContext.AssemblyDir(), rootContext.IncludesDir(), rootContext.ExcludesDir()} {
+		if err := watcher.Add(directory); err != nil {
+			if errors.Is(err, fs.ErrNotExist) && directory != rootContext.AssemblyDir() {
+				cmdContext.Logger.Debug().Msgf("Not watching %s, directory does not exist", directory)
+				continue
+			}
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", directory, err)
+		}
+	}
+	return &Watcher{
+		cmdContext: cmdContext,
+		watcher:    watcher,
+	}, nil
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// Run processes changes until `done` is closed. After every change, `onChange` is called with the
+// paths of the regex-assembly files that are affected by the change, i.e., the files that changed
+// themselves and the files that include a changed file, directly or transitively.
+// `onChange` is called from the calling goroutine.
+func (w *Watcher) Run(done <-chan struct{}, onChange func(filePaths []string)) error {
+	w.cmdContext.Logger.Info().Msgf("Watching %s for changes", w.cmdContext.RootContext().AssemblyDir())
+
+	changed := map[string]bool{}
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case <-done:
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || path.Ext(event.Name) != ".ra" {
+				continue
+			}
+			w.cmdContext.Logger.Debug().Msgf("Detected change: %s", event)
+			changed[filepath.Clean(event.Name)] = true
+			timer.Reset(watchDebounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.cmdContext.Logger.Error().Err(err).Msg("Failed to watch for changes")
+		case <-timer.C:
+			affected, err := w.affectedFiles(changed)
+			changed = map[string]bool{}
+			if err != nil {
+				w.cmdContext.Logger.Error().Err(err).Msg("Failed to determine affected files")
+				continue
+			}
+			if len(affected) > 0 {
+				onChange(affected)
+			}
+		}
+	}
+}
+
+// affectedFiles returns the paths of the regex-assembly files that either are one of the
+// `changed` files or depend on one of them.
+func (w *Watcher) affectedFiles(changed map[string]bool) ([]string, error) {
+	graph, err := dependencies.NewGraph(w.cmdContext.RootContext())
+	if err != nil {
+		return nil, err
+	}
+	filePaths := []string{}
+	for filePath := range changed {
+		filePaths = append(filePaths, filePath)
+	}
+	return graph.AffectedRules(filePaths...), nil
+}
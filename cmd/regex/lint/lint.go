@@ -0,0 +1,277 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+	regexLint "github.com/coreruleset/crs-toolchain/v2/regex/lint"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+const (
+	formatText   string = "text"
+	formatGitHub string = "github"
+	formatJson   string = "json"
+)
+
+// syntaxCheck is the name of the check under which syntax errors are reported
+const syntaxCheck = "syntax"
+
+var logger = log.With().Str("component", "cmd.regex.lint").Logger()
+
+// formatFlag satisfies the interface of pflag.Value, see cmd/internal/flag_types.go.
+type formatFlag struct {
+	format string
+}
+
+func (f *formatFlag) String() string {
+	return f.format
+}
+
+func (f *formatFlag) Set(value string) error {
+	switch value {
+	case formatText, formatGitHub, formatJson:
+		f.format = value
+		return nil
+	default:
+		return fmt.Errorf("invalid option for format: '%s'", value)
+	}
+}
+
+func (f *formatFlag) Type() string {
+	return "format"
+}
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	format := &formatFlag{format: formatText}
+	cmd := &cobra.Command{
+		Use:   "lint [RULE_ID | --all]",
+		Short: "Check regex-assembly files for common mistakes",
+		Long: `Check regex-assembly files for common mistakes.
+
+RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
+If the rule is a chained rule, RULE_ID must be specified with the
+offset of the chain from the chain starter rule. For example, for
+a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --all, all regex-assembly files are checked, including the files
+in the include and exclude directories.
+
+The following checks are available, all of them are enabled by default:
+` + describeChecks() + `
+Problems are printed as text, as GitHub annotations, or as JSON, depending on
+--format. Without --format, GitHub annotations are printed if --output is 'github',
+and a JSON, SARIF or JUnit XML document if --output is 'json', 'sarif' or 'junit'.
+The command fails if any problem was found.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			allFlag := cmd.Flags().Lookup("all")
+			if !allFlag.Changed && len(args) == 0 {
+				return errors.New("expected either RULE_ID or --all flag, found neither")
+			} else if allFlag.Changed && len(args) > 0 {
+				return errors.New("expected either RULE_ID or --all flag, found both")
+			} else if len(args) > 1 {
+				return errors.New("expected a single RULE_ID")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil
+			}
+			err := regexInternal.ParseRuleId(args[0], cmdContext)
+			if err != nil {
+				cmd.PrintErrf("failed to parse the rule ID from the input '%s'\n", args[0])
+				return err
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext := cmdContext.RootContext()
+			enable, err := cmd.Flags().GetStringSlice("enable")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read enable flag")
+				return err
+			}
+			disable, err := cmd.Flags().GetStringSlice("disable")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read disable flag")
+				return err
+			}
+			checks, err := regexLint.Select(regexLint.Checks(), enable, disable)
+			if err != nil {
+				return err
+			}
+			outputFormat := format.format
+			var report *internal.Report
+			if !cmd.Flags().Changed("format") {
+				switch cmdContext.OuterContext.Output {
+				case internal.GitHub:
+					outputFormat = formatGitHub
+				case internal.Json, internal.Sarif, internal.JUnit:
+					report = internal.NewReport(cmdContext.OuterContext.Output)
+				}
+			}
+
+			filePaths := []string{}
+			if len(args) > 0 {
+				filePaths = append(filePaths, filepath.Join(rootContext.AssemblyDir(), cmdContext.FileName))
+			} else {
+				filePaths, err = regexInternal.FindAssemblyFiles(rootContext.AssemblyDir())
+				if err != nil {
+					return err
+				}
+			}
+
+			problems := []regexLint.Problem{}
+			for _, filePath := range filePaths {
+				logger.Debug().Msgf("Linting %s", filePath)
+				fileProblems, err := lintFile(filePath, rootContext, checks)
+				if err != nil {
+					return err
+				}
+				problems = append(problems, fileProblems...)
+			}
+			if report != nil {
+				err = reportProblems(os.Stdout, report, problems, checks, filePaths, rootContext)
+			} else {
+				err = writeProblems(os.Stdout, problems, outputFormat)
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(problems) > 0 {
+				// Problems are not command related
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d problems in %d files", len(problems), countFiles(problems))
+			}
+			logger.Info().Msgf("No problems found in %d files", len(filePaths))
+			return nil
+		},
+	}
+
+	buildFlags(cmd, format)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command, format *formatFlag) {
+	cmd.Flags().BoolP("all", "a", false, "Instead of supplying a RULE_ID, check all regex-assembly files")
+	cmd.Flags().StringSlice("enable", nil, "Only run the named checks (comma separated)")
+	cmd.Flags().StringSlice("disable", nil, "Do not run the named checks (comma separated)")
+	cmd.Flags().Var(format, "format", "Output format. One of 'text', 'github', 'json'.")
+}
+
+func describeChecks() string {
+	var sb strings.Builder
+	for _, check := range regexLint.Checks() {
+		fmt.Fprintf(&sb, "  %-22s %s\n", check.Name, check.Description)
+	}
+	return sb.String()
+}
+
+// lintFile runs `checks` on the regex-assembly file at `filePath`. Syntax errors are reported as
+// problems as well. File names in problems are relative to the root directory.
+func lintFile(filePath string, rootContext *context.Context, checks []*regexLint.Check) ([]regexLint.Problem, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read regex-assembly file %s: %w", filePath, err)
+	}
+
+	problems := []regexLint.Problem{}
+	file, err := ast.Parse(internal.RelativePath(rootContext.RootDir(), filePath), contents)
+	var syntaxErrors ast.ErrorList
+	if errors.As(err, &syntaxErrors) {
+		for _, syntaxError := range syntaxErrors {
+			problems = append(problems, regexLint.Problem{
+				Check:   syntaxCheck,
+				File:    syntaxError.File,
+				Line:    syntaxError.Position.Line,
+				Column:  syntaxError.Position.Column,
+				Message: syntaxError.Message,
+			})
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	problems = append(problems, regexLint.Lint(file, processors.NewContext(rootContext), checks)...)
+	regexLint.SortProblems(problems)
+	return problems, nil
+}
+
+func writeProblems(writer io.Writer, problems []regexLint.Problem, format string) error {
+	switch format {
+	case formatJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(problems)
+	case formatGitHub:
+		for _, problem := range problems {
+			_, err := fmt.Fprintf(writer, "::error file=%s,line=%d,col=%d,title=%s::%s\n",
+				problem.File, problem.Line, problem.Column, problem.Check, problem.Message)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		for _, problem := range problems {
+			if _, err := fmt.Fprintln(writer, problem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reportProblems adds `problems` to `report` and writes the report to `writer`. For each of the
+// `checks`, and the syntax check, the files at `filePaths` without problems are recorded as
+// passed.
+func reportProblems(writer io.Writer, report *internal.Report, problems []regexLint.Problem, checks []*regexLint.Check, filePaths []string, rootContext *context.Context) error {
+	failed := map[[2]string]bool{}
+	for _, problem := range problems {
+		failed[[2]string{problem.Check, problem.File}] = true
+		report.Add(internal.Diagnostic{
+			Check:   problem.Check,
+			Level:   internal.LevelError,
+			File:    problem.File,
+			Line:    problem.Line,
+			Column:  problem.Column,
+			Message: problem.Message,
+		})
+	}
+	names := []string{syntaxCheck}
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	for _, name := range names {
+		for _, filePath := range filePaths {
+			relativePath := internal.RelativePath(rootContext.RootDir(), filePath)
+			if !failed[[2]string{name, relativePath}] {
+				report.Pass(name, "", relativePath)
+			}
+		}
+	}
+	return report.Write(writer)
+}
+
+func countFiles(problems []regexLint.Problem) int {
+	files := map[string]bool{}
+	for _, problem := range problems {
+		files[problem.File] = true
+	}
+	return len(files)
+}
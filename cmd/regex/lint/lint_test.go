@@ -0,0 +1,169 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	regexLint "github.com/coreruleset/crs-toolchain/v2/regex/lint"
+)
+
+type lintTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+func (s *lintTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+
+	s.writeFile(s.dataDir, "123456.ra", "##!> define unused foo\nfoo.bar\n")
+	s.writeFile(s.dataDir, "123457.ra", "foo\n")
+	s.writeFile(s.includeDir, "words.ra", "foo\nfoo\n##!<\n")
+}
+
+func TestRunLintTestSuite(t *testing.T) {
+	suite.Run(t, new(lintTestSuite))
+}
+
+func (s *lintTestSuite) TestLint_NoRuleId() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *lintTestSuite) TestLint_RuleIdAndAll() {
+	s.cmd.SetArgs([]string{"123456", "--all"})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *lintTestSuite) TestLint_NoProblems() {
+	output, err := s.run("123457")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *lintTestSuite) TestLint_RuleId() {
+	output, err := s.run("123456")
+	s.EqualError(err, "found 2 problems in 1 files")
+	s.Equal(`regex-assembly/123456.ra:1:13: definition unused is never used [unused-definition]
+regex-assembly/123456.ra:2:4: unescaped '.' matches any character, use '\.' to match a dot [unescaped-dot]
+`, output)
+}
+
+func (s *lintTestSuite) TestLint_All() {
+	output, err := s.run("--all", "--disable", "unused-definition,unescaped-dot")
+	s.EqualError(err, "found 2 problems in 1 files")
+	s.Equal(`regex-assembly/include/words.ra:2:1: duplicate line, first seen on line 1 [duplicate-line]
+regex-assembly/include/words.ra:3:1: end of block without matching start [syntax]
+`, output)
+}
+
+func (s *lintTestSuite) TestLint_Enable() {
+	output, err := s.run("123456", "--enable", "unescaped-dot")
+	s.Error(err)
+	s.Equal("regex-assembly/123456.ra:2:4: unescaped '.' matches any character, use '\\.' to match a dot [unescaped-dot]\n", output)
+}
+
+func (s *lintTestSuite) TestLint_UnknownCheck() {
+	s.cmd.SetArgs([]string{"123456", "--disable", "does-not-exist"})
+	_, err := s.cmd.ExecuteC()
+
+	s.EqualError(err, "unknown check 'does-not-exist'")
+}
+
+func (s *lintTestSuite) TestLint_GitHub() {
+	output, err := s.run("123456", "--format", "github", "--enable", "unused-definition")
+	s.Error(err)
+	s.Equal("::error file=regex-assembly/123456.ra,line=1,col=13,title=unused-definition::definition unused is never used\n", output)
+}
+
+func (s *lintTestSuite) TestLint_GitHubFromOutputType() {
+	s.cmdContext.OuterContext.Output = internal.GitHub
+	output, err := s.run("123456", "--enable", "unused-definition")
+	s.Error(err)
+	s.Equal("::error file=regex-assembly/123456.ra,line=1,col=13,title=unused-definition::definition unused is never used\n", output)
+}
+
+func (s *lintTestSuite) TestLint_Json() {
+	output, err := s.run("123456", "--format", "json")
+	s.Error(err)
+
+	problems := []regexLint.Problem{}
+	s.Require().NoError(json.Unmarshal([]byte(output), &problems))
+	s.Equal([]regexLint.Problem{
+		{Check: "unused-definition", File: "regex-assembly/123456.ra", Line: 1, Column: 13, Message: "definition unused is never used"},
+		{Check: "unescaped-dot", File: "regex-assembly/123456.ra", Line: 2, Column: 4, Message: `unescaped '.' matches any character, use '\.' to match a dot`},
+	}, problems)
+}
+
+func (s *lintTestSuite) TestLint_SarifFromOutputType() {
+	s.cmdContext.OuterContext.Output = internal.Sarif
+	output, err := s.run("123456")
+	s.Error(err)
+
+	log := map[string]any{}
+	s.Require().NoError(json.Unmarshal([]byte(output), &log))
+	s.Equal("2.1.0", log["version"])
+	s.Contains(output, `"ruleId": "unused-definition"`)
+	s.Contains(output, `"ruleId": "unescaped-dot"`)
+}
+
+func (s *lintTestSuite) TestLint_InvalidFormat() {
+	s.cmd.SetArgs([]string{"123456", "--format", "sarif"})
+	_, err := s.cmd.ExecuteC()
+
+	s.ErrorContains(err, "invalid option for format")
+}
+
+func (s *lintTestSuite) run(args ...string) (string, error) {
+	read := s.captureStdout()
+	s.cmd.SetArgs(args)
+	_, cmdErr := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	return string(output), cmdErr
+}
+
+func (s *lintTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *lintTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
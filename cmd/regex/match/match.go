@@ -0,0 +1,139 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package match
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+)
+
+var logger = log.With().Str("component", "cmd.regex.match").Logger()
+
+type NoMatchError struct {
+}
+
+func (n *NoMatchError) Error() string {
+	return "regular expression did not match the input"
+}
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "match RULE_ID [INPUT | -]",
+		Short: "Explain which regex-assembly lines match an input",
+		Long: `Explain which regex-assembly lines match an input.
+This command is mainly used for triaging false positives.
+It generates the regular expression for the rule, runs it against
+INPUT and prints the matched text together with the lines of the
+regex-assembly file and its includes that are responsible for the match.
+
+RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
+If the rule is a chained rule, RULE_ID must be specified with the
+offset of the chain from the chain starter rule. For example, to
+match against a second level chained rule, RULE_ID would be 932100-chain2.
+
+If INPUT is omitted or the special token '-' is used, the input is
+read from stdin.
+
+The command exits with a non-zero status if the input doesn't match.`,
+		Args: cobra.RangeArgs(1, 2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := regexInternal.ParseRuleId(args[0], cmdContext)
+			if err != nil {
+				cmd.PrintErrf("failed to parse the rule ID from the input '%s'\n", args[0])
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var input string
+			if len(args) < 2 || args[1] == "-" {
+				logger.Trace().Msg("Reading input from stdin")
+				stdin, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to read from stdin")
+				}
+				input = string(stdin)
+			} else {
+				input = args[1]
+			}
+
+			// Start running. If an error occurs, propagate but don't print anything
+			// command related.
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return performMatch(input, cmdContext)
+		},
+	}
+
+	return cmd
+}
+
+func performMatch(input string, cmdContext *regexInternal.CommandContext) error {
+	rootContext := cmdContext.RootContext()
+	filePath := path.Join(rootContext.AssemblyDir(), cmdContext.FileName)
+	_, sourceMap := regexInternal.RunAssembleWithSourceMap(filePath, rootContext, cmdContext)
+
+	match := sourceMap.Match(input)
+	if match == nil {
+		fmt.Println("No match")
+		return &NoMatchError{}
+	}
+
+	fmt.Printf("Matched %q at offset %d\n", match.Text, match.Start)
+	sources := newSourceReader(rootContext.RootDir())
+	for _, source := range match.Sources {
+		fmt.Printf("  %s: %s\n", sources.displayLocation(source), sources.line(source))
+	}
+
+	return nil
+}
+
+// sourceReader reads the lines of regex-assembly files referenced by a source map, reading
+// every file at most once.
+type sourceReader struct {
+	rootDir string
+	files   map[string][]string
+}
+
+func newSourceReader(rootDir string) *sourceReader {
+	return &sourceReader{
+		rootDir: rootDir,
+		files:   map[string][]string{},
+	}
+}
+
+func (r *sourceReader) line(location parser.SourceLocation) string {
+	lines, ok := r.files[location.File]
+	if !ok {
+		contents, err := os.ReadFile(location.File)
+		if err != nil {
+			logger.Debug().Err(err).Msgf("Failed to read %s", location.File)
+		}
+		lines = strings.Split(string(contents), "\n")
+		r.files[location.File] = lines
+	}
+	if location.Line < 1 || location.Line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[location.Line-1])
+}
+
+func (r *sourceReader) displayLocation(location parser.SourceLocation) string {
+	location.Column = 0
+	if relative, err := filepath.Rel(r.rootDir, location.File); err == nil && !strings.HasPrefix(relative, "..") {
+		location.File = relative
+	}
+	return location.String()
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package match
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+)
+
+type matchTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+func (s *matchTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+}
+
+func TestRunMatchTestSuite(t *testing.T) {
+	suite.Run(t, new(matchTestSuite))
+}
+
+func (s *matchTestSuite) TestMatch_NoRuleId() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *matchTestSuite) TestMatch_Include() {
+	s.writeFile(s.includeDir, "words.ra", "##! words\ncat\nwhoami\n")
+	s.writeFile(s.dataDir, "123456.ra", "##!> include words\nfoo\n")
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"123456", "run whoami now"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal(`Matched "whoami" at offset 4
+  regex-assembly/include/words.ra:3: whoami
+`, string(output))
+}
+
+func (s *matchTestSuite) TestMatch_NoMatch() {
+	s.writeFile(s.dataDir, "123456.ra", "foo\nbar\n")
+	read := s.captureStdout()
+	s.cmd.SetArgs([]string{"123456", "baz"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	var noMatchError *NoMatchError
+	s.ErrorAs(err, &noMatchError)
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Equal("No match\n", string(output))
+}
+
+func (s *matchTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *matchTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package rdeps
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+var logger = log.With().Str("component", "cmd.regex.rdeps").Logger()
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	format := &regexInternal.GraphFormatFlag{Format: regexInternal.GraphText}
+	cmd := &cobra.Command{
+		Use:   "rdeps INCLUDE_NAME",
+		Short: "Show the rules affected by an include file",
+		Long: `Show the rules affected by an include file.
+This command prints the regex-assembly files of all rules that include
+INCLUDE_NAME, directly or transitively, via include or include-except
+directives. These are the rules that must be regenerated and retested
+after INCLUDE_NAME has been changed.
+
+INCLUDE_NAME is the name of a file in the include or exclude directory, as
+it would be written in an include directive, e.g., unix-shell. The file
+extension is optional.
+
+With --format dot or --format json, the include graph of all files
+that depend on INCLUDE_NAME is printed instead, in Graphviz or JSON format.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext := cmdContext.RootContext()
+			include, err := dependencies.Resolve(rootContext, args[0])
+			if err != nil {
+				return err
+			}
+			graph, err := dependencies.NewGraph(rootContext)
+			if err != nil {
+				return fmt.Errorf("failed to build include graph: %w", err)
+			}
+
+			if format.Format != regexInternal.GraphText {
+				subgraph := graph.Subgraph(append([]string{include}, graph.Dependents(include)...))
+				return regexInternal.WriteGraph(os.Stdout, subgraph, format.Format, rootContext.AssemblyDir())
+			}
+			rules := graph.AffectedRules(include)
+			if len(rules) == 0 {
+				logger.Info().Msgf("No rule depends on %s", internal.RelativePath(rootContext.AssemblyDir(), include))
+			}
+			for _, rule := range rules {
+				fmt.Println(internal.RelativePath(rootContext.AssemblyDir(), rule))
+			}
+			return nil
+		},
+	}
+
+	buildFlags(cmd, format)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command, format *regexInternal.GraphFormatFlag) {
+	cmd.Flags().Var(format, "format", "Output format. One of 'text', 'dot', 'json'.")
+}
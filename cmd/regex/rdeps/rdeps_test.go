@@ -0,0 +1,124 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package rdeps
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+)
+
+type rdepsTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	excludeDir string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+func (s *rdepsTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	s.excludeDir = path.Join(s.dataDir, "exclude")
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+
+	s.writeFile(s.dataDir, "123456.ra", "##!> include shell\n")
+	s.writeFile(s.dataDir, "123457-chain1.ra", "##!> include-except other except\n")
+	s.writeFile(s.dataDir, "123458.ra", "foo\n")
+	s.writeFile(s.includeDir, "shell.ra", "##!> include words\n")
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.includeDir, "other.ra", "foo\n")
+	s.writeFile(s.excludeDir, "except.ra", "##!> include words\n")
+}
+
+func TestRunRdepsTestSuite(t *testing.T) {
+	suite.Run(t, new(rdepsTestSuite))
+}
+
+func (s *rdepsTestSuite) TestRdeps_NoIncludeName() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *rdepsTestSuite) TestRdeps_UnknownInclude() {
+	s.cmd.SetArgs([]string{"missing"})
+	_, err := s.cmd.ExecuteC()
+
+	s.ErrorIs(err, fs.ErrNotExist)
+}
+
+func (s *rdepsTestSuite) TestRdeps_Transitive() {
+	output := s.run("words")
+	s.Equal("123456.ra\n123457-chain1.ra\n", output)
+}
+
+func (s *rdepsTestSuite) TestRdeps_WithExtension() {
+	output := s.run("shell.ra")
+	s.Equal("123456.ra\n", output)
+}
+
+func (s *rdepsTestSuite) TestRdeps_Unused() {
+	s.writeFile(s.includeDir, "unused.ra", "foo\n")
+	output := s.run("unused")
+	s.Empty(output)
+}
+
+func (s *rdepsTestSuite) TestRdeps_Dot() {
+	output := s.run("shell", "--format", "dot")
+	s.Equal(`digraph includes {
+	rankdir=LR;
+	"123456.ra" [shape=box];
+	"include/shell.ra";
+	"123456.ra" -> "include/shell.ra";
+}
+`, output)
+}
+
+func (s *rdepsTestSuite) run(args ...string) string {
+	read := s.captureStdout()
+	s.cmd.SetArgs(args)
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	return string(output)
+}
+
+func (s *rdepsTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *rdepsTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
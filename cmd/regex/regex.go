@@ -9,10 +9,16 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/compare"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/deps"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/format"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/generate"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/lint"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/match"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/rdeps"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/test"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/update"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/regex/verify"
 )
 
 var logger = log.With().Str("component", "cmd.regex").Logger()
@@ -28,11 +34,20 @@ generated from the current regex-assembly file.`,
 	}
 
 	regexCmdContext := regexInternal.NewCommandContext(cmdContext, &logger)
+	cmd.PersistentFlags().BoolVar(&regexCmdContext.NoCache, "no-cache", false,
+		`Always generate regular expressions instead of reading them from the cache
+of previously generated expressions`)
 	cmd.AddCommand(
 		compare.New(regexCmdContext),
+		deps.New(regexCmdContext),
 		format.New(regexCmdContext),
 		generate.New(regexCmdContext),
+		lint.New(regexCmdContext),
+		match.New(regexCmdContext),
+		rdeps.New(regexCmdContext),
+		test.New(regexCmdContext),
 		update.New(regexCmdContext),
+		verify.New(regexCmdContext),
 	)
 
 	return cmd
@@ -4,10 +4,13 @@
 package regex
 
 import (
+	"errors"
 	"io/fs"
 	"os"
 	"path"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -25,6 +28,8 @@ type regexTestSuite struct {
 
 func (s *regexTestSuite) SetupTest() {
 	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
 	s.dataDir = path.Join(s.rootDir, "regex-assembly")
 	err := os.MkdirAll(s.dataDir, fs.ModePerm)
 	s.Require().NoError(err)
@@ -76,3 +81,155 @@ func (s *regexTestSuite) TestRegex_ParseRuleIdAndFileName() {
 	s.Equal(uint8(0), s.cmdContext.ChainOffset)
 	s.False(s.cmdContext.UseStdin)
 }
+
+func (s *regexTestSuite) TestRegex_RunJobsReportsInOrder() {
+	items := []int{}
+	for i := range 50 {
+		items = append(items, i)
+	}
+	reported := []int{}
+	err := regexInternal.RunJobs(items, 8, func(item int) int {
+		// Make later items finish first
+		time.Sleep(time.Duration(50-item) * 100 * time.Microsecond)
+		return item * 2
+	}, func(item int, result int) error {
+		s.Equal(item*2, result)
+		reported = append(reported, item)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Equal(items, reported)
+}
+
+func (s *regexTestSuite) TestRegex_RunJobsStopsOnError() {
+	reported := 0
+	err := regexInternal.RunJobs([]string{"a", "b", "c"}, 2, func(item string) string {
+		return item
+	}, func(item string, result string) error {
+		reported++
+		if item == "b" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	s.EqualError(err, "failed")
+	s.Equal(2, reported)
+}
+
+func (s *regexTestSuite) TestRegex_UpdateUsesCache() {
+	err := os.WriteFile(path.Join(s.dataDir, "123456.ra"), []byte("foo\n"), fs.ModePerm)
+	s.Require().NoError(err)
+	ruleFilePath := path.Join(s.rulesDir, "prefix-123-suffix.conf")
+	writeRule := func() {
+		err := os.WriteFile(ruleFilePath, []byte(`SecRule ARGS "@rx old" \`+"\n\t\"id:123456\""), fs.ModePerm)
+		s.Require().NoError(err)
+	}
+	readRegex := func() string {
+		contents, err := os.ReadFile(ruleFilePath)
+		s.Require().NoError(err)
+		return regexp.MustCompile(`@rx (\S+)"`).FindStringSubmatch(string(contents))[1]
+	}
+
+	writeRule()
+	cmd := New(s.cmdContext.OuterContext)
+	cmd.SetArgs([]string{"update", "123456"})
+	_, err = cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Equal("foo", readRegex())
+
+	// Tamper with the cache entry to prove that it is used
+	cacheDir := path.Join(os.Getenv("HOME"), ".crs-toolchain", "regex-assembly")
+	entries, err := os.ReadDir(cacheDir)
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	err = os.WriteFile(path.Join(cacheDir, entries[0].Name()), []byte("cached"), fs.ModePerm)
+	s.Require().NoError(err)
+
+	writeRule()
+	cmd = New(s.cmdContext.OuterContext)
+	cmd.SetArgs([]string{"update", "123456"})
+	_, err = cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Equal("cached", readRegex())
+
+	writeRule()
+	cmd = New(s.cmdContext.OuterContext)
+	cmd.SetArgs([]string{"--no-cache", "update", "123456"})
+	_, err = cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Equal("foo", readRegex())
+}
+
+func (s *regexTestSuite) TestRegex_WatcherReportsAffectedFiles() {
+	includeDir := path.Join(s.dataDir, "include")
+	err := os.Mkdir(includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("foo\n"), fs.ModePerm))
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "123456.ra"), []byte("##!> include words\n"), fs.ModePerm))
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "123457.ra"), []byte("bar\n"), fs.ModePerm))
+
+	watcher, err := regexInternal.NewWatcher(s.cmdContext)
+	s.Require().NoError(err)
+	defer watcher.Close()
+	done := make(chan struct{})
+	changes := make(chan []string, 10)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- watcher.Run(done, func(filePaths []string) {
+			changes <- filePaths
+		})
+	}()
+
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("baz\n"), fs.ModePerm))
+	s.Equal([]string{path.Join(s.dataDir, "123456.ra")}, s.nextChange(changes))
+
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "123457.ra"), []byte("qux\n"), fs.ModePerm))
+	s.Equal([]string{path.Join(s.dataDir, "123457.ra")}, s.nextChange(changes))
+
+	close(done)
+	s.NoError(<-stopped)
+}
+
+func (s *regexTestSuite) TestRegex_WatcherReportsIncludeExceptInBlock() {
+	includeDir := path.Join(s.dataDir, "include")
+	excludeDir := path.Join(s.dataDir, "exclude")
+	for _, directory := range []string{includeDir, excludeDir} {
+		s.Require().NoError(os.Mkdir(directory, fs.ModePerm))
+	}
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("foo\n"), fs.ModePerm))
+	s.Require().NoError(os.WriteFile(path.Join(excludeDir, "except.ra"), []byte("bar\n"), fs.ModePerm))
+	// Formatted files indent the directives inside processor blocks
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "123456.ra"),
+		[]byte("##!> cmdline unix\n  ##!> include-except words except\n##!<\n"), fs.ModePerm))
+
+	watcher, err := regexInternal.NewWatcher(s.cmdContext)
+	s.Require().NoError(err)
+	defer watcher.Close()
+	done := make(chan struct{})
+	changes := make(chan []string, 10)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- watcher.Run(done, func(filePaths []string) {
+			changes <- filePaths
+		})
+	}()
+
+	s.Require().NoError(os.WriteFile(path.Join(includeDir, "words.ra"), []byte("baz\n"), fs.ModePerm))
+	s.Equal([]string{path.Join(s.dataDir, "123456.ra")}, s.nextChange(changes))
+
+	s.Require().NoError(os.WriteFile(path.Join(excludeDir, "except.ra"), []byte("qux\n"), fs.ModePerm))
+	s.Equal([]string{path.Join(s.dataDir, "123456.ra")}, s.nextChange(changes))
+
+	close(done)
+	s.NoError(<-stopped)
+}
+
+func (s *regexTestSuite) nextChange(changes chan []string) []string {
+	select {
+	case filePaths := <-changes:
+		return filePaths
+	case <-time.After(5 * time.Second):
+		s.FailNow("timed out waiting for changes")
+		return nil
+	}
+}
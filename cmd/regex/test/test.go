@@ -0,0 +1,196 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+)
+
+var logger = log.With().Str("component", "cmd.regex.test").Logger()
+
+// testResult holds the test vectors of a regex-assembly file and the ones that failed, or the
+// error that occurred while checking them
+type testResult struct {
+	vectors []parser.TestVector
+	failed  []parser.TestVector
+	err     error
+}
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [RULE_ID | --all]",
+		Short: "Check generated regular expressions against their test vectors",
+		Long: `Check generated regular expressions against their test vectors.
+
+Test vectors are written into regex-assembly files as lines of the form
+'##!? match <input>' or '##!? nomatch <input>'. The generated regular
+expression, including the flags prefix, must match, respectively not match,
+the input. The input is the rest of the line. Inputs enclosed in double quotes
+are unquoted with Go syntax, e.g., "\ttab" or "trailing space ".
+
+Test vectors apply to the regex-assembly file they are written in. Test vectors
+of include and exclude files are checked against the regular expression
+generated from the included file on its own.
+
+RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
+If the rule is a chained rule, RULE_ID must be specified with the
+offset of the chain from the chain starter rule. For example, for
+a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --all, the test vectors of all regex-assembly files are checked, including
+the files in the include and exclude directories.
+
+The command fails if any test vector fails.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			allFlag := cmd.Flags().Lookup("all")
+			if !allFlag.Changed && len(args) == 0 {
+				return errors.New("expected either RULE_ID or --all flag, found neither")
+			} else if allFlag.Changed && len(args) > 0 {
+				return errors.New("expected either RULE_ID or --all flag, found both")
+			} else if len(args) > 1 {
+				return errors.New("expected a single RULE_ID")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil
+			}
+			err := regexInternal.ParseRuleId(args[0], cmdContext)
+			if err != nil {
+				cmd.PrintErrf("failed to parse the rule ID from the input '%s'\n", args[0])
+				return err
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext := cmdContext.RootContext()
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read jobs flag")
+				return err
+			}
+
+			filePaths := []string{}
+			if len(args) > 0 {
+				filePaths = append(filePaths, filepath.Join(rootContext.AssemblyDir(), cmdContext.FileName))
+			} else {
+				filePaths, err = regexInternal.FindAssemblyFiles(rootContext.AssemblyDir())
+				if err != nil {
+					return err
+				}
+			}
+
+			// Problems are not command related from here on
+			cmd.SilenceUsage = true
+			report := internal.NewReport(cmdContext.OuterContext.Output)
+			err = performTest(os.Stdout, report, filePaths, jobs, rootContext, cmdContext)
+			return report.Finish(os.Stdout, err)
+		},
+	}
+
+	buildFlags(cmd)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("all", "a", false, "Instead of supplying a RULE_ID, check the test vectors of all regex-assembly files")
+	cmd.Flags().IntP("jobs", "j", 0, `Number of regex-assembly files to process in parallel with --all.
+Defaults to the number of CPUs. Results are always reported in the same order`)
+}
+
+// performTest checks the test vectors of the regex-assembly files at `filePaths` and writes the
+// failed vectors to `writer`, or adds them to `report` if its output is structured. Files without
+// test vectors are not assembled.
+func performTest(writer io.Writer, report *internal.Report, filePaths []string, jobs int, rootContext *context.Context, cmdContext *regexInternal.CommandContext) error {
+	total, failed, files := 0, 0, 0
+	err := regexInternal.RunJobs(filePaths, jobs, func(filePath string) testResult {
+		return testFile(filePath, rootContext, cmdContext)
+	}, func(filePath string, result testResult) error {
+		if result.err != nil {
+			return result.err
+		}
+		if len(result.vectors) == 0 {
+			logger.Debug().Msgf("No test vectors in %s", filePath)
+			return nil
+		}
+		files++
+		total += len(result.vectors)
+		failed += len(result.failed)
+		for _, vector := range result.failed {
+			if err := writeFailure(writer, report, vector, rootContext, cmdContext); err != nil {
+				return err
+			}
+		}
+		if len(result.failed) == 0 {
+			report.Pass("regex-test", "", internal.RelativePath(rootContext.RootDir(), filePath))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test vectors failed", failed, total)
+	}
+	logger.Info().Msgf("All %d test vectors in %d files passed", total, files)
+	return nil
+}
+
+// testFile assembles the regex-assembly file at `filePath` and checks the result against the
+// test vectors of the file.
+func testFile(filePath string, rootContext *context.Context, cmdContext *regexInternal.CommandContext) testResult {
+	vectors, err := regexInternal.TestVectors(filePath, rootContext)
+	if err != nil || len(vectors) == 0 {
+		return testResult{err: err}
+	}
+	logger.Debug().Msgf("Checking %d test vectors of %s", len(vectors), filePath)
+	assembly, err := regexInternal.Assemble(filePath, rootContext, cmdContext)
+	if err != nil {
+		return testResult{err: err}
+	}
+	failed, err := regexInternal.FailedTestVectors(assembly, vectors)
+	if err != nil {
+		return testResult{err: fmt.Errorf("%s: %w", filePath, err)}
+	}
+	return testResult{vectors: vectors, failed: failed}
+}
+
+func writeFailure(writer io.Writer, report *internal.Report, vector parser.TestVector, rootContext *context.Context, cmdContext *regexInternal.CommandContext) error {
+	message := fmt.Sprintf("expected a match for %q", vector.Input)
+	if !vector.Match {
+		message = fmt.Sprintf("expected no match for %q", vector.Input)
+	}
+	fileName := internal.RelativePath(rootContext.RootDir(), vector.Location.File)
+	report.Add(internal.Diagnostic{
+		Check:   "regex-test",
+		Level:   internal.LevelError,
+		File:    fileName,
+		Line:    vector.Location.Line,
+		Message: message,
+	})
+	if report.Structured() {
+		return nil
+	}
+	var err error
+	if cmdContext.OuterContext.Output == internal.GitHub {
+		_, err = fmt.Fprintf(writer, "::error file=%s,line=%d,title=test vector::%s\n", fileName, vector.Location.Line, message)
+	} else {
+		_, err = fmt.Fprintf(writer, "%s:%d: %s\n", fileName, vector.Location.Line, message)
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+)
+
+type testTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	includeDir string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+func (s *testTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.includeDir = path.Join(s.dataDir, "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+
+	s.writeFile(s.dataDir, "123456.ra", `##!+ i
+##!? match a CAT
+##!? nomatch "dog "
+##!> include words
+`)
+	s.writeFile(s.dataDir, "123457.ra", "foo\n")
+	s.writeFile(s.includeDir, "words.ra", "##!? match cat\ncat\nmouse\n")
+}
+
+func TestRunTestTestSuite(t *testing.T) {
+	suite.Run(t, new(testTestSuite))
+}
+
+func (s *testTestSuite) TestTest_NoRuleId() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *testTestSuite) TestTest_RuleIdAndAll() {
+	s.cmd.SetArgs([]string{"123456", "--all"})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *testTestSuite) TestTest_Passes() {
+	output, err := s.run("123456")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *testTestSuite) TestTest_NoTestVectors() {
+	output, err := s.run("123457")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *testTestSuite) TestTest_Fails() {
+	s.writeFile(s.dataDir, "123456.ra", `##!? match dog
+##!? nomatch "a cat\n"
+##!? match mouse
+##!> include words
+`)
+	output, err := s.run("123456")
+	s.EqualError(err, "2 of 3 test vectors failed")
+	s.Equal(`regex-assembly/123456.ra:1: expected a match for "dog"
+regex-assembly/123456.ra:2: expected no match for "a cat\n"
+`, output)
+}
+
+func (s *testTestSuite) TestTest_FlagsArePartOfTheExpression() {
+	s.writeFile(s.dataDir, "123456.ra", "##!? match CAT\n##!> include words\n")
+	output, err := s.run("123456")
+	s.EqualError(err, "1 of 1 test vectors failed")
+	s.Equal("regex-assembly/123456.ra:1: expected a match for \"CAT\"\n", output)
+}
+
+func (s *testTestSuite) TestTest_All() {
+	s.writeFile(s.includeDir, "words.ra", "##!? match cat\n##!? match dog\ncat\nmouse\n")
+	output, err := s.run("--all")
+	s.EqualError(err, "1 of 4 test vectors failed")
+	s.Equal("regex-assembly/include/words.ra:2: expected a match for \"dog\"\n", output)
+}
+
+func (s *testTestSuite) TestTest_GitHub() {
+	s.cmdContext.OuterContext.Output = internal.GitHub
+	s.writeFile(s.dataDir, "123456.ra", "##!? nomatch cat\n##!> include words\n")
+	output, err := s.run("123456")
+	s.Error(err)
+	s.Equal("::error file=regex-assembly/123456.ra,line=1,title=test vector::expected no match for \"cat\"\n", output)
+}
+
+func (s *testTestSuite) TestTest_Json() {
+	s.cmdContext.OuterContext.Output = internal.Json
+	s.writeFile(s.dataDir, "123456.ra", "##!? nomatch cat\n##!> include words\n")
+	output, err := s.run("123456")
+	s.Error(err)
+	s.JSONEq(`[{
+	"check": "regex-test",
+	"level": "error",
+	"file": "regex-assembly/123456.ra",
+	"line": 1,
+	"message": "expected no match for \"cat\""
+}]`, output)
+}
+
+func (s *testTestSuite) TestTest_InvalidTestVector() {
+	s.writeFile(s.dataDir, "123456.ra", "##!? matches cat\ncat\n")
+	_, err := s.run("123456")
+	s.ErrorContains(err, "unknown test vector kind 'matches'")
+}
+
+func (s *testTestSuite) run(args ...string) (string, error) {
+	read := s.captureStdout()
+	s.cmd.SetArgs(args)
+	_, cmdErr := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	return string(output), cmdErr
+}
+
+func (s *testTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *testTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
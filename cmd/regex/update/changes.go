@@ -0,0 +1,166 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package update
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+)
+
+// PendingChangesError is returned by a dry run if any files would be changed by the update.
+type PendingChangesError struct {
+	Files []string
+}
+
+func (e *PendingChangesError) Error() string {
+	return fmt.Sprintf("%d files would be changed by the update", len(e.Files))
+}
+
+// fileChange is the JSON representation of a file changed by a dry run
+type fileChange struct {
+	File string `json:"file"`
+	Diff string `json:"diff"`
+}
+
+// changeSet writes the files changed by an update, unless they are unchanged. In a dry run,
+// files are not written. Their new contents are kept in memory instead, so that subsequent
+// updates of the same file, e.g., of multiple rules in a rule file, see the earlier changes.
+type changeSet struct {
+	dryRun   bool
+	original map[string][]byte
+	updated  map[string][]byte
+	// paths are the changed files, in order of their first change
+	paths []string
+}
+
+func newChangeSet(dryRun bool) *changeSet {
+	return &changeSet{
+		dryRun:   dryRun,
+		original: map[string][]byte{},
+		updated:  map[string][]byte{},
+		paths:    []string{},
+	}
+}
+
+// read returns the contents of the file at `filePath`, including the changes of a dry run.
+func (c *changeSet) read(filePath string) ([]byte, error) {
+	if contents, ok := c.updated[filePath]; ok {
+		return contents, nil
+	}
+	return os.ReadFile(filePath)
+}
+
+// write replaces the contents of the file at `filePath` with `contents`, unless they are equal.
+// New files are created. The permissions of existing files are kept.
+func (c *changeSet) write(filePath string, contents []byte) error {
+	current, err := c.read(filePath)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	if exists && bytes.Equal(current, contents) {
+		logger.Debug().Msgf("%s is up to date", filePath)
+		return nil
+	}
+
+	if !c.dryRun {
+		mode := fs.FileMode(0644)
+		if info, err := os.Stat(filePath); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(filePath, contents, mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+		return nil
+	}
+
+	if _, ok := c.updated[filePath]; !ok {
+		c.original[filePath] = current
+		c.paths = append(c.paths, filePath)
+	}
+	c.updated[filePath] = contents
+	return nil
+}
+
+// diff returns the unified diff of the changes to the file at `filePath`. File names in the
+// diff are relative to `directory`.
+func (c *changeSet) diff(filePath string, directory string) (string, error) {
+	relativePath := internal.RelativePath(directory, filePath)
+	fromFile := "a/" + relativePath
+	if c.original[filePath] == nil {
+		fromFile = "/dev/null"
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(c.original[filePath]),
+		B:        splitLines(c.updated[filePath]),
+		FromFile: fromFile,
+		ToFile:   "b/" + relativePath,
+		Context:  3,
+	})
+}
+
+// splitLines splits `contents` into lines for difflib, which expects every line to end with a
+// newline. Unlike `difflib.SplitLines`, no empty line is added after the last newline.
+func splitLines(contents []byte) []string {
+	lines := strings.SplitAfter(string(contents), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// report prints the changes of a dry run to `writer`: the names of the changed files, their
+// unified diffs, or a JSON document. Returns a *PendingChangesError if any files were changed.
+func (c *changeSet) report(writer io.Writer, directory string, withDiff bool, asJson bool) error {
+	changes := make([]fileChange, 0, len(c.paths))
+	for _, filePath := range c.paths {
+		diff, err := c.diff(filePath, directory)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, fileChange{File: internal.RelativePath(directory, filePath), Diff: diff})
+	}
+
+	switch {
+	case asJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			return err
+		}
+	case withDiff:
+		for _, change := range changes {
+			if _, err := io.WriteString(writer, change.Diff); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, change := range changes {
+			if _, err := fmt.Fprintln(writer, change.File); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(changes) == 0 {
+		logger.Info().Msg("All rules are up to date")
+		return nil
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		files = append(files, change.File)
+	}
+	return &PendingChangesError{Files: files}
+}
@@ -4,22 +4,23 @@
 package update
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
-	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
 	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
 	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/seclang"
 )
 
 // parsedRuleValues holds the parsed values for a single rule
@@ -29,6 +30,14 @@ type parsedRuleValues struct {
 	chainOffset uint8
 }
 
+// assembleResult holds the generated regular expression or phrases, or the error of a
+// regex-assembly file. `phrases` is only set for files that declare phrase output.
+type assembleResult struct {
+	regex   string
+	phrases []string
+	err     error
+}
+
 var logger = log.With().Str("component", "cmd.regex.update").Logger()
 
 func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
@@ -44,9 +53,48 @@ FILENAME is the name of a regex-assembly file (e.g., 932100.ra, 932100-chain1.ra
 Multiple RULE_IDs and filenames can be specified in any order, separated by spaces.
 If the rule is a chained rule, RULE_ID must be specified with the
 offset of the chain from the chain starter rule. For example, to
-generate a second level chained rule, RULE_ID would be 932100-chain2.`,
+generate a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --watch, the command keeps running after the update. Whenever a
+regex-assembly file, or a file it includes, changes, the affected rules
+are updated again. Only the rules given on the command line are updated,
+or all rules with --all.
+
+With --since, only the rules are updated whose regex-assembly file, or
+one of the files it includes, has changed since the given git revision.
+This includes uncommitted and untracked files.
+
+A rule is not updated if the generated regular expression fails the test
+vectors (##!? match / ##!? nomatch lines) of its regex-assembly file.
+
+A regex-assembly file that declares phrase output (##!> output phrases)
+generates a list of literal phrases instead of a regular expression. The
+phrases replace the argument of @pm, or the contents of the data file of
+@pmFromFile, which is resolved relative to the rule file. Comment lines at
+the start of the data file are preserved.
+
+With --dry-run, no files are written. Instead, the names of the files
+that would change are printed, or their unified diffs with --diff, or a
+JSON document with --json. The command fails if any file would change.`,
 		Args: func(cmd *cobra.Command, args []string) error {
+			dryRunFlag := cmd.Flags().Lookup("dry-run")
+			if !dryRunFlag.Changed && (cmd.Flags().Changed("diff") || cmd.Flags().Changed("json")) {
+				return errors.New("--diff and --json require --dry-run")
+			} else if dryRunFlag.Changed && cmd.Flags().Changed("watch") {
+				return errors.New("--dry-run can't be combined with --watch")
+			} else if cmd.Flags().Changed("diff") && cmd.Flags().Changed("json") {
+				return errors.New("expected either --diff or --json flag, found both")
+			}
 			allFlag := cmd.Flags().Lookup("all")
+			sinceFlag := cmd.Flags().Lookup("since")
+			if sinceFlag.Changed {
+				if allFlag.Changed {
+					return errors.New("expected either --all or --since flag, found both")
+				} else if len(args) > 0 {
+					return errors.New("expected either RULE_ID(s)/filename(s) or --since flag, found both")
+				}
+				return nil
+			}
 			if !allFlag.Changed && len(args) == 0 {
 				return errors.New("expected either RULE_ID(s)/filename(s) or --all flag, found neither")
 			} else if allFlag.Changed && len(args) > 0 {
@@ -74,19 +122,72 @@ generate a second level chained rule, RULE_ID would be 932100-chain2.`,
 			if err != nil {
 				return fmt.Errorf("failed to read value for 'all' flag: %w", err)
 			}
-
-			if processAll {
-				return performUpdateAll(ctxt, cmdContext)
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'jobs' flag: %w", err)
 			}
-			var parsedRules []parsedRuleValues
-			for _, arg := range args {
-				parsedRule, err := parseAndValidateArgument(arg, ctxt)
+			watch, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'watch' flag: %w", err)
+			}
+			since, err := cmd.Flags().GetString("since")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'since' flag: %w", err)
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'dry-run' flag: %w", err)
+			}
+			withDiff, err := cmd.Flags().GetBool("diff")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'diff' flag: %w", err)
+			}
+			asJson, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return fmt.Errorf("failed to read value for 'json' flag: %w", err)
+			}
+			files := newChangeSet(dryRun)
+
+			var watcher *regexInternal.Watcher
+			if watch {
+				// Create the watcher first so that changes made during the initial update aren't missed
+				watcher, err = regexInternal.NewWatcher(cmdContext)
 				if err != nil {
-					return fmt.Errorf("failed to parse argument '%s': %w", arg, err)
+					return err
 				}
-				parsedRules = append(parsedRules, parsedRule)
+				defer watcher.Close()
 			}
-			return performUpdateMultiple(parsedRules, ctxt, cmdContext)
+
+			// nil selects all rules
+			var selected map[string]bool
+			if processAll {
+				err = performUpdateAll(jobs, files, ctxt, cmdContext)
+			} else if since != "" {
+				err = performUpdateSince(since, jobs, files, ctxt, cmdContext)
+			} else {
+				var parsedRules []parsedRuleValues
+				selected = map[string]bool{}
+				for _, arg := range args {
+					parsedRule, err := parseAndValidateArgument(arg, ctxt)
+					if err != nil {
+						return fmt.Errorf("failed to parse argument '%s': %w", arg, err)
+					}
+					parsedRules = append(parsedRules, parsedRule)
+					selected[path.Join(ctxt.RootContext().AssemblyDir(), parsedRule.fileName)] = true
+				}
+				err = performUpdateMultiple(parsedRules, files, ctxt, cmdContext)
+			}
+			if err == nil && dryRun {
+				cmd.SilenceUsage = true
+				return files.report(cmd.OutOrStdout(), ctxt.RootContext().RootDir(), withDiff, asJson)
+			}
+			if err != nil || watcher == nil {
+				return err
+			}
+
+			return watcher.Run(cmd.Context().Done(), func(filePaths []string) {
+				updateAffected(filePaths, selected, jobs, files, ctxt, cmdContext)
+			})
 		},
 	}
 
@@ -97,6 +198,17 @@ generate a second level chained rule, RULE_ID would be 932100-chain2.`,
 func buildFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("all", "a", false, `Instead of supplying RULE_ID(s)/filename(s), you can tell the script to
 update all rules from their regex-assembly files`)
+	cmd.Flags().IntP("jobs", "j", 0, `Number of regex-assembly files to process in parallel with --all, --since or --watch.
+Defaults to the number of CPUs. Rule files are always updated in the same order`)
+	cmd.Flags().String("since", "", `Instead of supplying RULE_ID(s)/filename(s), update only the rules affected by
+changes to regex-assembly, include or exclude files since the given git revision
+(e.g., a branch, tag or commit), including uncommitted changes`)
+	cmd.Flags().BoolP("watch", "w", false, `After updating, keep watching the regex-assembly, include and exclude
+directories and update the rules affected by every change`)
+	cmd.Flags().Bool("dry-run", false, `Don't write any files, print the names of the files that would change instead.
+Fails if any file would change`)
+	cmd.Flags().Bool("diff", false, "With --dry-run, print a unified diff for each file that would change")
+	cmd.Flags().Bool("json", false, "With --dry-run, print the changes as a JSON document")
 }
 
 // extractBasename extracts the basename from a path or filename argument
@@ -153,7 +265,8 @@ func parseRuleIdToStruct(idAndChainOffset string) (parsedRuleValues, error) {
 	}, nil
 }
 
-func performUpdateAll(ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+func performUpdateAll(jobs int, files *changeSet, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+	rules := []parsedRuleValues{}
 	err := filepath.WalkDir(ctx.RootContext().AssemblyDir(), func(filePath string, dirEntry fs.DirEntry, err error) error {
 		if errors.Is(err, fs.ErrNotExist) {
 			// fail
@@ -177,29 +290,130 @@ func performUpdateAll(ctx *processors.Context, cmdContext *regexInternal.Command
 				return err
 			}
 
-			return processRule(id, chainOffset, filePath, ctx, cmdContext)
+			rules = append(rules, parsedRuleValues{id: id, fileName: dirEntry.Name(), chainOffset: chainOffset})
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
-	return nil
+	return updateRules(rules, jobs, files, ctx, cmdContext)
 }
 
-func performUpdateMultiple(parsedRules []parsedRuleValues, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+func performUpdateSince(since string, jobs int, files *changeSet, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+	filePaths, err := regexInternal.ChangedRules(ctx.RootContext(), since)
+	if err != nil {
+		return err
+	}
+	if len(filePaths) == 0 {
+		logger.Info().Msgf("No rules affected by changes since %s", since)
+		return nil
+	}
+
+	rules := []parsedRuleValues{}
+	for _, filePath := range filePaths {
+		rule, err := parseRuleIdToStruct(path.Base(filePath))
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("Rule %s, chain offset %d, is affected by changes since %s", rule.id, rule.chainOffset, since)
+		rules = append(rules, rule)
+	}
+	return updateRules(rules, jobs, files, ctx, cmdContext)
+}
+
+// updateRules assembles the regex-assembly files of `rules` and updates the rules
+func updateRules(rules []parsedRuleValues, jobs int, files *changeSet, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
+	// Assemble in parallel but update in order, as multiple rules share the same rule file
+	return regexInternal.RunJobs(rules, jobs, func(rule parsedRuleValues) assembleResult {
+		filePath := path.Join(ctx.RootContext().AssemblyDir(), rule.fileName)
+		logger.Info().Msgf("Processing %s, chain offset %d", rule.id, rule.chainOffset)
+		return assembleRule(filePath, ctx, cmdContext)
+	}, func(rule parsedRuleValues, result assembleResult) error {
+		if result.err != nil {
+			return result.err
+		}
+		return updateRule(rule.id, rule.chainOffset, path.Join(ctx.RootContext().AssemblyDir(), rule.fileName), result, files, ctx)
+	})
+}
+
+func performUpdateMultiple(parsedRules []parsedRuleValues, files *changeSet, ctx *processors.Context, cmdContext *regexInternal.CommandContext) error {
 	for _, rule := range parsedRules {
 		filePath := path.Join(ctx.RootContext().AssemblyDir(), rule.fileName)
-		if err := processRule(rule.id, rule.chainOffset, filePath, ctx, cmdContext); err != nil {
+		if err := processRule(rule.id, rule.chainOffset, filePath, files, ctx, cmdContext); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func processRule(ruleId string, chainOffset uint8, dataFilePath string, ctxt *processors.Context, cmdContext *regexInternal.CommandContext) error {
+// updateAffected updates the rules of the regex-assembly files at `filePaths` that are `selected`,
+// or all of them if `selected` is nil. Errors are logged instead of returned, as the files are
+// likely being edited.
+func updateAffected(filePaths []string, selected map[string]bool, jobs int, files *changeSet, ctx *processors.Context, cmdContext *regexInternal.CommandContext) {
+	rules := []parsedRuleValues{}
+	for _, filePath := range filePaths {
+		if selected != nil && !selected[filePath] {
+			continue
+		}
+		rule, err := parseRuleIdToStruct(path.Base(filePath))
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to parse rule ID of %s", filePath)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	_ = regexInternal.RunJobs(rules, jobs, func(rule parsedRuleValues) assembleResult {
+		filePath := path.Join(ctx.RootContext().AssemblyDir(), rule.fileName)
+		logger.Info().Msgf("Processing %s, chain offset %d", rule.id, rule.chainOffset)
+		return assembleRule(filePath, ctx, cmdContext)
+	}, func(rule parsedRuleValues, result assembleResult) error {
+		if result.err != nil {
+			logger.Error().Err(result.err).Msgf("Failed to assemble %s", rule.fileName)
+			return nil
+		}
+		filePath := path.Join(ctx.RootContext().AssemblyDir(), rule.fileName)
+		if err := updateRule(rule.id, rule.chainOffset, filePath, result, files, ctx); err != nil {
+			logger.Error().Err(err).Msgf("Failed to update rule %s", rule.id)
+		}
+		return nil
+	})
+}
+
+func processRule(ruleId string, chainOffset uint8, dataFilePath string, files *changeSet, ctxt *processors.Context, cmdContext *regexInternal.CommandContext) error {
 	logger.Info().Msgf("Processing %s, chain offset %d", ruleId, chainOffset)
-	regex := regexInternal.RunAssemble(dataFilePath, ctxt.RootContext(), cmdContext)
+	result := assembleRule(dataFilePath, ctxt, cmdContext)
+	if result.err != nil {
+		return result.err
+	}
+	return updateRule(ruleId, chainOffset, dataFilePath, result, files, ctxt)
+}
+
+// assembleRule generates the regular expression, or the phrases, of the regex-assembly file at
+// `dataFilePath`, depending on the output the file declares.
+func assembleRule(dataFilePath string, ctxt *processors.Context, cmdContext *regexInternal.CommandContext) assembleResult {
+	output, err := regexInternal.Output(dataFilePath, ctxt.RootContext())
+	if err != nil {
+		return assembleResult{err: err}
+	}
+	if output == parser.PhrasesOutput {
+		phrases, err := regexInternal.AssemblePhrases(dataFilePath, ctxt.RootContext(), cmdContext)
+		return assembleResult{phrases: phrases, err: err}
+	}
+	regex, err := regexInternal.Assemble(dataFilePath, ctxt.RootContext(), cmdContext)
+	return assembleResult{regex: regex, err: err}
+}
+
+// updateRule replaces the regular expression, or the phrases, of the rule with `result`, generated
+// from the regex-assembly file at `dataFilePath`. The rule is not updated if the regular expression
+// fails the test vectors of the regex-assembly file.
+func updateRule(ruleId string, chainOffset uint8, dataFilePath string, result assembleResult, files *changeSet, ctxt *processors.Context) error {
+	if result.phrases == nil {
+		if err := regexInternal.VerifyTestVectors(dataFilePath, result.regex, ctxt.RootContext()); err != nil {
+			return fmt.Errorf("refusing to update rule %s: %w", ruleId, err)
+		}
+	}
 
 	rulePrefix := ruleId[:3]
 	matches, err := filepath.Glob(fmt.Sprintf("%s/*-%s-*", ctxt.RootContext().RulesDir(), rulePrefix))
@@ -213,53 +427,94 @@ func processRule(ruleId string, chainOffset uint8, dataFilePath string, ctxt *pr
 	ruleFilePath := matches[0]
 	logger.Debug().Msgf("Processing rule file %s for rule %s", ruleFilePath, ruleId)
 
-	return updateRegex(ruleFilePath, ruleId, chainOffset, regex)
+	if result.phrases != nil {
+		return updatePhrases(ruleFilePath, ruleId, chainOffset, result.phrases, files)
+	}
+	return updateRegex(ruleFilePath, ruleId, chainOffset, result.regex, files)
 }
 
-func updateRegex(filePath string, ruleId string, chainOffset uint8, newRegex string) error {
-	contents, err := os.ReadFile(filePath)
+func updateRegex(filePath string, ruleId string, chainOffset uint8, newRegex string, files *changeSet) error {
+	file, rule, err := findRule(filePath, ruleId, chainOffset, files)
 	if err != nil {
-		return fmt.Errorf("failed to read rule file %s: %w", filePath, err)
-	}
-
-	lines := bytes.Split(contents, []byte("\n"))
-
-	idRegex := regexp.MustCompile(fmt.Sprintf("id:%s", ruleId))
-	index := 0
-	var line []byte
-	foundRule := false
-	chainCount := uint8(0)
-	for index, line = range lines {
-		if !foundRule && idRegex.Match(line) {
-			foundRule = true
-			if chainOffset == 0 {
-				index--
-				break
+		return err
+	}
+	if rule.Operator.Name != "rx" {
+		return fmt.Errorf("rule %s, chain offset %d, in %s doesn't use @rx", ruleId, chainOffset, filePath)
+	}
+	return writeOperatorArgument(file, rule, newRegex, files)
+}
+
+// updatePhrases replaces the argument of the @pm operator of the rule with `phrases`, or the
+// contents of the data file of the @pmFromFile operator.
+func updatePhrases(filePath string, ruleId string, chainOffset uint8, phrases []string, files *changeSet) error {
+	file, rule, err := findRule(filePath, ruleId, chainOffset, files)
+	if err != nil {
+		return err
+	}
+	switch rule.Operator.Name {
+	case "pm":
+		for _, phrase := range phrases {
+			if strings.ContainsAny(phrase, " \t") {
+				return fmt.Errorf("phrase '%s' contains whitespace, which @pm doesn't support, use @pmFromFile instead", phrase)
 			}
-			continue
 		}
-		if foundRule && regex.SecRuleRegex.Match(line) {
-			chainCount++
+		return writeOperatorArgument(file, rule, strings.ReplaceAll(strings.Join(phrases, " "), `"`, `\"`), files)
+	case "pmFromFile", "pmf":
+		dataFileNames := strings.Fields(rule.Operator.Argument.Value)
+		if len(dataFileNames) != 1 {
+			return fmt.Errorf("rule %s, chain offset %d, in %s must use exactly one data file, found %d", ruleId, chainOffset, filePath, len(dataFileNames))
 		}
-		if foundRule && chainCount == chainOffset {
-			break
-		}
-	}
-	if !foundRule || chainOffset != chainCount {
-		return fmt.Errorf("failed to find rule %s, chain offset, %d in %s", ruleId, chainOffset, filePath)
+		return writeDataFile(path.Join(path.Dir(filePath), dataFileNames[0]), phrases, files)
+	default:
+		return fmt.Errorf("rule %s, chain offset %d, in %s doesn't use @pm or @pmFromFile", ruleId, chainOffset, filePath)
 	}
+}
 
-	regexLine := lines[index]
-	found := regex.RuleRxRegex.FindAllStringSubmatch(string(regexLine), -1)
-	if len(found) == 0 {
-		return fmt.Errorf("failed to find rule %s in %s", ruleId, filePath)
+func findRule(filePath string, ruleId string, chainOffset uint8, files *changeSet) (*seclang.File, *seclang.Rule, error) {
+	contents, err := files.read(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read rule file %s: %w", filePath, err)
+	}
+	file, err := seclang.Parse(filePath, contents)
+	if err != nil {
+		return nil, nil, err
 	}
-	updatedLine := found[0][1] + newRegex + found[0][3]
-	lines[index] = []byte(updatedLine)
+	rule, err := file.FindRule(ruleId, chainOffset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find rule %s, chain offset %d: %w", ruleId, chainOffset, err)
+	}
+	return file, rule, nil
+}
 
-	err = os.WriteFile(filePath, bytes.Join(lines, []byte("\n")), fs.ModePerm)
+func writeOperatorArgument(file *seclang.File, rule *seclang.Rule, argument string, files *changeSet) error {
+	contents, err := file.ReplaceOperatorArgument(rule, argument)
 	if err != nil {
-		return fmt.Errorf("failed to write rule file %s: %w", filePath, err)
+		return err
 	}
-	return nil
+	return files.write(file.Name, contents)
+}
+
+// writeDataFile writes `phrases` to the data file at `filePath`, one per line. Comments and empty
+// lines at the start of an existing data file are kept.
+func writeDataFile(filePath string, phrases []string, files *changeSet) error {
+	var sb strings.Builder
+	contents, err := files.read(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read data file %s: %w", filePath, err)
+	}
+	for _, line := range strings.SplitAfter(string(contents), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		sb.WriteString(line)
+	}
+	if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteString("\n")
+	}
+	for _, phrase := range phrases {
+		sb.WriteString(phrase)
+		sb.WriteString("\n")
+	}
+	return files.write(filePath, []byte(sb.String()))
 }
@@ -4,17 +4,21 @@
 package update
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
 	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
 type updateTestSuite struct {
@@ -28,6 +32,8 @@ type updateTestSuite struct {
 
 func (s *updateTestSuite) SetupTest() {
 	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
 	s.dataDir = path.Join(s.rootDir, "regex-assembly")
 	err := os.MkdirAll(s.dataDir, fs.ModePerm)
 	s.Require().NoError(err)
@@ -290,6 +296,24 @@ SecRule ARGS '@rx regex3" \
 	s.Equal(expected, actual)
 }
 
+func (s *updateTestSuite) TestUpdate_UpdatesAllWithJobs() {
+	ruleFile := ""
+	expected := ""
+	for i := range 20 {
+		id := fmt.Sprintf("1234%02d", i)
+		s.writeDataFile(id+".ra", "", "regex"+id)
+		ruleFile += fmt.Sprintf("SecRule ARGS \"@rx old\" \\\n\t\"id:%s\"\n", id)
+		expected += fmt.Sprintf("SecRule ARGS \"@rx regex%s\" \\\n\t\"id:%s\"\n", id, id)
+	}
+	s.writeRuleFile("123400", ruleFile)
+	s.cmd.SetArgs([]string{"--all", "--jobs", "4"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	actual := s.readRuleFile("123400")
+	s.Equal(expected, actual)
+}
+
 func (s *updateTestSuite) TestUpdate_UpdateAllSkippingSubDirectories() {
 	s.writeDataFile("123456.ra", "", "homer")
 	s.writeDataFile("123457.ra", "include", "simpson")
@@ -345,6 +369,235 @@ func (s *updateTestSuite) TestUpdate_UpdatesChainedRule() {
 	s.Equal(expected, actual)
 }
 
+func (s *updateTestSuite) TestUpdate_UpdatesRuleWithActionsOnSameLine() {
+	s.writeDataFile("123456-chain2.ra", "", "homer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" "id:123456,chain"
+	SecRule ARGS "@pm foo bar" "chain"
+		SecRule ARGS "@rx regex2" "t:none"
+SecRule ARGS "@rx regex3" "id:1234567"
+`)
+	s.cmd.SetArgs([]string{"123456-chain2"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := `SecRule ARGS "@rx regex1" "id:123456,chain"
+	SecRule ARGS "@pm foo bar" "chain"
+		SecRule ARGS "@rx homer" "t:none"
+SecRule ARGS "@rx regex3" "id:1234567"
+`
+	actual := s.readRuleFile("123456")
+	s.Equal(expected, actual)
+}
+
+func (s *updateTestSuite) TestUpdate_RefusesToUpdateOtherOperators() {
+	s.writeDataFile("123456-chain1.ra", "", "homer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" "id:123456,chain"
+	SecRule ARGS "@pm foo bar"
+`)
+	s.cmd.SetArgs([]string{"123456-chain1"})
+	_, err := s.cmd.ExecuteC()
+	s.ErrorContains(err, "rule 123456, chain offset 1, in")
+	s.ErrorContains(err, "doesn't use @rx")
+}
+
+func (s *updateTestSuite) TestUpdate_UpdatesRuleThatPassesTestVectors() {
+	s.writeDataFile("123456.ra", "", "##!+ i\n##!? match HOMER\n##!? nomatch marge\nhomer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" \
+	"id:123456"`)
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := `SecRule ARGS "@rx (?i)homer" \
+	"id:123456"`
+	s.Equal(expected, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_RefusesToBreakTestVectors() {
+	s.writeDataFile("123456.ra", "", "##!? match homer\n##!? nomatch bart\nbart")
+	contents := `SecRule ARGS "@rx regex1" \
+	"id:123456"`
+	s.writeRuleFile("123456", contents)
+	s.cmd.SetArgs([]string{"--all"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().Error(err)
+
+	var vectorError *regexInternal.TestVectorError
+	s.Require().ErrorAs(err, &vectorError)
+	s.Len(vectorError.Failed, 2)
+	s.Contains(err.Error(), "refusing to update rule 123456: regular expression fails 2 test vectors")
+	s.Contains(err.Error(), `123456.ra:1: match "homer"`)
+	s.Contains(err.Error(), `123456.ra:2: nomatch "bart"`)
+	s.Equal(contents, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_UpdatesPmPhrases() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget\ncurl\nWGET\nsay\"hi")
+	s.writeRuleFile("123456", `SecRule ARGS "@pm foo bar" \
+	"id:123456"`)
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := `SecRule ARGS "@pm wget curl say\"hi" \
+	"id:123456"`
+	s.Equal(expected, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_RefusesPmPhrasesWithWhitespace() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget\ncmd\\ /c")
+	s.writeRuleFile("123456", `SecRule ARGS "@pm foo bar" "id:123456"`)
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.EqualError(err, "phrase 'cmd /c' contains whitespace, which @pm doesn't support, use @pmFromFile instead")
+}
+
+func (s *updateTestSuite) TestUpdate_UpdatesPmFromFileDataFile() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget\ncmd\\ /c")
+	contents := `SecRule ARGS "@pmFromFile commands.data" "id:123456"`
+	s.writeRuleFile("123456", contents)
+	dataFilePath := path.Join(s.rulesDir, "commands.data")
+	err := os.WriteFile(dataFilePath, []byte("# Generated from 123456.ra\n\nfoo\nbar\n"), fs.ModePerm)
+	s.Require().NoError(err)
+	s.cmd.SetArgs([]string{"123456"})
+	_, err = s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	actual, err := os.ReadFile(dataFilePath)
+	s.Require().NoError(err)
+	s.Equal("# Generated from 123456.ra\n\nwget\ncmd /c\n", string(actual))
+	s.Equal(contents, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_CreatesPmFromFileDataFile() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget")
+	s.writeRuleFile("123456", `SecRule ARGS "@pmf commands.data" "id:123456"`)
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	actual, err := os.ReadFile(path.Join(s.rulesDir, "commands.data"))
+	s.Require().NoError(err)
+	s.Equal("wget\n", string(actual))
+}
+
+func (s *updateTestSuite) TestUpdate_RefusesToUpdateRxWithPhrases() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx foo" "id:123456"`)
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.ErrorContains(err, "doesn't use @pm or @pmFromFile")
+}
+
+func (s *updateTestSuite) TestUpdate_DryRunListsChangedFiles() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.writeDataFile("123457.ra", "", "bart")
+	contents := `SecRule ARGS "@rx regex1" "id:123456"
+SecRule ARGS "@rx bart" "id:123457"
+`
+	s.writeRuleFile("123456", contents)
+	var stdout strings.Builder
+	s.cmd.SetOut(&stdout)
+	s.cmd.SetArgs([]string{"--all", "--dry-run"})
+	_, err := s.cmd.ExecuteC()
+
+	var pendingError *PendingChangesError
+	s.Require().ErrorAs(err, &pendingError)
+	s.Equal([]string{"rules/prefix-123-suffix.conf"}, pendingError.Files)
+	s.Equal("rules/prefix-123-suffix.conf\n", stdout.String())
+	s.Equal(contents, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_DryRunPrintsDiff() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.writeDataFile("123457.ra", "", "bart")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" "id:123456"
+SecRule ARGS "@rx regex2" "id:123457"
+`)
+	var stdout strings.Builder
+	s.cmd.SetOut(&stdout)
+	s.cmd.SetArgs([]string{"--all", "--dry-run", "--diff"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().Error(err)
+
+	expected := `--- a/rules/prefix-123-suffix.conf
++++ b/rules/prefix-123-suffix.conf
+@@ -1,2 +1,2 @@
+-SecRule ARGS "@rx regex1" "id:123456"
+-SecRule ARGS "@rx regex2" "id:123457"
++SecRule ARGS "@rx homer" "id:123456"
++SecRule ARGS "@rx bart" "id:123457"
+`
+	s.Equal(expected, stdout.String())
+}
+
+func (s *updateTestSuite) TestUpdate_DryRunPrintsJson() {
+	s.writeDataFile("123456.ra", "", "##!> output phrases\nwget")
+	s.writeRuleFile("123456", `SecRule ARGS "@pmFromFile commands.data" "id:123456"`)
+	var stdout strings.Builder
+	s.cmd.SetOut(&stdout)
+	s.cmd.SetArgs([]string{"123456", "--dry-run", "--json"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().Error(err)
+
+	expected := `[
+  {
+    "file": "rules/commands.data",
+    "diff": "--- /dev/null\n+++ b/rules/commands.data\n@@ -0,0 +1 @@\n+wget\n"
+  }
+]
+`
+	s.Equal(expected, stdout.String())
+	s.NoFileExists(path.Join(s.rulesDir, "commands.data"))
+}
+
+func (s *updateTestSuite) TestUpdate_DryRunSucceedsWithoutChanges() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx homer" "id:123456"`)
+	var stdout strings.Builder
+	s.cmd.SetOut(&stdout)
+	s.cmd.SetArgs([]string{"--all", "--dry-run", "--diff"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+	s.Empty(stdout.String())
+}
+
+func (s *updateTestSuite) TestUpdate_DiffRequiresDryRun() {
+	s.cmd.SetArgs([]string{"--all", "--diff"})
+	_, err := s.cmd.ExecuteC()
+	s.EqualError(err, "--diff and --json require --dry-run")
+}
+
+func (s *updateTestSuite) TestUpdate_DoesNotRewriteUnchangedFiles() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx homer" "id:123456"`)
+	filePath := path.Join(s.rulesDir, "prefix-123-suffix.conf")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	s.Require().NoError(os.Chtimes(filePath, modTime, modTime))
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	info, err := os.Stat(filePath)
+	s.Require().NoError(err)
+	s.Equal(modTime, info.ModTime())
+}
+
+func (s *updateTestSuite) TestUpdate_KeepsFilePermissions() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" "id:123456"`)
+	filePath := path.Join(s.rulesDir, "prefix-123-suffix.conf")
+	s.Require().NoError(os.Chmod(filePath, 0640))
+	s.cmd.SetArgs([]string{"123456"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	info, err := os.Stat(filePath)
+	s.Require().NoError(err)
+	s.Equal(fs.FileMode(0640), info.Mode().Perm())
+	s.Equal(`SecRule ARGS "@rx homer" "id:123456"`, s.readRuleFile("123456"))
+}
+
 func (s *updateTestSuite) writeDataFile(filename string, directory string, contents string) {
 	parentDirectory := s.dataDir
 	if directory != "" {
@@ -373,3 +626,117 @@ func (s *updateTestSuite) readRuleFile(ruleId string) string {
 
 	return string(contents)
 }
+
+func (s *updateTestSuite) TestUpdate_WatchUpdatesAffectedRules() {
+	s.writeDataFile("words.ra", "include", "homer")
+	s.writeDataFile("123456.ra", "", "##!> include words\n")
+	s.writeDataFile("123457.ra", "", "simpson")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" \
+	"id:123456"
+SecRule ARGS "@rx regex2" \
+	"id:123457"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	s.cmd.SetArgs([]string{"--all", "--watch"})
+	go func() {
+		_, err := s.cmd.ExecuteContextC(ctx)
+		done <- err
+	}()
+	// The watcher exists once the initial update has been written
+	s.Eventually(func() bool {
+		return strings.Contains(s.readRuleFile("123456"), "@rx homer")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Only the rule including the changed file is updated
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" \
+	"id:123456"
+SecRule ARGS "@rx regex2" \
+	"id:123457"`)
+	err := os.WriteFile(path.Join(s.dataDir, "include", "words.ra"), []byte("bart"), fs.ModePerm)
+	s.Require().NoError(err)
+	s.Eventually(func() bool {
+		return strings.Contains(s.readRuleFile("123456"), "@rx bart")
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Contains(s.readRuleFile("123456"), "@rx regex2")
+
+	cancel()
+	s.NoError(<-done)
+}
+
+func (s *updateTestSuite) TestUpdate_SinceUpdatesOnlyAffectedRules() {
+	s.writeDataFile("words.ra", "include", "homer")
+	s.writeDataFile("123456.ra", "", "##!> include words\n")
+	s.writeDataFile("123457.ra", "", "simpson")
+	s.writeDataFile("123458.ra", "", "marge")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx regex1" \
+	"id:123456"
+SecRule ARGS "@rx regex2" \
+	"id:123457"
+SecRule ARGS "@rx regex3" \
+	"id:123458"`)
+	s.git("init", "-b", "main")
+	s.git("add", ".")
+	s.git("commit", "-m", "initial")
+
+	// One committed change to an include and one uncommitted change to a regex-assembly file
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "include", "words.ra"), []byte("bart"), fs.ModePerm))
+	s.git("commit", "-am", "change include")
+	s.writeDataFile("123458.ra", "", "lisa")
+
+	s.cmd.SetArgs([]string{"--since", "HEAD~1"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := `SecRule ARGS "@rx bart" \
+	"id:123456"
+SecRule ARGS "@rx regex2" \
+	"id:123457"
+SecRule ARGS "@rx lisa" \
+	"id:123458"`
+	s.Equal(expected, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_SinceFollowsIncludeExceptInBlock() {
+	s.writeDataFile("words.ra", "include", "homer\nbart\n")
+	s.writeDataFile("except.ra", "exclude", "bart\n")
+	// Formatted files indent the directives inside processor blocks
+	s.writeDataFile("123456.ra", "", "##!> assemble\n  ##!> include-except words except\n##!<\n")
+	s.writeRuleFile("123456", `SecRule ARGS "@rx homer" \
+	"id:123456"`)
+	s.git("init", "-b", "main")
+	s.git("add", ".")
+	s.git("commit", "-m", "initial")
+
+	s.Require().NoError(os.WriteFile(path.Join(s.dataDir, "exclude", "except.ra"), []byte("homer\n"), fs.ModePerm))
+
+	s.cmd.SetArgs([]string{"--since", "main"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(err)
+
+	expected := `SecRule ARGS "@rx bart" \
+	"id:123456"`
+	s.Equal(expected, s.readRuleFile("123456"))
+}
+
+func (s *updateTestSuite) TestUpdate_SinceWithUnknownRevision() {
+	s.writeDataFile("123456.ra", "", "homer")
+	s.git("init", "-b", "main")
+	s.git("add", ".")
+	s.git("commit", "-m", "initial")
+
+	s.cmd.SetArgs([]string{"--since", "unknown"})
+	_, err := s.cmd.ExecuteC()
+	s.ErrorContains(err, "failed to resolve git revision unknown")
+}
+
+func (s *updateTestSuite) TestUpdate_SinceAndRuleIdReturnsError() {
+	s.cmd.SetArgs([]string{"123456", "--since", "main"})
+	_, err := s.cmd.ExecuteC()
+	s.Error(err)
+}
+
+func (s *updateTestSuite) git(args ...string) {
+	out, err := utils.RunGit(s.rootDir, append([]string{"-c", "user.name=dummy", "-c", "user.email=dummy@dummy.com"}, args...)...)
+	s.Require().NoError(err, string(out))
+}
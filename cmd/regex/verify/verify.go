@@ -0,0 +1,268 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/regression"
+	"github.com/coreruleset/crs-toolchain/v2/transformations"
+)
+
+var logger = log.With().Str("component", "cmd.regex.verify").Logger()
+
+// rule identifies the regex-assembly file of a rule
+type rule struct {
+	id          string
+	chainOffset uint8
+	fileName    string
+}
+
+// verifyResult holds the outcome of checking a rule against its regression tests, or the error
+// that occurred while checking
+type verifyResult struct {
+	testFilePath string
+	checked      int
+	failures     []regression.Failure
+	err          error
+}
+
+func New(cmdContext *regexInternal.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [RULE_ID | --all]",
+		Short: "Check generated regular expressions against the payloads of regression tests",
+		Long: `Check generated regular expressions against the payloads of regression tests.
+
+The regular expression of a rule is generated from its regex-assembly file and
+checked against the go-ftw regression tests of the rule, without sending any
+requests. Every test that expects the rule to match must contain a payload that
+the regular expression matches. Tests that expect the rule not to match must not
+contain such a payload.
+
+This is an approximation of running the regression tests against a WAF. The
+candidate payloads of a test are the URI, the names and values of arguments,
+headers and cookies, and the body. Every payload is checked as it is and after
+applying the transformations given with --transformations, in order.
+
+Stages of chained rules are only checked if they expect the rule to match, as a
+rule of a chain may match although the chain doesn't.
+
+RULE_ID is the ID of the rule, e.g., 932100, or the regex-assembly file name.
+If the rule is a chained rule, RULE_ID must be specified with the
+offset of the chain from the chain starter rule. For example, for
+a second level chained rule, RULE_ID would be 932100-chain2.
+
+With --all, all rules with regex-assembly files are checked. Rules without
+regression tests are skipped.
+
+The command fails if any test fails.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			allFlag := cmd.Flags().Lookup("all")
+			if !allFlag.Changed && len(args) == 0 {
+				return errors.New("expected either RULE_ID or --all flag, found neither")
+			} else if allFlag.Changed && len(args) > 0 {
+				return errors.New("expected either RULE_ID or --all flag, found both")
+			} else if len(args) > 1 {
+				return errors.New("expected a single RULE_ID")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil
+			}
+			err := regexInternal.ParseRuleId(args[0], cmdContext)
+			if err != nil {
+				cmd.PrintErrf("failed to parse the rule ID from the input '%s'\n", args[0])
+				return err
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext := cmdContext.RootContext()
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read jobs flag")
+				return err
+			}
+			names, err := cmd.Flags().GetStringSlice("transformations")
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to read transformations flag")
+				return err
+			}
+			chain, err := transformations.NewChain(names)
+			if err != nil {
+				return err
+			}
+
+			rules := []rule{}
+			if len(args) > 0 {
+				rules = append(rules, rule{id: cmdContext.Id, chainOffset: cmdContext.ChainOffset, fileName: cmdContext.FileName})
+			} else {
+				rules, err = findRules(rootContext.AssemblyDir())
+				if err != nil {
+					return err
+				}
+			}
+
+			// Problems are not command related from here on
+			cmd.SilenceUsage = true
+			report := internal.NewReport(cmdContext.OuterContext.Output)
+			err = performVerify(os.Stdout, report, rules, chain, jobs, rootContext, cmdContext)
+			return report.Finish(os.Stdout, err)
+		},
+	}
+
+	buildFlags(cmd)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("all", "a", false, "Instead of supplying a RULE_ID, check all rules with regex-assembly files")
+	cmd.Flags().IntP("jobs", "j", 0, `Number of regex-assembly files to process in parallel with --all.
+Defaults to the number of CPUs. Results are always reported in the same order`)
+	cmd.Flags().StringSliceP("transformations", "t", regression.DefaultTransformations,
+		fmt.Sprintf(`Transformations applied to payloads, in order. Supported transformations: %v.
+Pass an empty value to check payloads only as they are`, transformations.Names()))
+}
+
+// findRules returns the rules of the regex-assembly files in `directory`. Files in
+// subdirectories, e.g., include files, don't belong to rules.
+func findRules(directory string) ([]rule, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read regex-assembly directory: %w", err)
+	}
+	rules := []rule{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".ra" {
+			continue
+		}
+		subs := regex.RuleIdFileNameRegex.FindStringSubmatch(entry.Name())
+		if subs == nil {
+			continue
+		}
+		chainOffset, err := strconv.ParseUint("0"+subs[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("failed to match chain offset of %s. Value must not be larger than 255", entry.Name())
+		}
+		rules = append(rules, rule{id: subs[1], chainOffset: uint8(chainOffset), fileName: entry.Name()})
+	}
+	return rules, nil
+}
+
+// performVerify checks the generated regular expressions of `rules` against their regression
+// tests and writes the failures to `writer`, or adds them to `report` if its output is
+// structured. Rules without regression tests are not assembled.
+func performVerify(writer io.Writer, report *internal.Report, rules []rule, chain *transformations.Chain, jobs int, rootContext *context.Context, cmdContext *regexInternal.CommandContext) error {
+	total, failed := 0, 0
+	err := regexInternal.RunJobs(rules, jobs, func(r rule) verifyResult {
+		return verifyRule(r, chain, rootContext, cmdContext)
+	}, func(r rule, result verifyResult) error {
+		if result.err != nil {
+			return result.err
+		}
+		if result.testFilePath == "" {
+			logger.Debug().Msgf("No regression tests for rule %s", r.id)
+			return nil
+		}
+		total += result.checked
+		name := strings.TrimSuffix(r.fileName, path.Ext(r.fileName))
+		failedTests := map[*regression.Test]bool{}
+		for _, failure := range result.failures {
+			failedTests[failure.Test] = true
+			if err := writeFailure(writer, report, name, result.testFilePath, failure, rootContext, cmdContext); err != nil {
+				return err
+			}
+		}
+		if len(result.failures) == 0 {
+			report.Pass("regex-verify", name, internal.RelativePath(rootContext.RootDir(), result.testFilePath))
+		}
+		failed += len(failedTests)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d regression tests failed", failed, total)
+	}
+	logger.Info().Msgf("All %d regression tests passed", total)
+	return nil
+}
+
+// verifyRule assembles the regex-assembly file of `r` and checks the result against the
+// regression tests of the rule.
+func verifyRule(r rule, chain *transformations.Chain, rootContext *context.Context, cmdContext *regexInternal.CommandContext) verifyResult {
+	testFilePath, err := regression.FindTestFile(rootContext.RegressionTestsDir(), r.id)
+	if err != nil || testFilePath == "" {
+		return verifyResult{err: err}
+	}
+	testFile, err := regression.Load(testFilePath)
+	if err != nil {
+		return verifyResult{err: err}
+	}
+	ruleId, err := strconv.Atoi(r.id)
+	if err != nil {
+		return verifyResult{err: fmt.Errorf("invalid rule ID %s: %w", r.id, err)}
+	}
+
+	logger.Debug().Msgf("Checking rule %s, chain offset %d, against %s", r.id, r.chainOffset, testFilePath)
+	assembly, err := regexInternal.Assemble(filepath.Join(rootContext.AssemblyDir(), r.fileName), rootContext, cmdContext)
+	if err != nil {
+		return verifyResult{err: err}
+	}
+	expression, err := regexp.Compile(assembly)
+	if err != nil {
+		return verifyResult{err: fmt.Errorf("%s: failed to compile generated regular expression: %w", r.fileName, err)}
+	}
+
+	checker := &regression.Checker{
+		Transformations: chain,
+		MatchOnly:       r.chainOffset > 0,
+	}
+	checked, failures, err := checker.Check(testFile, ruleId, expression)
+	if err != nil {
+		return verifyResult{err: fmt.Errorf("%s: %w", testFilePath, err)}
+	}
+	return verifyResult{testFilePath: testFilePath, checked: checked, failures: failures}
+}
+
+func writeFailure(writer io.Writer, report *internal.Report, name string, testFilePath string, failure regression.Failure, rootContext *context.Context, cmdContext *regexInternal.CommandContext) error {
+	fileName := internal.RelativePath(rootContext.RootDir(), testFilePath)
+	report.Add(internal.Diagnostic{
+		Check:   "regex-verify",
+		Level:   internal.LevelError,
+		Subject: name,
+		File:    fileName,
+		Line:    failure.Test.Line,
+		Message: failure.String(),
+	})
+	if report.Structured() {
+		return nil
+	}
+	var err error
+	if cmdContext.OuterContext.Output == internal.GitHub {
+		_, err = fmt.Fprintf(writer, "::error file=%s,line=%d,title=regression test::%s\n", fileName, failure.Test.Line, failure)
+	} else {
+		_, err = fmt.Fprintf(writer, "%s:%d: %s\n", fileName, failure.Test.Line, failure)
+	}
+	return err
+}
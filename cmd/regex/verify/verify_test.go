@@ -0,0 +1,184 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package verify
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	regexInternal "github.com/coreruleset/crs-toolchain/v2/cmd/regex/internal"
+)
+
+type verifyTestSuite struct {
+	suite.Suite
+	rootDir    string
+	dataDir    string
+	testsDir   string
+	cmdContext *regexInternal.CommandContext
+	cmd        *cobra.Command
+}
+
+const regressionTests = `---
+rule_id: 123456
+tests:
+  - test_id: 1
+    stages:
+      - input:
+          uri: "/?q=a%20cat"
+        output:
+          log:
+            expect_ids: [123456]
+  - test_id: 2
+    stages:
+      - input:
+          headers:
+            User-Agent: Mouse
+        output:
+          log:
+            expect_ids: [123456]
+  - test_id: 3
+    stages:
+      - input:
+          data: "animal=dog"
+        output:
+          log:
+            no_expect_ids: [123456]
+`
+
+func (s *verifyTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	// Keep the cache of generated expressions out of the user's home directory
+	s.T().Setenv("HOME", s.T().TempDir())
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	err := os.MkdirAll(path.Join(s.dataDir, "include"), fs.ModePerm)
+	s.Require().NoError(err)
+	s.testsDir = path.Join(s.rootDir, "tests", "regression", "tests", "REQUEST-123-TEST")
+	err = os.MkdirAll(s.testsDir, fs.ModePerm)
+	s.Require().NoError(err)
+	rootCtx := internal.NewCommandContext(s.rootDir)
+	s.cmdContext = regexInternal.NewCommandContext(rootCtx, &logger)
+	s.cmd = New(s.cmdContext)
+
+	s.writeFile(s.dataDir, "123456.ra", "cat\nmouse\n")
+	s.writeFile(s.dataDir, "123457.ra", "foo\n")
+	s.writeFile(path.Join(s.dataDir, "include"), "123458.ra", "bar\n")
+	s.writeFile(s.testsDir, "123456.yaml", regressionTests)
+}
+
+func TestRunVerifyTestSuite(t *testing.T) {
+	suite.Run(t, new(verifyTestSuite))
+}
+
+func (s *verifyTestSuite) TestVerify_NoRuleId() {
+	s.cmd.SetArgs([]string{})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *verifyTestSuite) TestVerify_RuleIdAndAll() {
+	s.cmd.SetArgs([]string{"123456", "--all"})
+	_, err := s.cmd.ExecuteC()
+
+	s.Error(err)
+}
+
+func (s *verifyTestSuite) TestVerify_Passes() {
+	output, err := s.run("123456")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *verifyTestSuite) TestVerify_NoRegressionTests() {
+	output, err := s.run("123457")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *verifyTestSuite) TestVerify_Fails() {
+	s.writeFile(s.dataDir, "123456.ra", "cat\ndog\n")
+	output, err := s.run("123456")
+	s.EqualError(err, "2 of 3 regression tests failed")
+	s.Equal(`tests/regression/tests/REQUEST-123-TEST/123456.yaml:11: test 123456-2: expected rule 123456 to match, but no payload matches
+tests/regression/tests/REQUEST-123-TEST/123456.yaml:19: test 123456-3: expected rule 123456 not to match, but "animal=dog" matches
+`, output)
+}
+
+func (s *verifyTestSuite) TestVerify_Transformations() {
+	output, err := s.run("123456", "--transformations", "")
+	s.EqualError(err, "1 of 3 regression tests failed")
+	s.Contains(output, "test 123456-2")
+
+	s.cmd = New(s.cmdContext)
+	_, err = s.run("123456", "--transformations", "rot13")
+	s.ErrorContains(err, "unknown transformation 'rot13'")
+}
+
+func (s *verifyTestSuite) TestVerify_ChainedRuleOnlyChecksMatches() {
+	s.writeFile(s.dataDir, "123456-chain1.ra", "cat\nmouse\ndog\n")
+	output, err := s.run("123456-chain1")
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *verifyTestSuite) TestVerify_All() {
+	s.writeFile(s.dataDir, "123456-chain1.ra", "cat\n")
+	output, err := s.run("--all")
+	s.EqualError(err, "1 of 5 regression tests failed")
+	s.Equal("tests/regression/tests/REQUEST-123-TEST/123456.yaml:11: test 123456-2: expected rule 123456 to match, but no payload matches\n", output)
+}
+
+func (s *verifyTestSuite) TestVerify_GitHub() {
+	s.cmdContext.OuterContext.Output = internal.GitHub
+	s.writeFile(s.dataDir, "123456.ra", "cat\n")
+	output, err := s.run("123456")
+	s.Error(err)
+	s.Equal("::error file=tests/regression/tests/REQUEST-123-TEST/123456.yaml,line=11,title=regression test::test 123456-2: expected rule 123456 to match, but no payload matches\n", output)
+}
+
+func (s *verifyTestSuite) TestVerify_Sarif() {
+	s.cmdContext.OuterContext.Output = internal.Sarif
+	s.writeFile(s.dataDir, "123456.ra", "cat\n")
+	output, err := s.run("123456")
+	s.Error(err)
+	s.Contains(output, `"ruleId": "regex-verify"`)
+	s.Contains(output, `"uri": "tests/regression/tests/REQUEST-123-TEST/123456.yaml"`)
+	s.Contains(output, `"startLine": 11`)
+	s.NotContains(output, "::error")
+}
+
+func (s *verifyTestSuite) run(args ...string) (string, error) {
+	read := s.captureStdout()
+	s.cmd.SetArgs(args)
+	_, cmdErr := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	return string(output), cmdErr
+}
+
+func (s *verifyTestSuite) captureStdout() *os.File {
+	read, write, err := os.Pipe()
+	s.Require().NoError(err)
+
+	realStdout := os.Stdout
+	os.Stdout = write
+	s.T().Cleanup(func() {
+		os.Stdout = realStdout
+	})
+	return read
+}
+
+func (s *verifyTestSuite) writeFile(directory string, filename string, contents string) {
+	err := os.WriteFile(path.Join(directory, filename), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
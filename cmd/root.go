@@ -14,7 +14,9 @@ import (
 	"github.com/coreruleset/crs-toolchain/v2/cmd/completion"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/generate"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/lsp"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/regex"
+	"github.com/coreruleset/crs-toolchain/v2/cmd/serve"
 	"github.com/coreruleset/crs-toolchain/v2/cmd/util"
 )
 
@@ -55,7 +57,9 @@ func New() *cobra.Command {
 		chore.New(cmdContext),
 		completion.New(),
 		generate.New(cmdContext),
+		lsp.New(cmdContext),
 		regex.New(cmdContext),
+		serve.New(cmdContext),
 		util.New(cmdContext),
 	)
 	return rootCmd
@@ -70,7 +74,7 @@ func buildFlags(rootCmd *cobra.Command, cmdContext *internal.CommandContext) {
 	rootCmd.PersistentFlags().VarP(logLevelFlag, "log-level", "l",
 		`Set the application log level
 Options: 'trace', 'debug', 'info', 'warn', 'error', 'fatal', 'panic', 'disabled'`)
-	rootCmd.PersistentFlags().VarP(outputTypeFlag, "output", "o", "Output format. One of 'text', 'github'.")
+	rootCmd.PersistentFlags().VarP(outputTypeFlag, "output", "o", "Output format. One of 'text', 'github', 'json', 'sarif', 'junit'.")
 	rootCmd.PersistentFlags().VarP(workingDirectoryFlag, "directory", "d",
 		`Absolute or relative path to the CRS directory.
 If not specified, the command is assumed to run inside the CRS directory`)
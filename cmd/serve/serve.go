@@ -0,0 +1,95 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+)
+
+var logger = log.With().Str("component", "cmd.serve").Logger()
+
+const shutdownTimeout = 10 * time.Second
+
+func New(cmdContext *internal.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve regex assembly over HTTP",
+		Long: `Serve regex assembly over HTTP.
+
+POST /v1/assemble accepts a JSON object with the regex-assembly source and returns
+the assembled regular expression, or diagnostics if the source could not be assembled:
+
+  {
+    "source": "##!> include shell-commands\nfoo\n",
+    "fileName": "932100.ra",
+    "includes": {"shell-commands": "bar\nbaz\n"},
+    "excludes": {},
+    "sourceMap": true,
+    "stats": true
+  }
+
+Only "source" is required. If the request contains include or exclude files, only
+these files can be included. Otherwise, includes are resolved in the CRS directory
+of the server (see --directory). Including files by absolute path, or outside of the
+include and exclude directories, is not allowed.
+
+GET /healthz reports whether the server is up.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listen, err := cmd.Flags().GetString("listen")
+			if err != nil {
+				return err
+			}
+			// Errors are not command related
+			cmd.SilenceUsage = true
+			return serve(cmd.Context(), listen, NewServer(cmdContext.RootContext()))
+		},
+	}
+
+	buildFlags(cmd)
+	return cmd
+}
+
+func buildFlags(cmd *cobra.Command) {
+	cmd.Flags().String("listen", ":8080", "Address to listen on, e.g., ':8080' or '127.0.0.1:8080'")
+}
+
+// serve runs the HTTP server until it fails or the process receives SIGINT or SIGTERM.
+// Requests in flight are completed before the server shuts down.
+func serve(ctx context.Context, listen string, server *Server) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+	}
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		logger.Info().Msg("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdownErr <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info().Msgf("Listening on %s", listen)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdownErr
+}
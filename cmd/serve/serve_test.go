@@ -0,0 +1,47 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	crsContext "github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+type serveTestSuite struct {
+	suite.Suite
+	server *Server
+}
+
+func (s *serveTestSuite) SetupTest() {
+	s.server = NewServer(crsContext.New(s.T().TempDir(), "toolchain.yaml"))
+}
+
+func TestRunServeTestSuite(t *testing.T) {
+	suite.Run(t, new(serveTestSuite))
+}
+
+func (s *serveTestSuite) TestListenFlagDefault() {
+	cmd := New(internal.NewCommandContext(s.T().TempDir()))
+	listen, err := cmd.Flags().GetString("listen")
+	s.Require().NoError(err)
+	s.Equal(":8080", listen)
+}
+
+func (s *serveTestSuite) TestServe_StopsWhenContextIsDone() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := serve(ctx, "127.0.0.1:0", s.server)
+	s.NoError(err)
+}
+
+func (s *serveTestSuite) TestServe_InvalidAddress() {
+	err := serve(context.Background(), "127.0.0.1:-1", s.server)
+	s.Error(err)
+}
@@ -0,0 +1,315 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+	"github.com/coreruleset/crs-toolchain/v2/regex/operators"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/regex/validation"
+)
+
+const (
+	// maxRequestSize limits the size of request bodies, including all include files
+	maxRequestSize = 1 << 20
+	// defaultFileName is the name of the regex-assembly source if the request doesn't name it
+	defaultFileName = "input.ra"
+	assembleCheck   = "regex-assemble"
+)
+
+// assembleRequest is the body of a request to the assemble endpoint. `Includes` and `Excludes`
+// map file names (e.g., "shell-commands" or "shell-commands.ra") to file contents. If the
+// request contains any of them, only these files can be included. Otherwise, includes are
+// resolved in the CRS directory of the server.
+type assembleRequest struct {
+	Source    string            `json:"source"`
+	FileName  string            `json:"fileName,omitempty"`
+	Includes  map[string]string `json:"includes,omitempty"`
+	Excludes  map[string]string `json:"excludes,omitempty"`
+	SourceMap bool              `json:"sourceMap,omitempty"`
+	Stats     bool              `json:"stats,omitempty"`
+}
+
+// assembleResponse is the body of the response of the assemble endpoint. `Regex` is empty if
+// the source could not be assembled, `Diagnostics` then explains why.
+type assembleResponse struct {
+	Regex       string                `json:"regex,omitempty"`
+	Diagnostics []internal.Diagnostic `json:"diagnostics"`
+	SourceMap   *operators.SourceMap  `json:"sourceMap,omitempty"`
+	Stats       *assembleStats        `json:"stats,omitempty"`
+}
+
+// assembleStats describes the source and the generated expression.
+type assembleStats struct {
+	// Length is the length of the generated expression in bytes
+	Length int `json:"length"`
+	// SourceLines is the number of lines of the source that aren't empty, comments or test vectors
+	SourceLines int `json:"sourceLines"`
+	// Includes is the number of files included by the source, not counting nested includes
+	Includes int `json:"includes"`
+	// DurationMs is the time it took to assemble the expression in milliseconds
+	DurationMs float64 `json:"durationMs"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves regex assembly over HTTP. Every request is processed with a new processor
+// context, so requests don't share state and can be served concurrently.
+type Server struct {
+	rootContext *context.Context
+}
+
+func NewServer(rootContext *context.Context) *Server {
+	return &Server{rootContext: rootContext}
+}
+
+// Handler returns the handler for all endpoints of the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("POST /v1/assemble", s.handleAssemble)
+	return mux
+}
+
+func (s *Server) handleHealth(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = writer.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleAssemble(writer http.ResponseWriter, request *http.Request) {
+	assembleRequest := assembleRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&assembleRequest); err != nil {
+		status := http.StatusBadRequest
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJson(writer, status, errorResponse{Error: fmt.Sprintf("invalid request: %s", err)})
+		return
+	}
+	if assembleRequest.FileName == "" {
+		assembleRequest.FileName = defaultFileName
+	}
+
+	response, err := s.assemble(&assembleRequest)
+	if err != nil {
+		writeJson(writer, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if len(response.Diagnostics) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJson(writer, status, response)
+}
+
+// assemble generates the regular expression for the request. Problems with the source are
+// reported as diagnostics of the response, errors are returned for invalid requests only.
+func (s *Server) assemble(request *assembleRequest) (*assembleResponse, error) {
+	if !validFileName(request.FileName) {
+		return nil, fmt.Errorf("invalid file name %q", request.FileName)
+	}
+
+	rootContext := s.rootContext
+	if len(request.Includes) > 0 || len(request.Excludes) > 0 {
+		tempDir, err := os.MkdirTemp("", "crs-toolchain-serve-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory for include files: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		rootContext = context.NewWithConfiguration(tempDir, s.rootContext.Configuration())
+		if err := writeFiles(rootContext.IncludesDir(), request.Includes); err != nil {
+			return nil, err
+		}
+		if err := writeFiles(rootContext.ExcludesDir(), request.Excludes); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	// Don't expose files of the server outside of the include and exclude directories
+	processorContext := processors.NewContext(rootContext)
+	processorContext.RestrictIncludes()
+	assembler := operators.NewAssemblerForFile(processorContext, request.FileName)
+	var assembly string
+	var sourceMap *operators.SourceMap
+	var err error
+	if request.SourceMap {
+		assembly, sourceMap, err = assembler.RunWithSourceMap(request.Source)
+	} else {
+		assembly, err = assembler.Run(request.Source)
+	}
+	duration := time.Since(start)
+
+	response := &assembleResponse{Diagnostics: []internal.Diagnostic{}}
+	if err != nil {
+		logger.Debug().Err(err).Msgf("Failed to assemble %s", request.FileName)
+		response.Diagnostics = diagnose(err, request, rootContext.RootDir())
+		return response, nil
+	}
+
+	response.Regex = assembly
+	if sourceMap != nil {
+		relativizeSourceMap(sourceMap, rootContext.RootDir())
+		response.SourceMap = sourceMap
+	}
+	if request.Stats {
+		response.Stats = &assembleStats{
+			Length:      len(assembly),
+			SourceLines: countSourceLines(request.Source),
+			Includes:    len(dependencies.IncludeNames([]byte(request.Source))),
+			DurationMs:  float64(duration.Microseconds()) / 1000,
+		}
+	}
+	return response, nil
+}
+
+// diagnose turns the error returned by the assembler into diagnostics. Errors of the validation
+// don't have a location, they are reported for the lines of the source that fail the validation
+// on their own, if any.
+func diagnose(err error, request *assembleRequest, rootDir string) []internal.Diagnostic {
+	var includeError *parser.IncludeError
+	if errors.As(err, &includeError) {
+		diagnostics := []internal.Diagnostic{}
+		for _, parseError := range includeError.Errors {
+			diagnostics = append(diagnostics, parseErrorDiagnostic(parseError, rootDir))
+		}
+		return diagnostics
+	}
+	var parseError *parser.ParseError
+	if errors.As(err, &parseError) {
+		return []internal.Diagnostic{parseErrorDiagnostic(parseError, rootDir)}
+	}
+
+	diagnostics := []internal.Diagnostic{}
+	for index, line := range strings.Split(request.Source, "\n") {
+		if regex.CommentRegex.MatchString(line) || regex.TestVectorRegex.MatchString(line) {
+			continue
+		}
+		if lineErr := validation.ValidateAll(strings.NewReader(line)); lineErr != nil {
+			diagnostics = append(diagnostics, newDiagnostic(request.FileName, index+1, 0, lineErr.Error(), rootDir))
+		}
+	}
+	if len(diagnostics) == 0 {
+		diagnostics = append(diagnostics, newDiagnostic(request.FileName, 0, 0, err.Error(), rootDir))
+	}
+	return diagnostics
+}
+
+// parseErrorDiagnostic creates a diagnostic for an error returned by the parser. Errors in
+// included files are reported at the outermost include directive.
+func parseErrorDiagnostic(parseError *parser.ParseError, rootDir string) internal.Diagnostic {
+	location := parseError.Location
+	if len(parseError.IncludeStack) > 0 {
+		location = parseError.IncludeStack[0]
+	}
+	return newDiagnostic(location.File, location.Line, location.Column, parseError.Error(), rootDir)
+}
+
+func newDiagnostic(file string, line int, column int, message string, rootDir string) internal.Diagnostic {
+	return internal.Diagnostic{
+		Check:   assembleCheck,
+		Level:   internal.LevelError,
+		File:    relativePath(rootDir, file),
+		Line:    line,
+		Column:  column,
+		Message: strings.ReplaceAll(message, rootDir+string(filepath.Separator), ""),
+	}
+}
+
+// relativizeSourceMap replaces the paths of included files in `sourceMap` with paths relative
+// to `rootDir`, so that responses don't expose the file system of the server.
+func relativizeSourceMap(sourceMap *operators.SourceMap, rootDir string) {
+	for i := range sourceMap.Mappings {
+		for j := range sourceMap.Mappings[i].Sources {
+			source := &sourceMap.Mappings[i].Sources[j]
+			source.File = relativePath(rootDir, source.File)
+		}
+	}
+	for i := range sourceMap.Unmapped {
+		sourceMap.Unmapped[i].File = relativePath(rootDir, sourceMap.Unmapped[i].File)
+	}
+}
+
+// relativePath returns `filePath` relative to `rootDir`, or `filePath` if it is not inside
+// `rootDir`.
+func relativePath(rootDir string, filePath string) string {
+	if !filepath.IsAbs(filePath) {
+		return filePath
+	}
+	relative, err := filepath.Rel(rootDir, filePath)
+	if err != nil || !filepath.IsLocal(relative) {
+		return filePath
+	}
+	return filepath.ToSlash(relative)
+}
+
+// writeFiles writes the files of the request to `directory`, adding the .ra extension where
+// it is missing.
+func writeFiles(directory string, files map[string]string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(directory, fs.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory for include files: %w", err)
+	}
+	for name, contents := range files {
+		if path.Ext(name) != ".ra" {
+			name += ".ra"
+		}
+		if !validFileName(name) {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+			return fmt.Errorf("failed to write include file %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validFileName returns true for plain file names without directories.
+func validFileName(name string) bool {
+	return filepath.IsLocal(name) && filepath.Base(name) == name
+}
+
+func countSourceLines(source string) int {
+	count := 0
+	for _, line := range strings.Split(source, "\n") {
+		if strings.TrimSpace(line) == "" || regex.CommentRegex.MatchString(line) || regex.TestVectorRegex.MatchString(line) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func writeJson(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(body); err != nil {
+		logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
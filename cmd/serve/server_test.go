@@ -0,0 +1,242 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/cmd/internal"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+type serverTestSuite struct {
+	suite.Suite
+	rootDir     string
+	includesDir string
+	server      *httptest.Server
+}
+
+func (s *serverTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.includesDir = path.Join(s.rootDir, "regex-assembly", "include")
+	s.Require().NoError(os.MkdirAll(s.includesDir, fs.ModePerm))
+
+	s.server = httptest.NewServer(NewServer(context.New(s.rootDir, "toolchain.yaml")).Handler())
+}
+
+func (s *serverTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestRunServerTestSuite(t *testing.T) {
+	suite.Run(t, new(serverTestSuite))
+}
+
+func (s *serverTestSuite) TestHealth() {
+	response, err := http.Get(s.server.URL + "/healthz")
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	body, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Equal("ok\n", string(body))
+}
+
+func (s *serverTestSuite) TestAssemble() {
+	status, response := s.assemble(`{"source": "homer\nmarge\n"}`)
+
+	s.Equal(http.StatusOK, status)
+	s.Equal("homer|marge", response.Regex)
+	s.Empty(response.Diagnostics)
+	s.Nil(response.SourceMap)
+	s.Nil(response.Stats)
+}
+
+func (s *serverTestSuite) TestAssemble_RequestIncludes() {
+	status, response := s.assemble(`{
+		"source": "##!> include simpsons\nbart\n",
+		"includes": {"simpsons": "homer\nmarge\n"}
+	}`)
+
+	s.Equal(http.StatusOK, status)
+	s.Equal("homer|marge|bart", response.Regex)
+}
+
+func (s *serverTestSuite) TestAssemble_RootIncludes() {
+	s.Require().NoError(os.WriteFile(path.Join(s.includesDir, "simpsons.ra"), []byte("homer\nmarge\n"), fs.ModePerm))
+
+	status, response := s.assemble(`{"source": "##!> include simpsons\nbart\n"}`)
+
+	s.Equal(http.StatusOK, status)
+	s.Equal("homer|marge|bart", response.Regex)
+}
+
+func (s *serverTestSuite) TestAssemble_RequestIncludesReplaceRoot() {
+	s.Require().NoError(os.WriteFile(path.Join(s.includesDir, "simpsons.ra"), []byte("homer\n"), fs.ModePerm))
+
+	status, response := s.assemble(`{
+		"source": "##!> include simpsons\n##!> include flanders\n",
+		"includes": {"flanders.ra": "ned\n"}
+	}`)
+
+	s.Equal(http.StatusUnprocessableEntity, status)
+	s.Empty(response.Regex)
+	s.Require().Len(response.Diagnostics, 1)
+	diagnostic := response.Diagnostics[0]
+	s.Equal("regex-assemble", diagnostic.Check)
+	s.Equal(internal.LevelError, diagnostic.Level)
+	s.Equal("input.ra", diagnostic.File)
+	s.Equal(1, diagnostic.Line)
+	s.Contains(diagnostic.Message, "failed to resolve include simpsons.ra")
+	s.NotContains(diagnostic.Message, os.TempDir())
+}
+
+func (s *serverTestSuite) TestAssemble_ValidationDiagnostics() {
+	status, response := s.assemble(`{"source": "homer\n[äb]\n", "fileName": "123456.ra"}`)
+
+	s.Equal(http.StatusUnprocessableEntity, status)
+	s.Require().Len(response.Diagnostics, 1)
+	s.Equal("123456.ra", response.Diagnostics[0].File)
+	s.Equal(2, response.Diagnostics[0].Line)
+}
+
+func (s *serverTestSuite) TestAssemble_SourceMapAndStats() {
+	status, response := s.assemble(`{
+		"source": "##! comment\n##!> include simpsons\nbart\n",
+		"includes": {"simpsons": "homer\n"},
+		"sourceMap": true,
+		"stats": true
+	}`)
+
+	s.Equal(http.StatusOK, status)
+	s.Equal("homer|bart", response.Regex)
+	s.Require().NotNil(response.SourceMap)
+	s.Equal("homer|bart", response.SourceMap.Regex)
+	files := []string{}
+	for _, mapping := range response.SourceMap.Mappings {
+		for _, source := range mapping.Sources {
+			files = append(files, source.File)
+		}
+	}
+	s.Contains(files, "regex-assembly/include/simpsons.ra")
+	s.Contains(files, "input.ra")
+
+	s.Require().NotNil(response.Stats)
+	s.Equal(len("homer|bart"), response.Stats.Length)
+	s.Equal(2, response.Stats.SourceLines)
+	s.Equal(1, response.Stats.Includes)
+}
+
+func (s *serverTestSuite) TestAssemble_AbsoluteIncludeNotAllowed() {
+	status, response := s.assemble(`{"source": "##!> include /etc/passwd\n"}`)
+
+	s.Equal(http.StatusUnprocessableEntity, status)
+	s.Empty(response.Regex)
+	s.Require().Len(response.Diagnostics, 1)
+	s.Contains(response.Diagnostics[0].Message, "is not allowed")
+}
+
+func (s *serverTestSuite) TestAssemble_IncludeOutsideOfRootNotAllowed() {
+	status, response := s.assemble(`{"source": "##!> include simpsons\n", "includes": {"simpsons": "##!> include ../../../secret\n"}}`)
+
+	s.Equal(http.StatusUnprocessableEntity, status)
+	s.Empty(response.Regex)
+	s.Require().Len(response.Diagnostics, 1)
+	s.Contains(response.Diagnostics[0].Message, "is not allowed")
+}
+
+func (s *serverTestSuite) TestAssemble_IndentedAbsoluteIncludeExceptNotAllowed() {
+	secretDir := s.T().TempDir()
+	secret := path.Join(secretDir, "secret.ra")
+	s.Require().NoError(os.WriteFile(secret, []byte("password\n"), fs.ModePerm))
+
+	status, response := s.assemble(`{
+		"source": "##!=< cmdline unix\n  ##!> include-except ` + secret + ` nothing\n##!=>\n",
+		"excludes": {"nothing": "homer\n"}
+	}`)
+
+	s.Equal(http.StatusUnprocessableEntity, status)
+	s.Empty(response.Regex)
+	s.Require().Len(response.Diagnostics, 1)
+	s.Contains(response.Diagnostics[0].Message, "is not allowed")
+	s.NotContains(response.Diagnostics[0].Message, "password")
+}
+
+func (s *serverTestSuite) TestAssemble_InvalidIncludeName() {
+	status, body := s.post(`{"source": "foo\n", "includes": {"../simpsons": "homer\n"}}`)
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Contains(body, "invalid file name")
+}
+
+func (s *serverTestSuite) TestAssemble_MalformedRequest() {
+	status, body := s.post(`{"source": 42}`)
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Contains(body, "invalid request")
+}
+
+func (s *serverTestSuite) TestAssemble_RequestTooLarge() {
+	status, _ := s.post(`{"source": "` + strings.Repeat("a", maxRequestSize) + `"}`)
+
+	s.Equal(http.StatusRequestEntityTooLarge, status)
+}
+
+func (s *serverTestSuite) TestAssemble_MethodNotAllowed() {
+	response, err := http.Get(s.server.URL + "/v1/assemble")
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func (s *serverTestSuite) TestAssemble_Concurrent() {
+	wg := sync.WaitGroup{}
+	for range 10 {
+		wg.Go(func() {
+			// Don't use `Require` outside of the test goroutine
+			response, err := http.Post(s.server.URL+"/v1/assemble", "application/json",
+				strings.NewReader(`{"source": "##!> include simpsons\n", "includes": {"simpsons": "homer\nmarge\n"}}`))
+			if !s.NoError(err) {
+				return
+			}
+			defer response.Body.Close()
+			body, err := io.ReadAll(response.Body)
+			s.NoError(err)
+			s.Equal(http.StatusOK, response.StatusCode)
+			s.Contains(string(body), `"regex": "homer|marge"`)
+		})
+	}
+	wg.Wait()
+}
+
+func (s *serverTestSuite) assemble(body string) (int, assembleResponse) {
+	status, responseBody := s.post(body)
+	response := assembleResponse{}
+	s.Require().NoError(json.Unmarshal([]byte(responseBody), &response), responseBody)
+	return status, response
+}
+
+func (s *serverTestSuite) post(body string) (int, string) {
+	response, err := http.Post(s.server.URL+"/v1/assemble", "application/json", bytes.NewReader([]byte(body)))
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	return response.StatusCode, string(responseBody)
+}
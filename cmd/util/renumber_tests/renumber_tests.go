@@ -6,8 +6,10 @@ package renumberTests
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
+	"slices"
 
 	"github.com/spf13/cobra"
 
@@ -21,7 +23,11 @@ func New(cmdContext *internal.CommandContext) *cobra.Command {
 		Use: "renumber-tests RULE_ID",
 		Short: `Renumber all CRS tests, so that they are sequential in every file.
 
-RULE_ID is the ID of the rule, e.g., 932100, or the test file name.`,
+RULE_ID is the ID of the rule, e.g., 932100, or the test file name.
+
+With --check and --output json or --output sarif, the test files that are
+not properly numbered are reported as a single JSON or SARIF document. With
+--output junit, each test file is a test case of a JUnit XML report.`,
 		Args: cobra.MatchAll(cobra.MaximumNArgs(1), func(cmd *cobra.Command, args []string) error {
 			allFlag := cmd.Flags().Lookup("all")
 			if !allFlag.Changed && len(args) == 0 {
@@ -49,17 +55,40 @@ RULE_ID is the ID of the rule, e.g., 932100, or the test file name.`,
 			if cmdContext.Output == internal.GitHub {
 				cmd.SilenceErrors = true
 			}
+			report := internal.NewReport(cmdContext.Output)
 			renumberer := util.NewTestRenumberer()
 			if processAll {
-				return renumberer.RenumberTests(checkOnly, cmdContext.Output == internal.GitHub, cmdContext.RootContext())
+				err = renumberer.RenumberTests(checkOnly, cmdContext.Output == internal.GitHub, cmdContext.RootContext())
+			} else {
+				filenameArg := args[0]
+				var filePath string
+				filePath, err = parseFilePath(filenameArg, cmdContext.RootContext())
+				if err != nil {
+					return err
+				}
+				err = renumberer.RenumberTest(filePath, checkOnly, cmdContext.RootContext())
 			}
-
-			filenameArg := args[0]
-			filePath, err := parseFilePath(filenameArg, cmdContext.RootContext())
-			if err != nil {
-				return err
+			misnumbered := renumberer.MisnumberedFiles()
+			for _, filePath := range renumberer.ProcessedFiles() {
+				fileName := internal.RelativePath(cmdContext.RootContext().RootDir(), filePath)
+				index := slices.IndexFunc(misnumbered, func(file util.MisnumberedFile) bool { return file.Path == filePath })
+				if index < 0 {
+					report.Pass("test-numbering", "", fileName)
+					continue
+				}
+				report.Add(internal.Diagnostic{
+					Check:   "test-numbering",
+					Level:   internal.LevelWarning,
+					File:    fileName,
+					Line:    misnumbered[index].Line,
+					Message: fmt.Sprintf("Test file not properly numbered: %s", path.Base(filePath)),
+				})
+			}
+			numberingError := &util.TestNumberingError{}
+			if processAll && errors.As(err, &numberingError) {
+				report.Summarize(os.Stdout, "All test files need to be properly numbered. Please run `crs-toolchain util renumber-tests --all`")
 			}
-			return renumberer.RenumberTest(filePath, checkOnly, cmdContext.RootContext())
+			return report.Finish(os.Stdout, err)
 		},
 	}
 
@@ -4,6 +4,7 @@
 package renumberTests
 
 import (
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -152,11 +153,52 @@ func (s *renumberTestsTestSuite) TestRenumberTests_GitHubOutput() {
 	s.Require().NoError(err)
 
 	output := string(buffer)
-	s.Contains(output, "::warning::Test file not properly numbered")
+	s.Contains(output, "::warning file=tests/regression/tests/_prefix_123_suffix_/123456.yaml,line=1::Test file not properly numbered: 123456.yaml")
 	s.Contains(output, "::error::")
 	s.Contains(output, "Please run `crs-toolchain util renumber-tests --all`")
 }
 
+func (s *renumberTestsTestSuite) TestRenumberTests_JsonOutput() {
+	read := s.captureStdout()
+
+	contents := "test_id: homer"
+	s.writeTestFile("123456.yaml", contents)
+	s.cmdContext.Output = internal.Json
+	s.cmd.SetArgs([]string{"-ca"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	s.ErrorIs(err, &util.TestNumberingError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.JSONEq(`[{
+	"check": "test-numbering",
+	"level": "warning",
+	"file": "tests/regression/tests/_prefix_123_suffix_/123456.yaml",
+	"line": 1,
+	"message": "Test file not properly numbered: 123456.yaml"
+}]`, string(output))
+}
+
+func (s *renumberTestsTestSuite) TestRenumberTests_JUnitOutput() {
+	read := s.captureStdout()
+
+	s.writeTestFile("123456.yaml", "test_id: homer")
+	s.writeTestFile("234567.yaml", "test_id: 1\n")
+	s.cmdContext.Output = internal.JUnit
+	s.cmd.SetArgs([]string{"-ca"})
+	_, err := s.cmd.ExecuteC()
+	s.Require().NoError(os.Stdout.Close())
+
+	s.ErrorIs(err, &util.TestNumberingError{})
+
+	output, err := io.ReadAll(read)
+	s.Require().NoError(err)
+	s.Contains(string(output), `<testsuite name="test-numbering" tests="2" failures="1">`)
+	s.Contains(string(output), `<failure message="Test file not properly numbered: 123456.yaml" type="warning">`)
+}
+
 func (s *renumberTestsTestSuite) TestRenumberTests_Legacy_WithYaml() {
 	s.writeTestFile("123456.yaml", "test_title: homer")
 	s.cmd.SetArgs([]string{"123456"})
@@ -205,7 +247,7 @@ func (s *renumberTestsTestSuite) TestRenumberTests_Legacy_GitHubOutput() {
 	s.Require().NoError(err)
 
 	output := string(buffer)
-	s.Contains(output, "::warning::Test file not properly numbered")
+	s.Contains(output, "::warning file=tests/regression/tests/_prefix_123_suffix_/123456.yaml,line=1::Test file not properly numbered: 123456.yaml")
 	s.Contains(output, "::error::")
 	s.Contains(output, "Please run `crs-toolchain util renumber-tests --all`")
 }
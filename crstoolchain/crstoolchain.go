@@ -0,0 +1,251 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package crstoolchain exposes the regular expression tooling of crs-toolchain as a Go API that
+// can be embedded into other programs. In contrast to the commands, no function of this package
+// exits the process; all failures are returned as errors.
+//
+// The toolchain, and the parser, operators and processors it uses, log to the logger of the
+// Options. Nothing is logged by default.
+//
+// The context passed to the functions of a Toolchain is checked for cancellation between the
+// steps of an operation, e.g., before the rule file is written by Update. A step that has
+// started, such as assembling a regular expression, is not interrupted.
+package crstoolchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	crsContext "github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/format"
+	"github.com/coreruleset/crs-toolchain/v2/regex/operators"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/seclang"
+)
+
+const defaultConfigurationFileName = "toolchain.yaml"
+
+// Options configures a Toolchain.
+type Options struct {
+	// Logger receives the log messages of the Toolchain. The zero value, like zerolog.Nop(),
+	// discards all messages.
+	Logger zerolog.Logger
+	// ConfigurationFileName is the name of the toolchain configuration file in the
+	// regex-assembly directory. Defaults to "toolchain.yaml".
+	ConfigurationFileName string
+}
+
+// Toolchain runs the regex tooling against a CRS directory structure.
+type Toolchain struct {
+	rootContext *crsContext.Context
+	logger      zerolog.Logger
+}
+
+// Comparison is the result of comparing the regular expression of a rule with the one generated
+// from its regex-assembly file.
+type Comparison struct {
+	// RuleId is the ID of the rule, without the chain offset.
+	RuleId string
+	// ChainOffset is the offset of the compared rule in the chain, 0 for the rule itself.
+	ChainOffset uint8
+	// RuleFile is the path to the file that contains the rule.
+	RuleFile string
+	// Current is the regular expression currently used by the rule.
+	Current string
+	// Generated is the regular expression generated from the regex-assembly file.
+	Generated string
+}
+
+// Changed returns true if the regular expression of the rule is not up to date.
+func (c *Comparison) Changed() bool {
+	return c.Current != c.Generated
+}
+
+// New creates a Toolchain for the CRS directory structure at `rootDir`.
+func New(rootDir string, options Options) (*Toolchain, error) {
+	info, err := os.Stat(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access root directory %s: %w", rootDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root directory %s is not a directory", rootDir)
+	}
+
+	configurationFileName := options.ConfigurationFileName
+	if configurationFileName == "" {
+		configurationFileName = defaultConfigurationFileName
+	}
+	return &Toolchain{
+		rootContext: crsContext.New(rootDir, configurationFileName),
+		logger:      options.Logger.With().Str("component", "crstoolchain").Logger(),
+	}, nil
+}
+
+// Assemble generates the regular expression for the regex-assembly contents read from `reader`.
+// Include and definition directives are resolved relative to the root directory of the Toolchain.
+func (t *Toolchain) Assemble(ctx context.Context, reader io.Reader) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	input, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read regex-assembly input: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	t.logger.Debug().Msg("Assembling input")
+	return operators.NewAssembler(t.newContext()).Run(string(input))
+}
+
+// Format formats the regex-assembly contents read from `reader` and returns the formatted
+// contents.
+func (t *Toolchain) Format(ctx context.Context, reader io.Reader) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	t.logger.Debug().Msg("Formatting input")
+	lines, _, err := format.FormatAssembly(t.newContext(), "", reader)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Compare generates the regular expression of the rule `ruleId` (e.g., "932100" or
+// "932100-chain1") from its regex-assembly file and compares it with the regular expression of
+// the rule.
+func (t *Toolchain) Compare(ctx context.Context, ruleId string) (*Comparison, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	comparison, _, _, err := t.compare(ctx, ruleId)
+	return comparison, err
+}
+
+// Update replaces the regular expression of the rule `ruleId` (e.g., "932100" or
+// "932100-chain1") with the one generated from its regex-assembly file. Returns true if the
+// rule file was changed. In contrast to `regex update`, the test vectors of the regex-assembly
+// file are not verified.
+func (t *Toolchain) Update(ctx context.Context, ruleId string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	comparison, file, rule, err := t.compare(ctx, ruleId)
+	if err != nil {
+		return false, err
+	}
+	if !comparison.Changed() {
+		t.logger.Debug().Msgf("Rule %s is up to date", ruleId)
+		return false, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	contents, err := file.ReplaceOperatorArgument(rule, comparison.Generated)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(comparison.RuleFile)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(comparison.RuleFile, contents, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write rule file %s: %w", comparison.RuleFile, err)
+	}
+	t.logger.Info().Msgf("Updated rule %s in %s", ruleId, comparison.RuleFile)
+	return true, nil
+}
+
+func (t *Toolchain) compare(ctx context.Context, ruleIdAndChainOffset string) (*Comparison, *seclang.File, *seclang.Rule, error) {
+	ruleId, chainOffset, err := parseRuleId(ruleIdAndChainOffset)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	assemblyFilePath := path.Join(t.rootContext.AssemblyDir(), ruleIdAndChainOffset+".ra")
+	input, err := os.ReadFile(assemblyFilePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read regex-assembly file %s: %w", assemblyFilePath, err)
+	}
+	t.logger.Debug().Msgf("Assembling %s", assemblyFilePath)
+	generated, err := operators.NewAssemblerForFile(t.newContext(), assemblyFilePath).Run(string(input))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	ruleFilePath, err := t.findRuleFile(ruleId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	file, err := seclang.ParseFile(ruleFilePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse rule file %s: %w", ruleFilePath, err)
+	}
+	rule, err := file.FindRule(ruleId, chainOffset)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to find rule %s, chain offset %d: %w", ruleId, chainOffset, err)
+	}
+	if rule.Operator.Name != "rx" {
+		return nil, nil, nil, fmt.Errorf("rule %s, chain offset %d, in %s doesn't use @rx", ruleId, chainOffset, ruleFilePath)
+	}
+
+	return &Comparison{
+		RuleId:      ruleId,
+		ChainOffset: chainOffset,
+		RuleFile:    ruleFilePath,
+		Current:     rule.Operator.Argument.Value,
+		Generated:   generated,
+	}, file, rule, nil
+}
+
+// newContext creates a processor context that logs to the logger of the Toolchain.
+func (t *Toolchain) newContext() *processors.Context {
+	ctxt := processors.NewContext(t.rootContext)
+	ctxt.SetLogger(t.logger)
+	return ctxt
+}
+
+// findRuleFile returns the path to the rule file that contains the rule `ruleId`. Rule files are
+// named after the first three digits of the IDs of their rules (e.g., REQUEST-932-...).
+func (t *Toolchain) findRuleFile(ruleId string) (string, error) {
+	matches, err := filepath.Glob(fmt.Sprintf("%s/*-%s-*", t.rootContext.RulesDir(), ruleId[:3]))
+	if err != nil {
+		return "", fmt.Errorf("failed to find rule file for rule id %s: %w", ruleId, err)
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("failed to find rule file for rule id %s", ruleId)
+	}
+	return matches[0], nil
+}
+
+// parseRuleId splits IDs like "932100-chain1" into the rule ID and the chain offset.
+func parseRuleId(idAndChainOffset string) (string, uint8, error) {
+	subs := regex.RuleIdFileNameRegex.FindStringSubmatch(idAndChainOffset)
+	if subs == nil || strings.HasSuffix(idAndChainOffset, ".ra") {
+		return "", 0, errors.New("failed to match rule ID, expected a format like 932100 or 932100-chain1")
+	}
+	if subs[2] == "" {
+		return subs[1], 0, nil
+	}
+	chainOffset, err := strconv.ParseUint(subs[2], 10, 8)
+	if err != nil {
+		return "", 0, errors.New("failed to match chain offset. Value must not be larger than 255")
+	}
+	return subs[1], uint8(chainOffset), nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package crstoolchain
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type crsToolchainTestSuite struct {
+	suite.Suite
+	rootDir   string
+	dataDir   string
+	rulesDir  string
+	toolchain *Toolchain
+}
+
+func (s *crsToolchainTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.dataDir = path.Join(s.rootDir, "regex-assembly")
+	s.Require().NoError(os.MkdirAll(path.Join(s.dataDir, "include"), fs.ModePerm))
+	s.rulesDir = path.Join(s.rootDir, "rules")
+	s.Require().NoError(os.Mkdir(s.rulesDir, fs.ModePerm))
+
+	toolchain, err := New(s.rootDir, Options{})
+	s.Require().NoError(err)
+	s.toolchain = toolchain
+}
+
+func TestRunCrsToolchainTestSuite(t *testing.T) {
+	suite.Run(t, new(crsToolchainTestSuite))
+}
+
+func (s *crsToolchainTestSuite) TestNew_MissingRootDir() {
+	_, err := New(path.Join(s.rootDir, "missing"), Options{})
+	s.ErrorIs(err, fs.ErrNotExist)
+}
+
+func (s *crsToolchainTestSuite) TestNew_InjectedLogger() {
+	out := &bytes.Buffer{}
+	toolchain, err := New(s.rootDir, Options{Logger: zerolog.New(out).Level(zerolog.TraceLevel)})
+	s.Require().NoError(err)
+
+	_, err = toolchain.Assemble(context.Background(), strings.NewReader("homer\n"))
+	s.Require().NoError(err)
+	s.Contains(out.String(), `"component":"crstoolchain"`)
+	s.Contains(out.String(), "Assembling input")
+	// Messages of the parser and the operators go to the injected logger as well
+	s.Contains(out.String(), "Starting assembler")
+	s.Contains(out.String(), "parsing line")
+}
+
+func (s *crsToolchainTestSuite) TestAssemble() {
+	actual, err := s.toolchain.Assemble(context.Background(), strings.NewReader("homer\nmarge\n"))
+	s.Require().NoError(err)
+	s.Equal("homer|marge", actual)
+}
+
+func (s *crsToolchainTestSuite) TestAssemble_Include() {
+	s.writeFile(path.Join(s.dataDir, "include", "simpsons.ra"), "homer\nmarge\n")
+
+	actual, err := s.toolchain.Assemble(context.Background(), strings.NewReader("##!> include simpsons\n"))
+	s.Require().NoError(err)
+	s.Equal("homer|marge", actual)
+}
+
+func (s *crsToolchainTestSuite) TestAssemble_ReturnsError() {
+	_, err := s.toolchain.Assemble(context.Background(), strings.NewReader("##!> include missing\n"))
+	s.Error(err)
+}
+
+func (s *crsToolchainTestSuite) TestAssemble_CanceledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.toolchain.Assemble(ctx, strings.NewReader("homer\n"))
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *crsToolchainTestSuite) TestFormat() {
+	actual, err := s.toolchain.Format(context.Background(), strings.NewReader("##!+   i\n  homer"))
+	s.Require().NoError(err)
+	s.Equal(`##! Please refer to the documentation at
+##! https://coreruleset.org/docs/development/regex_assembly/.
+
+##!+ i
+homer
+`, actual)
+}
+
+func (s *crsToolchainTestSuite) TestCompare() {
+	s.writeFile(path.Join(s.dataDir, "123456.ra"), "homer\nmarge\n")
+	s.writeRuleFile(`SecRule ARGS "@rx homer|marge" \
+	"id:123456"
+SecRule ARGS "@rx bart" \
+	"id:123457"`)
+
+	comparison, err := s.toolchain.Compare(context.Background(), "123456")
+	s.Require().NoError(err)
+	s.False(comparison.Changed())
+	s.Equal("123456", comparison.RuleId)
+	s.Equal(path.Join(s.rulesDir, "prefix-123-suffix.conf"), comparison.RuleFile)
+}
+
+func (s *crsToolchainTestSuite) TestCompare_Changed() {
+	s.writeFile(path.Join(s.dataDir, "123456-chain1.ra"), "lisa\n")
+	s.writeRuleFile(`SecRule ARGS "@rx homer" \
+	"id:123456,\
+	chain"
+	SecRule ARGS "@rx bart" \
+		"t:none"`)
+
+	comparison, err := s.toolchain.Compare(context.Background(), "123456-chain1")
+	s.Require().NoError(err)
+	s.True(comparison.Changed())
+	s.Equal(uint8(1), comparison.ChainOffset)
+	s.Equal("bart", comparison.Current)
+	s.Equal("lisa", comparison.Generated)
+}
+
+func (s *crsToolchainTestSuite) TestCompare_InvalidRuleId() {
+	_, err := s.toolchain.Compare(context.Background(), "homer")
+	s.ErrorContains(err, "failed to match rule ID")
+}
+
+func (s *crsToolchainTestSuite) TestCompare_NotRx() {
+	s.writeFile(path.Join(s.dataDir, "123456.ra"), "homer\n")
+	s.writeRuleFile(`SecRule ARGS "@pm homer" \
+	"id:123456"`)
+
+	_, err := s.toolchain.Compare(context.Background(), "123456")
+	s.ErrorContains(err, "doesn't use @rx")
+}
+
+func (s *crsToolchainTestSuite) TestUpdate() {
+	s.writeFile(path.Join(s.dataDir, "123456.ra"), "homer\nmarge\n")
+	s.writeRuleFile(`SecRule ARGS "@rx homer" \
+	"id:123456"`)
+
+	updated, err := s.toolchain.Update(context.Background(), "123456")
+	s.Require().NoError(err)
+	s.True(updated)
+	s.Equal(`SecRule ARGS "@rx homer|marge" \
+	"id:123456"`, s.readRuleFile())
+
+	updated, err = s.toolchain.Update(context.Background(), "123456")
+	s.Require().NoError(err)
+	s.False(updated)
+}
+
+func (s *crsToolchainTestSuite) TestUpdate_CanceledContext() {
+	s.writeFile(path.Join(s.dataDir, "123456.ra"), "homer\nmarge\n")
+	s.writeRuleFile(`SecRule ARGS "@rx homer" \
+	"id:123456"`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.toolchain.Update(ctx, "123456")
+	s.ErrorIs(err, context.Canceled)
+	s.Equal(`SecRule ARGS "@rx homer" \
+	"id:123456"`, s.readRuleFile())
+}
+
+func (s *crsToolchainTestSuite) writeFile(filePath string, contents string) {
+	s.Require().NoError(os.WriteFile(filePath, []byte(contents), fs.ModePerm))
+}
+
+func (s *crsToolchainTestSuite) writeRuleFile(contents string) {
+	s.writeFile(path.Join(s.rulesDir, "prefix-123-suffix.conf"), contents)
+}
+
+func (s *crsToolchainTestSuite) readRuleFile() string {
+	contents, err := os.ReadFile(path.Join(s.rulesDir, "prefix-123-suffix.conf"))
+	s.Require().NoError(err)
+	return string(contents)
+}
@@ -20,7 +20,7 @@ var logger = log.With().Str("component", "updater").Logger()
 func getLatestVersionFromGitHub() (*selfupdate.Release, error) {
 	source, err := selfupdate.NewGitHubSource(selfupdate.GitHubConfig{})
 	if err != nil {
-		logger.Fatal().Err(err)
+		return nil, err
 	}
 	updater, err := selfupdate.NewUpdater(selfupdate.Config{
 		Source:    source,
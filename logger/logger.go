@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+
+	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
 const DefaultLogLevel zerolog.Level = zerolog.InfoLevel
@@ -19,9 +22,29 @@ func init() {
 	zerolog.SetGlobalLevel(DefaultLogLevel)
 }
 
+// Field names of the location of a GitHub annotation, e.g.,
+// `logger.Error().Str(logger.FileFieldName, "rules/REQUEST-932.conf").Int(logger.LineFieldName, 12)`.
+const (
+	FileFieldName   = "file"
+	LineFieldName   = "line"
+	ColumnFieldName = "col"
+)
+
+// locatedLevel replaces the level of events with a location, see `prepareLocation`
+type locatedLevel struct{}
+
+// locatedAnnotation is the complete annotation of an event with a location
+type locatedAnnotation string
+
+// SetGithubOutput returns a logger that writes events as GitHub Actions workflow commands.
+// Events with a file field annotate that file, at the line and column fields if present.
 func SetGithubOutput(w io.Writer) zerolog.Logger {
 	ghOutput := zerolog.ConsoleWriter{Out: w, TimeFormat: "03:04:05"}
+	ghOutput.FormatPrepare = prepareLocation
 	ghOutput.FormatLevel = func(i any) string {
+		if _, ok := i.(locatedLevel); ok {
+			return ""
+		}
 		var l string
 		if ll, ok := i.(string); ok {
 			switch ll {
@@ -44,6 +67,9 @@ func SetGithubOutput(w io.Writer) zerolog.Logger {
 		return fmt.Sprintf("::%s", l)
 	}
 	ghOutput.FormatMessage = func(i any) string {
+		if annotation, ok := i.(locatedAnnotation); ok {
+			return fmt.Sprintf("%s\n", annotation)
+		}
 		return fmt.Sprintf("::%s\n", i)
 	}
 	ghOutput.PartsExclude = []string{zerolog.TimestampFieldName, zerolog.CallerFieldName}
@@ -55,3 +81,38 @@ func SetGithubOutput(w io.Writer) zerolog.Logger {
 
 	return log.Output(ghOutput).With().Caller().Logger()
 }
+
+// prepareLocation turns events with a file field into complete annotations of the file, so that
+// the location is part of the workflow command instead of being printed as fields.
+func prepareLocation(evt map[string]any) error {
+	file, ok := evt[FileFieldName].(string)
+	if !ok || file == "" {
+		return nil
+	}
+	command := "notice"
+	switch evt[zerolog.LevelFieldName] {
+	case zerolog.LevelTraceValue, zerolog.LevelDebugValue:
+		command = "debug"
+	case zerolog.LevelWarnValue:
+		command = "warning"
+	case zerolog.LevelErrorValue, zerolog.LevelFatalValue, zerolog.LevelPanicValue:
+		command = "error"
+	}
+	message, _ := evt[zerolog.MessageFieldName].(string)
+	evt[zerolog.LevelFieldName] = locatedLevel{}
+	evt[zerolog.MessageFieldName] = locatedAnnotation(utils.GitHubAnnotation(
+		command, file, intField(evt, LineFieldName), intField(evt, ColumnFieldName), message))
+	delete(evt, FileFieldName)
+	delete(evt, LineFieldName)
+	delete(evt, ColumnFieldName)
+	return nil
+}
+
+// intField returns the integer value of the field `name`, or 0 if the event has no such field.
+func intField(evt map[string]any, name string) int {
+	value, err := strconv.Atoi(fmt.Sprint(evt[name]))
+	if err != nil {
+		return 0
+	}
+	return value
+}
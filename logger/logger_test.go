@@ -137,3 +137,18 @@ func (s *loggerTestSuite) TestSetGithubOutput() {
 		})
 	}
 }
+
+func (s *loggerTestSuite) TestSetGithubOutput_WithLocation() {
+	logger := SetGithubOutput(s.out)
+	logger.Error().
+		Str(FileFieldName, "rules/REQUEST-932.conf").
+		Int(LineFieldName, 12).
+		Int(ColumnFieldName, 20).
+		Msg("regex is out of date")
+	s.Contains(s.out.String(), "::error file=rules/REQUEST-932.conf,line=12,col=20::regex is out of date\n")
+	s.NotContains(s.out.String(), "file=rules/REQUEST-932.conf ")
+	s.out.Reset()
+
+	logger.Warn().Str(FileFieldName, "regex-assembly/932100.ra").Msg("not formatted")
+	s.Contains(s.out.String(), "::warning file=regex-assembly/932100.ra::not formatted\n")
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ast declares the types used to represent the syntax tree of regex-assembly files.
+// Every node of the tree retains the source text it was parsed from, so that the tree can be
+// printed back to the exact source with `Print`.
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position is a position in a regex-assembly file. Lines and columns are 1-based, columns count
+// bytes. The offset is the 0-based byte offset from the start of the file.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Node is implemented by all nodes of the syntax tree.
+type Node interface {
+	// Pos returns the position of the first character of the node's first line,
+	// including indentation.
+	Pos() Position
+	node()
+}
+
+// Line holds the source text of a single line. It is embedded in all nodes, except for
+// blocks, which span multiple lines.
+type Line struct {
+	Position Position
+	// Text is the line as written in the source, including indentation, without the line terminator.
+	Text string
+	// Newline is the line terminator, "\n" or "\r\n", or empty for a last line without terminator.
+	Newline string
+}
+
+func (l *Line) Pos() Position {
+	return l.Position
+}
+
+// Indent returns the leading whitespace of the line.
+func (l *Line) Indent() string {
+	return l.Text[:len(l.Text)-len(strings.TrimLeft(l.Text, " \t"))]
+}
+
+func (*Line) node() {}
+
+func (l *Line) sourceLine() *Line {
+	return l
+}
+
+// Argument is a word of a directive, e.g., the name of an included file.
+type Argument struct {
+	Position Position
+	Value    string
+}
+
+// Replacement is a suffix replacement of an include or include-except directive. A `To` value
+// of `""` (two double quotes) removes the suffix.
+type Replacement struct {
+	From Argument
+	To   Argument
+}
+
+// Empty is a line that contains only whitespace.
+type Empty struct {
+	Line
+}
+
+// Comment is a comment line (##! <text>). Text is everything after the comment marker.
+type Comment struct {
+	Line
+	Text string
+}
+
+// Pattern is a line that contains a regular expression, or any other input to processors.
+// Value is the line without indentation.
+type Pattern struct {
+	Line
+	Value string
+}
+
+// Flags is a flags directive (##!+ <flags>).
+type Flags struct {
+	Line
+	Flags Argument
+}
+
+// Prefix is a prefix directive (##!^ <prefix>).
+type Prefix struct {
+	Line
+	Value Argument
+}
+
+// Suffix is a suffix directive (##!$ <suffix>).
+type Suffix struct {
+	Line
+	Value Argument
+}
+
+// Define is a definition directive (##!> define <name> <value>).
+type Define struct {
+	Line
+	Name  Argument
+	Value Argument
+}
+
+// Output is an output directive (##!> output <kind>), see `parser.Parser.Output`.
+type Output struct {
+	Line
+	Kind Argument
+}
+
+// Include is an include directive (##!> include <name> [-- <from> <to>...]).
+type Include struct {
+	Line
+	Name         Argument
+	Replacements []Replacement
+}
+
+// IncludeExcept is an include-except directive
+// (##!> include-except <name> <exclude>... [-- <from> <to>...]).
+type IncludeExcept struct {
+	Line
+	Name         Argument
+	Excludes     []Argument
+	Replacements []Replacement
+}
+
+// StashInput is an input directive of the assemble processor (##!=< <name>), which stores the
+// output of the preceding lines under the given name.
+type StashInput struct {
+	Line
+	Name Argument
+}
+
+// StashOutput is an output directive of the assemble processor (##!=> [<name>]), which appends the
+// stored output with the given name, or the output of the preceding lines if there is no name.
+type StashOutput struct {
+	Line
+	Name Argument
+}
+
+// TestVector is a test vector directive (##!? match <input>, ##!? nomatch <input>). Input is
+// the input as written, i.e., still quoted if it is enclosed in double quotes.
+type TestVector struct {
+	Line
+	Kind  Argument
+	Input Argument
+}
+
+// BlockEnd is the line that ends a block (##!<).
+type BlockEnd struct {
+	Line
+}
+
+// Block is a processor block (##!> assemble, ##!> cmdline <argument>) with its body.
+// End is nil if the block is not terminated.
+type Block struct {
+	Start     Line
+	Processor Argument
+	Argument  Argument
+	Body      []Node
+	End       *BlockEnd
+}
+
+func (b *Block) Pos() Position {
+	return b.Start.Position
+}
+
+func (*Block) node() {}
+
+// File is the syntax tree of a regex-assembly file.
+type File struct {
+	Name  string
+	Nodes []Node
+}
+
+// Inspect traverses the tree rooted at `node` in depth-first order. It calls `f` for each node,
+// and descends into the body of blocks only if `f` returns true.
+func Inspect(node Node, f func(Node) bool) {
+	if !f(node) {
+		return
+	}
+	if block, ok := node.(*Block); ok {
+		for _, child := range block.Body {
+			Inspect(child, f)
+		}
+	}
+}
+
+// Inspect traverses all the nodes of the file, see `Inspect`.
+func (f *File) Inspect(fn func(Node) bool) {
+	for _, node := range f.Nodes {
+		Inspect(node, fn)
+	}
+}
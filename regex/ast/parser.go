@@ -0,0 +1,252 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+)
+
+// unnamedInput is used in place of a file name in errors when the parsed input was not read
+// from a file.
+const unnamedInput = "<input>"
+
+var fieldRegex = regexp.MustCompile(`\S+`)
+
+// Error is a syntax error in a regex-assembly file.
+type Error struct {
+	File     string
+	Position Position
+	Message  string
+}
+
+func (e *Error) Error() string {
+	file := e.File
+	if file == "" {
+		file = unnamedInput
+	}
+	return fmt.Sprintf("%s:%s: %s", file, e.Position, e.Message)
+}
+
+// ErrorList is the list of syntax errors in a file, in the order they appear.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, 0, len(l))
+	for _, err := range l {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+type parser struct {
+	file   *File
+	blocks []*Block
+	errors ErrorList
+}
+
+// Parse parses the contents of the regex-assembly file `name`. The file name is only used to
+// report errors. Lines are classified the same way the regex-assembly parser classifies them.
+// The returned file contains all nodes, even if there are syntax errors, in which case an
+// ErrorList is returned as well.
+func Parse(name string, src []byte) (*File, error) {
+	p := &parser{
+		file: &File{Name: name, Nodes: []Node{}},
+	}
+	for _, line := range splitLines(src) {
+		p.parseLine(line)
+	}
+	for _, block := range p.blocks {
+		p.error(block.Start.Position, "unterminated %s block", block.Processor.Value)
+	}
+
+	if len(p.errors) > 0 {
+		return p.file, p.errors
+	}
+	return p.file, nil
+}
+
+// ParseReader is like `Parse` but reads the contents of the file from `reader`.
+func ParseReader(name string, reader io.Reader) (*File, error) {
+	src, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(name, src)
+}
+
+func splitLines(src []byte) []Line {
+	lines := []Line{}
+	offset := 0
+	for number := 1; offset < len(src); number++ {
+		text := src[offset:]
+		newline := ""
+		if index := bytes.IndexByte(text, '\n'); index >= 0 {
+			text = text[:index]
+			newline = "\n"
+			if bytes.HasSuffix(text, []byte("\r")) {
+				text = text[:len(text)-1]
+				newline = "\r\n"
+			}
+		}
+		lines = append(lines, Line{
+			Position: Position{Offset: offset, Line: number, Column: 1},
+			Text:     string(text),
+			Newline:  newline,
+		})
+		offset += len(text) + len(newline)
+	}
+	return lines
+}
+
+func (p *parser) parseLine(line Line) {
+	indent := len(line.Indent())
+	text := line.Text[indent:]
+	// argument returns the argument captured in group `group` of `submatches`
+	argument := func(submatches []int, group int) Argument {
+		start, end := submatches[2*group], submatches[2*group+1]
+		if start < 0 {
+			return Argument{Position: p.position(line, indent+len(text))}
+		}
+		return Argument{Position: p.position(line, indent+start), Value: text[start:end]}
+	}
+	// arguments returns the whitespace separated arguments captured in group `group` of `submatches`
+	arguments := func(submatches []int, group int) []Argument {
+		start, end := submatches[2*group], submatches[2*group+1]
+		if start < 0 {
+			return nil
+		}
+		args := []Argument{}
+		for _, field := range fieldRegex.FindAllStringIndex(text[start:end], -1) {
+			args = append(args, Argument{
+				Position: p.position(line, indent+start+field[0]),
+				Value:    text[start+field[0] : start+field[1]],
+			})
+		}
+		return args
+	}
+
+	if strings.TrimSpace(text) == "" {
+		p.append(&Empty{Line: line})
+	} else if regex.CommentRegex.MatchString(text) {
+		p.append(&Comment{Line: line, Text: text[len("##!"):]})
+	} else if submatches := regex.TestVectorRegex.FindStringSubmatchIndex(text); submatches != nil {
+		vector := &TestVector{Line: line, Kind: argument(submatches, 1), Input: argument(submatches, 2)}
+		if vector.Kind.Value != "match" && vector.Kind.Value != "nomatch" {
+			p.error(vector.Kind.Position, "unknown test vector kind '%s'", vector.Kind.Value)
+		}
+		p.append(vector)
+	} else if submatches := regex.IncludeRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&Include{
+			Line:         line,
+			Name:         argument(submatches, 1),
+			Replacements: p.replacements(arguments(submatches, 2)),
+		})
+	} else if submatches := regex.IncludeExceptRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&IncludeExcept{
+			Line:         line,
+			Name:         argument(submatches, 1),
+			Excludes:     arguments(submatches, 2),
+			Replacements: p.replacements(arguments(submatches, 3)),
+		})
+	} else if submatches := regex.DefinitionRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&Define{Line: line, Name: argument(submatches, 2), Value: argument(submatches, 3)})
+	} else if submatches := regex.OutputRegex.FindStringSubmatchIndex(text); submatches != nil {
+		output := &Output{Line: line, Kind: argument(submatches, 1)}
+		if output.Kind.Value != "regex" && output.Kind.Value != "phrases" {
+			p.error(output.Kind.Position, "unknown output '%s'", output.Kind.Value)
+		}
+		p.append(output)
+	} else if submatches := regex.FlagsRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&Flags{Line: line, Flags: argument(submatches, 1)})
+	} else if submatches := regex.PrefixRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&Prefix{Line: line, Value: argument(submatches, 1)})
+	} else if submatches := regex.SuffixRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&Suffix{Line: line, Value: argument(submatches, 1)})
+	} else if submatches := regex.ProcessorBlockStartRegex.FindStringSubmatchIndex(text); submatches != nil {
+		block := &Block{
+			Start:     line,
+			Processor: argument(submatches, 1),
+			Argument:  argument(submatches, 2),
+			Body:      []Node{},
+		}
+		p.append(block)
+		p.blocks = append(p.blocks, block)
+	} else if regex.ProcessorEndRegex.MatchString(text) {
+		end := &BlockEnd{Line: line}
+		if len(p.blocks) == 0 {
+			p.error(line.Position, "end of block without matching start")
+			p.append(end)
+		} else {
+			p.blocks[len(p.blocks)-1].End = end
+			p.blocks = p.blocks[:len(p.blocks)-1]
+		}
+	} else if submatches := regex.AssembleInputRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&StashInput{Line: line, Name: argument(submatches, 1)})
+	} else if submatches := regex.AssembleOutputRegex.FindStringSubmatchIndex(text); submatches != nil {
+		p.append(&StashOutput{Line: line, Name: argument(submatches, 1)})
+	} else {
+		if strings.HasPrefix(text, "##!") {
+			p.error(p.position(line, indent), "invalid directive: %s", text)
+		}
+		p.append(&Pattern{Line: line, Value: text})
+	}
+}
+
+// replacements pairs up the arguments of suffix replacements.
+func (p *parser) replacements(args []Argument) []Replacement {
+	if args == nil {
+		return nil
+	}
+	if len(args)%2 > 0 {
+		p.error(args[0].Position, "uneven number of arguments found for suffix replacements")
+	}
+	replacements := make([]Replacement, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		replacements = append(replacements, Replacement{From: args[i], To: args[i+1]})
+	}
+	return replacements
+}
+
+// append adds `node` to the body of the innermost open block, or to the file if there is none.
+func (p *parser) append(node Node) {
+	if len(p.blocks) > 0 {
+		block := p.blocks[len(p.blocks)-1]
+		block.Body = append(block.Body, node)
+	} else {
+		p.file.Nodes = append(p.file.Nodes, node)
+	}
+}
+
+// position returns the position of the byte at `column` (0-based) in `line`.
+func (p *parser) position(line Line, column int) Position {
+	return Position{
+		Offset: line.Position.Offset + column,
+		Line:   line.Position.Line,
+		Column: column + 1,
+	}
+}
+
+func (p *parser) error(position Position, format string, args ...any) {
+	p.errors = append(p.errors, &Error{
+		File:     p.file.Name,
+		Position: position,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package ast
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type parserTestSuite struct {
+	suite.Suite
+}
+
+func TestRunParserTestSuite(t *testing.T) {
+	suite.Run(t, new(parserTestSuite))
+}
+
+func (s *parserTestSuite) TestParse_Directives() {
+	contents := `##! comment
+##!+ i
+##!^ prefix
+##!$ suffix
+##!> define name value
+
+##!> include file -- a b
+##!> include-except file ex1  ex2 -- c ""
+foo
+`
+	file, err := Parse("123456.ra", []byte(contents))
+	s.Require().NoError(err)
+	s.Equal("123456.ra", file.Name)
+	s.Require().Len(file.Nodes, 9)
+
+	comment := file.Nodes[0].(*Comment)
+	s.Equal(" comment", comment.Text)
+	s.Equal(Position{Offset: 0, Line: 1, Column: 1}, comment.Pos())
+
+	flags := file.Nodes[1].(*Flags)
+	s.Equal(Argument{Position: Position{Offset: 17, Line: 2, Column: 6}, Value: "i"}, flags.Flags)
+
+	s.Equal("prefix", file.Nodes[2].(*Prefix).Value.Value)
+	s.Equal("suffix", file.Nodes[3].(*Suffix).Value.Value)
+
+	define := file.Nodes[4].(*Define)
+	s.Equal(Argument{Position: Position{Offset: 55, Line: 5, Column: 13}, Value: "name"}, define.Name)
+	s.Equal(Argument{Position: Position{Offset: 60, Line: 5, Column: 18}, Value: "value"}, define.Value)
+
+	s.IsType(&Empty{}, file.Nodes[5])
+
+	include := file.Nodes[6].(*Include)
+	s.Equal(Argument{Position: Position{Offset: 80, Line: 7, Column: 14}, Value: "file"}, include.Name)
+	s.Equal([]Replacement{{
+		From: Argument{Position: Position{Offset: 88, Line: 7, Column: 22}, Value: "a"},
+		To:   Argument{Position: Position{Offset: 90, Line: 7, Column: 24}, Value: "b"},
+	}}, include.Replacements)
+
+	includeExcept := file.Nodes[7].(*IncludeExcept)
+	s.Equal("file", includeExcept.Name.Value)
+	s.Equal([]Argument{
+		{Position: Position{Offset: 117, Line: 8, Column: 26}, Value: "ex1"},
+		{Position: Position{Offset: 122, Line: 8, Column: 31}, Value: "ex2"},
+	}, includeExcept.Excludes)
+	s.Require().Len(includeExcept.Replacements, 1)
+	s.Equal("c", includeExcept.Replacements[0].From.Value)
+	s.Equal(`""`, includeExcept.Replacements[0].To.Value)
+
+	pattern := file.Nodes[8].(*Pattern)
+	s.Equal("foo", pattern.Value)
+	s.Equal(9, pattern.Pos().Line)
+}
+
+func (s *parserTestSuite) TestParse_Blocks() {
+	contents := `##!> assemble
+  foo
+  ##!=< stored
+  ##!> cmdline unix
+    bar
+  ##!<
+  ##!=> stored
+  ##!=>
+##!<
+`
+	file, err := Parse("", []byte(contents))
+	s.Require().NoError(err)
+	s.Require().Len(file.Nodes, 1)
+
+	assemble := file.Nodes[0].(*Block)
+	s.Equal("assemble", assemble.Processor.Value)
+	s.Equal("", assemble.Argument.Value)
+	s.Require().NotNil(assemble.End)
+	s.Equal(9, assemble.End.Pos().Line)
+	s.Require().Len(assemble.Body, 5)
+
+	s.Equal("  ", assemble.Body[0].(*Pattern).Indent())
+	s.Equal("foo", assemble.Body[0].(*Pattern).Value)
+	input := assemble.Body[1].(*StashInput)
+	s.Equal(Argument{Position: Position{Offset: 28, Line: 3, Column: 9}, Value: "stored"}, input.Name)
+
+	cmdline := assemble.Body[2].(*Block)
+	s.Equal("cmdline", cmdline.Processor.Value)
+	s.Equal(Argument{Position: Position{Offset: 50, Line: 4, Column: 16}, Value: "unix"}, cmdline.Argument)
+	s.Require().Len(cmdline.Body, 1)
+	s.Equal("bar", cmdline.Body[0].(*Pattern).Value)
+	s.Require().NotNil(cmdline.End)
+
+	s.Equal("stored", assemble.Body[3].(*StashOutput).Name.Value)
+	s.Equal("", assemble.Body[4].(*StashOutput).Name.Value)
+}
+
+func (s *parserTestSuite) TestParse_TestVectors() {
+	contents := "##!? match foo bar\n  ##!? nomatch \" baz\"\n##!? fails x\n"
+	file, err := Parse("123456.ra", []byte(contents))
+
+	var errorList ErrorList
+	s.Require().True(errors.As(err, &errorList))
+	s.Require().Len(errorList, 1)
+	s.Equal("123456.ra:3:6: unknown test vector kind 'fails'", errorList[0].Error())
+
+	s.Require().Len(file.Nodes, 3)
+	match := file.Nodes[0].(*TestVector)
+	s.Equal(Argument{Position: Position{Offset: 5, Line: 1, Column: 6}, Value: "match"}, match.Kind)
+	s.Equal(Argument{Position: Position{Offset: 11, Line: 1, Column: 12}, Value: "foo bar"}, match.Input)
+	noMatch := file.Nodes[1].(*TestVector)
+	s.Equal("nomatch", noMatch.Kind.Value)
+	s.Equal(`" baz"`, noMatch.Input.Value)
+	s.Equal(Position{Offset: 34, Line: 2, Column: 16}, noMatch.Input.Position)
+}
+
+func (s *parserTestSuite) TestParse_Output() {
+	contents := "##!> output phrases\n##!> output words\n"
+	file, err := Parse("123456.ra", []byte(contents))
+
+	var errorList ErrorList
+	s.Require().True(errors.As(err, &errorList))
+	s.Require().Len(errorList, 1)
+	s.Equal("123456.ra:2:13: unknown output 'words'", errorList[0].Error())
+
+	s.Require().Len(file.Nodes, 2)
+	output := file.Nodes[0].(*Output)
+	s.Equal(Argument{Position: Position{Offset: 12, Line: 1, Column: 13}, Value: "phrases"}, output.Kind)
+}
+
+func (s *parserTestSuite) TestParse_Errors() {
+	contents := "##!<\n##!> include file -- a\n##!=x\n##!> assemble\nfoo\n"
+	file, err := Parse("123456.ra", []byte(contents))
+
+	var errorList ErrorList
+	s.Require().True(errors.As(err, &errorList))
+	s.Require().Len(errorList, 4)
+	s.Equal("123456.ra:1:1: end of block without matching start", errorList[0].Error())
+	s.Equal("123456.ra:2:22: uneven number of arguments found for suffix replacements", errorList[1].Error())
+	s.Equal("123456.ra:3:1: invalid directive: ##!=x", errorList[2].Error())
+	s.Equal("123456.ra:4:1: unterminated assemble block", errorList[3].Error())
+	s.Equal("123456.ra:1:1: end of block without matching start (and 3 more errors)", err.Error())
+
+	// The tree is returned nevertheless
+	s.Require().Len(file.Nodes, 4)
+	s.IsType(&BlockEnd{}, file.Nodes[0])
+	s.Empty(file.Nodes[1].(*Include).Replacements)
+	s.IsType(&Pattern{}, file.Nodes[2])
+	block := file.Nodes[3].(*Block)
+	s.Nil(block.End)
+	s.Len(block.Body, 1)
+}
+
+func (s *parserTestSuite) TestParse_LineTerminators() {
+	file, err := Parse("", []byte("foo\r\n\r\nbar"))
+	s.Require().NoError(err)
+	s.Require().Len(file.Nodes, 3)
+
+	foo := file.Nodes[0].(*Pattern)
+	s.Equal("foo", foo.Value)
+	s.Equal("\r\n", foo.Newline)
+	s.IsType(&Empty{}, file.Nodes[1])
+	bar := file.Nodes[2].(*Pattern)
+	s.Equal(Position{Offset: 7, Line: 3, Column: 1}, bar.Pos())
+	s.Equal("", bar.Newline)
+}
+
+func (s *parserTestSuite) TestParseReader() {
+	file, err := ParseReader("", strings.NewReader("##! comment\n"))
+	s.Require().NoError(err)
+	s.Len(file.Nodes, 1)
+}
+
+func (s *parserTestSuite) TestInspect() {
+	file, err := Parse("", []byte("foo\n##!> assemble\nbar\n##!> assemble\nbaz\n##!<\n##!<\n"))
+	s.Require().NoError(err)
+
+	patterns := []string{}
+	file.Inspect(func(node Node) bool {
+		if pattern, ok := node.(*Pattern); ok {
+			patterns = append(patterns, pattern.Value)
+		}
+		return true
+	})
+	s.Equal([]string{"foo", "bar", "baz"}, patterns)
+
+	blocks := 0
+	file.Inspect(func(node Node) bool {
+		if _, ok := node.(*Block); ok {
+			blocks++
+			return false
+		}
+		return true
+	})
+	s.Equal(1, blocks)
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package ast
+
+import (
+	"bytes"
+	"io"
+)
+
+// Print writes the source of `file` to `writer`. Nodes are printed from the source text they
+// retain (see `Line`), so the output of an unmodified tree is identical to the parsed source.
+func Print(writer io.Writer, file *File) error {
+	for _, node := range file.Nodes {
+		if err := printNode(writer, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *File) String() string {
+	var buffer bytes.Buffer
+	// Writing to a buffer can't fail
+	_ = Print(&buffer, f)
+	return buffer.String()
+}
+
+func printNode(writer io.Writer, node Node) error {
+	block, ok := node.(*Block)
+	if !ok {
+		return printLine(writer, node.(interface{ sourceLine() *Line }).sourceLine())
+	}
+
+	if err := printLine(writer, &block.Start); err != nil {
+		return err
+	}
+	for _, child := range block.Body {
+		if err := printNode(writer, child); err != nil {
+			return err
+		}
+	}
+	if block.End != nil {
+		return printLine(writer, &block.End.Line)
+	}
+	return nil
+}
+
+func printLine(writer io.Writer, line *Line) error {
+	_, err := io.WriteString(writer, line.Text+line.Newline)
+	return err
+}
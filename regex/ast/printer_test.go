@@ -0,0 +1,48 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type printerTestSuite struct {
+	suite.Suite
+}
+
+func TestRunPrinterTestSuite(t *testing.T) {
+	suite.Run(t, new(printerTestSuite))
+}
+
+func (s *printerTestSuite) TestPrint_IsLossless() {
+	for _, contents := range []string{
+		"",
+		"\n",
+		"foo",
+		"##! comment\r\n\t##!+ i \r\nfoo\r\n",
+		`##! Please refer to the documentation at
+##! https://coreruleset.org/docs/development/regex_assembly/.
+
+##!+ i
+##!^ \b
+##!> define   slashes  [/\\]
+  ##!> include-except  file ex1 ex2 --  a  b
+##!> assemble
+  foo{{slashes}}
+  ##!=< stored
+  ##!> cmdline windows
+    bar
+  ##!<
+  ##!=>    stored
+##!<
+`,
+		// Invalid input is printed as is as well
+		"##!<\n##!=x\n##!> assemble\n  foo  ",
+	} {
+		file, _ := Parse("", []byte(contents))
+		s.Equal(contents, file.String())
+	}
+}
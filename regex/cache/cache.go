@@ -0,0 +1,146 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+var logger = log.With().Str("component", "cache").Logger()
+
+var toolchainIdentityOnce sync.Once
+var toolchainIdentity string
+var toolchainIdentityErr error
+
+// Cache stores generated regular expressions on disk. Entries are content addressed: the key of
+// an entry is a hash of everything the generated expression depends on, i.e., the contents of the
+// regex-assembly file, the contents of all files it includes (transitively), the patterns of the
+// toolchain configuration, and the toolchain itself. Entries never need to be invalidated.
+type Cache struct {
+	directory   string
+	rootContext *context.Context
+}
+
+// New creates a cache that stores its entries in `directory`.
+func New(rootContext *context.Context, directory string) *Cache {
+	return &Cache{
+		directory:   directory,
+		rootContext: rootContext,
+	}
+}
+
+// Key computes the key for the regex-assembly file with `contents`. Returns an error if any of
+// the inputs can't be read, in which case the result must not be cached.
+func (c *Cache) Key(contents []byte) (string, error) {
+	identity, err := getToolchainIdentity()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine toolchain version: %w", err)
+	}
+	patterns, err := json.Marshal(c.rootContext.Configuration().Patterns)
+	if err != nil {
+		return "", err
+	}
+
+	keyHash := sha256.New()
+	writeField(keyHash, "toolchain", []byte(identity))
+	writeField(keyHash, "patterns", patterns)
+	writeField(keyHash, "input", contents)
+
+	dependencyPaths, err := dependencies.Find(c.rootContext, contents)
+	if err != nil {
+		return "", err
+	}
+	for _, dependency := range dependencyPaths {
+		dependencyContents, err := os.ReadFile(dependency)
+		if err != nil {
+			return "", err
+		}
+		writeField(keyHash, dependency, dependencyContents)
+	}
+	return hex.EncodeToString(keyHash.Sum(nil)), nil
+}
+
+// Get returns the cached expression for `key`, if any.
+func (c *Cache) Get(key string) (string, bool) {
+	contents, err := os.ReadFile(path.Join(c.directory, key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Debug().Err(err).Msgf("Failed to read cache entry %s", key)
+		}
+		return "", false
+	}
+	return string(contents), true
+}
+
+// Put stores `expression` under `key`. Concurrent writers for the same key are safe, as entries
+// are written to a temporary file first and then moved into place.
+func (c *Cache) Put(key string, expression string) error {
+	if err := os.MkdirAll(c.directory, 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(c.directory, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(expression); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path.Join(c.directory, key))
+}
+
+// getToolchainIdentity returns a hash of the running executable. A version string alone isn't
+// enough, as development builds all share the same version.
+func getToolchainIdentity() (string, error) {
+	toolchainIdentityOnce.Do(func() {
+		executable, err := os.Executable()
+		if err != nil {
+			toolchainIdentityErr = err
+			return
+		}
+		file, err := os.Open(executable)
+		if err != nil {
+			toolchainIdentityErr = err
+			return
+		}
+		defer file.Close()
+		executableHash := sha256.New()
+		if _, err := io.Copy(executableHash, file); err != nil {
+			toolchainIdentityErr = err
+			return
+		}
+		toolchainIdentity = hex.EncodeToString(executableHash.Sum(nil))
+	})
+	return toolchainIdentity, toolchainIdentityErr
+}
+
+// writeField writes a length prefixed name and value, so that different inputs can't produce
+// the same stream of bytes.
+func writeField(keyHash hash.Hash, name string, value []byte) {
+	for _, part := range [][]byte{[]byte(name), value} {
+		_ = binary.Write(keyHash, binary.LittleEndian, uint64(len(part)))
+		keyHash.Write(part)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/configuration"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+type cacheTestSuite struct {
+	suite.Suite
+	rootDir    string
+	includeDir string
+	excludeDir string
+	cache      *Cache
+}
+
+func TestRunCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(cacheTestSuite))
+}
+
+func (s *cacheTestSuite) SetupTest() {
+	s.rootDir = s.T().TempDir()
+	s.includeDir = path.Join(s.rootDir, "regex-assembly", "include")
+	s.excludeDir = path.Join(s.rootDir, "regex-assembly", "exclude")
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+	s.cache = New(context.New(s.rootDir, "toolchain.yaml"), path.Join(s.rootDir, "cache"))
+}
+
+func (s *cacheTestSuite) writeFile(directory string, name string, contents string) {
+	err := os.WriteFile(path.Join(directory, name), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
+
+func (s *cacheTestSuite) TestKey_Stable() {
+	s.writeFile(s.includeDir, "include.ra", "foo\n")
+	contents := []byte("##!> include include\nbar\n")
+
+	first, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	second, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.Equal(first, second)
+
+	other, err := s.cache.Key([]byte("##!> include include\nbaz\n"))
+	s.Require().NoError(err)
+	s.NotEqual(first, other)
+}
+
+func (s *cacheTestSuite) TestKey_ChangesWithTransitiveInclude() {
+	s.writeFile(s.includeDir, "outer.ra", "##!> include inner\n")
+	s.writeFile(s.includeDir, "inner.ra", "foo\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "foo\n")
+	contents := []byte("##!> include outer\n##!> include-except inner exclude\n")
+
+	before, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.writeFile(s.includeDir, "inner.ra", "bar\n")
+	afterInclude, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.NotEqual(before, afterInclude)
+
+	s.writeFile(s.excludeDir, "exclude.ra", "bar\n")
+	afterExclude, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.NotEqual(afterInclude, afterExclude)
+}
+
+func (s *cacheTestSuite) TestKey_ChangesWithIncludeExceptInBlock() {
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "bar\n")
+	// Formatted files indent the directives inside processor blocks
+	contents := []byte("##!> cmdline unix\n  ##!> include-except words exclude\n##!<\n")
+
+	first, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+
+	s.writeFile(s.includeDir, "words.ra", "baz\n")
+	second, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.NotEqual(first, second)
+
+	s.writeFile(s.excludeDir, "exclude.ra", "qux\n")
+	third, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+	s.NotEqual(second, third)
+}
+
+func (s *cacheTestSuite) TestKey_ChangesWithPatterns() {
+	contents := []byte("##!> cmdline unix\n  cat\n##!<\n")
+	before, err := s.cache.Key(contents)
+	s.Require().NoError(err)
+
+	other := New(context.NewWithConfiguration(s.rootDir, &configuration.Configuration{
+		Patterns: configuration.Patterns{
+			AntiEvasion: configuration.Pattern{Unix: "_"},
+		},
+	}), path.Join(s.rootDir, "cache"))
+	after, err := other.Key(contents)
+	s.Require().NoError(err)
+	s.NotEqual(before, after)
+}
+
+func (s *cacheTestSuite) TestKey_MissingInclude() {
+	_, err := s.cache.Key([]byte("##!> include missing\n"))
+	s.ErrorIs(err, fs.ErrNotExist)
+}
+
+func (s *cacheTestSuite) TestKey_CyclicInclude() {
+	s.writeFile(s.includeDir, "a.ra", "##!> include b\n")
+	s.writeFile(s.includeDir, "b.ra", "##!> include a\n")
+	_, err := s.cache.Key([]byte("##!> include a\n"))
+	s.NoError(err)
+}
+
+func (s *cacheTestSuite) TestGetAndPut() {
+	_, found := s.cache.Get("key")
+	s.False(found)
+
+	err := s.cache.Put("key", "foo|bar")
+	s.Require().NoError(err)
+	expression, found := s.cache.Get("key")
+	s.True(found)
+	s.Equal("foo|bar", expression)
+
+	entries, err := os.ReadDir(path.Join(s.rootDir, "cache"))
+	s.Require().NoError(err)
+	s.Len(entries, 1)
+}
@@ -18,8 +18,16 @@ var IncludeExceptRegex = regexp.MustCompile(`^##!>\s*include-except\s+(\S+)\s*(.
 // The name is captured in group 2, the value in group 3.
 var DefinitionRegex = regexp.MustCompile(`^(##!>\s*define\s+([a-zA-Z0-9-_]+)\s+)(\S+)\s*$`)
 
+// OutputRegex matches an output line (##!> output <kind>).
+// The kind is captured in group 1.
+var OutputRegex = regexp.MustCompile(`^##!>\s*output\s+(\S+)\s*$`)
+
 // CommentRegex matches a comment line (##!, no other directives)
-var CommentRegex = regexp.MustCompile(`^\s*##!(?:[^^$+><=]|$)`)
+var CommentRegex = regexp.MustCompile(`^\s*##!(?:[^^$+><=?]|$)`)
+
+// TestVectorRegex matches a test vector line (##!? match <input>, ##!? nomatch <input>).
+// The kind of the vector is captured in group 1, the input in group 2.
+var TestVectorRegex = regexp.MustCompile(`^\s*##!\?\s*(\S*)[ \t]?(.*)$`)
 
 // FlagsRegex matches a flags line (##!+ <value>).
 // The value is captured in group 1.
@@ -56,9 +64,14 @@ var AssembleOutputRegex = regexp.MustCompile(`^\s*##!=>\s*(.*)$`)
 // RuleRxRegex matches a full SecRule line with @rx.
 // Everything up to the start of the regular expression is captured in group 1,
 // the end of the line after the regular expression is captured in group 2.
+//
+// Deprecated: RuleRxRegex doesn't handle rules that span multiple lines or quotes in the
+// regular expression. Use seclang.ParseFile and the Operator of the rule instead.
 var RuleRxRegex = regexp.MustCompile(`(.*"!?@rx )(.*)(" \\)`)
 
 // SecRuleRegex matches any SecRule line.
+//
+// Deprecated: Use seclang.ParseFile, which parses the SecRule directives of a file into rules.
 var SecRuleRegex = regexp.MustCompile(`\s*SecRule`)
 
 // RuleIdFileNameRegex matches the rule ID in a regex-assembly file name (<id>-<chain>.ra).
@@ -0,0 +1,103 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencies
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+)
+
+// Find returns the paths of all files that are included by `contents`, directly or
+// transitively, in the order they are first referenced. Both include and include-except
+// directives are followed. Cycles are not an error, every file is only reported once.
+func Find(rootContext *context.Context, contents []byte) ([]string, error) {
+	dependencies := []string{}
+	visited := map[string]bool{}
+	queue := [][]byte{contents}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, name := range IncludeNames(current) {
+			filePath, err := Resolve(rootContext, name)
+			if err != nil {
+				return nil, err
+			}
+			if visited[filePath] {
+				continue
+			}
+			visited[filePath] = true
+			dependencies = append(dependencies, filePath)
+
+			included, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, included)
+		}
+	}
+	return dependencies, nil
+}
+
+// Resolve returns the path of the file that the parser reads for the include named `name`.
+// Relative names are looked up in the include directory first, then in the exclude directory.
+// Returns an error wrapping `fs.ErrNotExist` if there is no such file.
+func Resolve(rootContext *context.Context, name string) (string, error) {
+	if path.Ext(name) != ".ra" {
+		name += ".ra"
+	}
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err != nil {
+			return "", fmt.Errorf("failed to resolve include %s: %w", name, err)
+		}
+		return filepath.Clean(name), nil
+	}
+	for _, directory := range []string{rootContext.IncludesDir(), rootContext.ExcludesDir()} {
+		filePath := filepath.Join(directory, name)
+		if _, err := os.Stat(filePath); err == nil {
+			return filePath, nil
+		}
+	}
+	return "", fmt.Errorf("failed to resolve include %s: %w", name, fs.ErrNotExist)
+}
+
+// IncludeNames returns the names of the files referenced by include and include-except
+// directives in `contents`, in the order they appear.
+func IncludeNames(contents []byte) []string {
+	names := []string{}
+	for _, directive := range includeDirectives(contents) {
+		names = append(names, directive.name)
+	}
+	return names
+}
+
+type includeDirective struct {
+	name string
+	kind EdgeKind
+}
+
+// includeDirectives returns the include and include-except directives of `contents`. Like the
+// parser, it removes indentation before classifying a line, as directives inside processor
+// blocks are indented.
+func includeDirectives(contents []byte) []includeDirective {
+	directives := []includeDirective{}
+	for _, rawLine := range strings.Split(string(contents), "\n") {
+		line := strings.TrimLeft(rawLine, " \t")
+		if match := regex.IncludeRegex.FindStringSubmatch(line); match != nil {
+			directives = append(directives, includeDirective{name: match[1], kind: IncludeEdge})
+		} else if match := regex.IncludeExceptRegex.FindStringSubmatch(line); match != nil {
+			directives = append(directives, includeDirective{name: match[1], kind: IncludeEdge})
+			for _, exclude := range strings.Fields(match[2]) {
+				directives = append(directives, includeDirective{name: exclude, kind: ExcludeEdge})
+			}
+		}
+	}
+	return directives
+}
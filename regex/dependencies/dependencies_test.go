@@ -0,0 +1,102 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencies
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+type dependenciesTestSuite struct {
+	suite.Suite
+	rootContext *context.Context
+	includeDir  string
+	excludeDir  string
+}
+
+func TestRunDependenciesTestSuite(t *testing.T) {
+	suite.Run(t, new(dependenciesTestSuite))
+}
+
+func (s *dependenciesTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.rootContext = context.New(rootDir, "toolchain.yaml")
+	s.includeDir = s.rootContext.IncludesDir()
+	s.excludeDir = s.rootContext.ExcludesDir()
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *dependenciesTestSuite) writeFile(directory string, name string, contents string) {
+	err := os.WriteFile(path.Join(directory, name), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
+
+func (s *dependenciesTestSuite) TestFind_Transitive() {
+	s.writeFile(s.includeDir, "outer.ra", "##!> include inner\n")
+	s.writeFile(s.includeDir, "inner.ra", "foo\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "foo\n")
+
+	dependencies, err := Find(s.rootContext, []byte("##!> include outer\n##!> include-except inner exclude\n"))
+	s.Require().NoError(err)
+	s.Equal([]string{
+		path.Join(s.includeDir, "outer.ra"),
+		path.Join(s.includeDir, "inner.ra"),
+		path.Join(s.excludeDir, "exclude.ra"),
+	}, dependencies)
+}
+
+func (s *dependenciesTestSuite) TestFind_IndentedDirectives() {
+	s.writeFile(s.includeDir, "inner.ra", "foo\n")
+	s.writeFile(s.includeDir, "words.ra", "bar\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "foo\n")
+
+	dependencies, err := Find(s.rootContext, []byte(`##!> cmdline unix
+  ##!> include inner
+  ##!> include-except words exclude
+##!<
+`))
+	s.Require().NoError(err)
+	s.Equal([]string{
+		path.Join(s.includeDir, "inner.ra"),
+		path.Join(s.includeDir, "words.ra"),
+		path.Join(s.excludeDir, "exclude.ra"),
+	}, dependencies)
+}
+
+func (s *dependenciesTestSuite) TestIncludeNames_IndentedIncludeExcept() {
+	names := IncludeNames([]byte("##!> assemble\n\t##!> include-except words exclude\n##!<\n"))
+	s.Equal([]string{"words", "exclude"}, names)
+}
+
+func (s *dependenciesTestSuite) TestFind_Cycle() {
+	s.writeFile(s.includeDir, "a.ra", "##!> include b\n")
+	s.writeFile(s.includeDir, "b.ra", "##!> include a\n")
+
+	dependencies, err := Find(s.rootContext, []byte("##!> include a\n"))
+	s.Require().NoError(err)
+	s.Equal([]string{path.Join(s.includeDir, "a.ra"), path.Join(s.includeDir, "b.ra")}, dependencies)
+}
+
+func (s *dependenciesTestSuite) TestFind_Missing() {
+	_, err := Find(s.rootContext, []byte("foo\n##!> include missing\n"))
+	s.ErrorIs(err, fs.ErrNotExist)
+}
+
+func (s *dependenciesTestSuite) TestResolve_PrefersIncludeDirectory() {
+	s.writeFile(s.includeDir, "both.ra", "foo\n")
+	s.writeFile(s.excludeDir, "both.ra", "foo\n")
+
+	filePath, err := Resolve(s.rootContext, "both")
+	s.Require().NoError(err)
+	s.Equal(path.Join(s.includeDir, "both.ra"), filePath)
+}
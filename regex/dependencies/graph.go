@@ -0,0 +1,252 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencies
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+)
+
+// EdgeKind is the kind of a reference from one file to another.
+type EdgeKind string
+
+const (
+	// IncludeEdge is created by `include` directives and by the first argument of
+	// `include-except` directives.
+	IncludeEdge EdgeKind = "include"
+	// ExcludeEdge is created by the exclude arguments of `include-except` directives.
+	ExcludeEdge EdgeKind = "exclude"
+)
+
+// Edge is a reference from one file to another.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// MissingInclude is a reference to a file that doesn't exist.
+type MissingInclude struct {
+	From string `json:"from"`
+	Name string `json:"name"`
+}
+
+// Graph is the include graph of the regex-assembly files. Files are identified by their paths.
+type Graph struct {
+	rootContext *context.Context
+	files       []string
+	edges       map[string][]Edge
+	dependents  map[string][]string
+	missing     []MissingInclude
+}
+
+// NewGraph builds the include graph of all regex-assembly files of the rules, and of all files
+// in the include and exclude directories. Includes are resolved the same way the parser
+// resolves them. References to files that don't exist don't cause an error, they are
+// reported by `Missing`.
+func NewGraph(rootContext *context.Context) (*Graph, error) {
+	g := &Graph{
+		rootContext: rootContext,
+		edges:       map[string][]Edge{},
+		dependents:  map[string][]string{},
+	}
+
+	queue := []string{}
+	entries, err := os.ReadDir(rootContext.AssemblyDir())
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && path.Ext(entry.Name()) == ".ra" {
+			queue = append(queue, filepath.Join(rootContext.AssemblyDir(), entry.Name()))
+		}
+	}
+	for _, directory := range []string{rootContext.IncludesDir(), rootContext.ExcludesDir()} {
+		err := filepath.WalkDir(directory, func(filePath string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !dirEntry.IsDir() && path.Ext(filePath) == ".ra" {
+				queue = append(queue, filepath.Clean(filePath))
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	known := map[string]bool{}
+	for len(queue) > 0 {
+		filePath := queue[0]
+		queue = queue[1:]
+		if known[filePath] {
+			continue
+		}
+		known[filePath] = true
+		g.files = append(g.files, filePath)
+
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, directive := range includeDirectives(contents) {
+			target, err := Resolve(rootContext, directive.name)
+			if err != nil {
+				g.missing = append(g.missing, MissingInclude{From: filePath, Name: directive.name})
+				continue
+			}
+			g.edges[filePath] = append(g.edges[filePath], Edge{From: filePath, To: target, Kind: directive.kind})
+			if !slices.Contains(g.dependents[target], filePath) {
+				g.dependents[target] = append(g.dependents[target], filePath)
+			}
+			// Absolute includes may point outside of the known directories
+			queue = append(queue, target)
+		}
+	}
+	slices.Sort(g.files)
+	return g, nil
+}
+
+// Files returns the paths of all files in the graph, sorted.
+func (g *Graph) Files() []string {
+	return g.files
+}
+
+// Rules returns the paths of the regex-assembly files of rules, sorted.
+func (g *Graph) Rules() []string {
+	rules := []string{}
+	for _, filePath := range g.files {
+		if g.IsRule(filePath) {
+			rules = append(rules, filePath)
+		}
+	}
+	return rules
+}
+
+// IsRule returns whether the file at `filePath` is the regex-assembly file of a rule.
+func (g *Graph) IsRule(filePath string) bool {
+	return filepath.Dir(filePath) == filepath.Clean(g.rootContext.AssemblyDir()) &&
+		regex.RuleIdFileNameRegex.MatchString(filepath.Base(filePath))
+}
+
+// Edges returns the references of the file at `filePath`, in the order they appear in the file.
+func (g *Graph) Edges(filePath string) []Edge {
+	return g.edges[filePath]
+}
+
+// Missing returns all references to files that don't exist.
+func (g *Graph) Missing() []MissingInclude {
+	return g.missing
+}
+
+// Dependencies returns the paths of the files that the file at `filePath` references, directly
+// or transitively, in the order they are first referenced.
+func (g *Graph) Dependencies(filePath string) []string {
+	return g.walk(filePath, func(current string) []string {
+		targets := []string{}
+		for _, edge := range g.edges[current] {
+			targets = append(targets, edge.To)
+		}
+		return targets
+	})
+}
+
+// Dependents returns the paths of the files that reference the file at `filePath`, directly or
+// transitively, sorted.
+func (g *Graph) Dependents(filePath string) []string {
+	dependents := g.walk(filePath, func(current string) []string {
+		return g.dependents[current]
+	})
+	slices.Sort(dependents)
+	return dependents
+}
+
+// AffectedRules returns the paths of the regex-assembly files of the rules that are one of the
+// files at `filePaths` or depend on one of them, sorted.
+func (g *Graph) AffectedRules(filePaths ...string) []string {
+	affected := map[string]bool{}
+	for _, filePath := range filePaths {
+		filePath = filepath.Clean(filePath)
+		if g.IsRule(filePath) && slices.Contains(g.files, filePath) {
+			affected[filePath] = true
+		}
+		for _, dependent := range g.Dependents(filePath) {
+			if g.IsRule(dependent) {
+				affected[dependent] = true
+			}
+		}
+	}
+	rules := []string{}
+	for _, filePath := range g.files {
+		if affected[filePath] {
+			rules = append(rules, filePath)
+		}
+	}
+	return rules
+}
+
+// Subgraph returns the graph restricted to the files at `filePaths`. Only edges between these
+// files are retained.
+func (g *Graph) Subgraph(filePaths []string) *Graph {
+	selected := map[string]bool{}
+	for _, filePath := range filePaths {
+		selected[filePath] = true
+	}
+	subgraph := &Graph{
+		rootContext: g.rootContext,
+		edges:       map[string][]Edge{},
+		dependents:  map[string][]string{},
+	}
+	for _, filePath := range g.files {
+		if !selected[filePath] {
+			continue
+		}
+		subgraph.files = append(subgraph.files, filePath)
+		for _, edge := range g.edges[filePath] {
+			if selected[edge.To] {
+				subgraph.edges[filePath] = append(subgraph.edges[filePath], edge)
+			}
+		}
+		for _, dependent := range g.dependents[filePath] {
+			if selected[dependent] {
+				subgraph.dependents[filePath] = append(subgraph.dependents[filePath], dependent)
+			}
+		}
+	}
+	for _, missing := range g.missing {
+		if selected[missing.From] {
+			subgraph.missing = append(subgraph.missing, missing)
+		}
+	}
+	return subgraph
+}
+
+// walk returns all files reachable from `start` via `next`, excluding `start`, in breadth
+// first order.
+func (g *Graph) walk(start string, next func(string) []string) []string {
+	reached := []string{}
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, target := range next(current) {
+			if visited[target] {
+				continue
+			}
+			visited[target] = true
+			reached = append(reached, target)
+			queue = append(queue, target)
+		}
+	}
+	return reached
+}
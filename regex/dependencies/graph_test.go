@@ -0,0 +1,167 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencies
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+)
+
+type graphTestSuite struct {
+	suite.Suite
+	rootContext *context.Context
+	assemblyDir string
+	includeDir  string
+	excludeDir  string
+}
+
+func TestRunGraphTestSuite(t *testing.T) {
+	suite.Run(t, new(graphTestSuite))
+}
+
+func (s *graphTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.rootContext = context.New(rootDir, "toolchain.yaml")
+	s.assemblyDir = s.rootContext.AssemblyDir()
+	s.includeDir = s.rootContext.IncludesDir()
+	s.excludeDir = s.rootContext.ExcludesDir()
+	for _, directory := range []string{s.includeDir, s.excludeDir} {
+		err := os.MkdirAll(directory, fs.ModePerm)
+		s.Require().NoError(err)
+	}
+
+	s.writeFile(s.assemblyDir, "123456.ra", "##!> include shell\n")
+	s.writeFile(s.assemblyDir, "123457.ra", "##!> include-except words except\n")
+	s.writeFile(s.assemblyDir, "123458-chain1.ra", "foo\n")
+	s.writeFile(s.includeDir, "shell.ra", "##!> include words\n")
+	s.writeFile(s.includeDir, "words.ra", "foo\n")
+	s.writeFile(s.excludeDir, "except.ra", "bar\n")
+}
+
+func (s *graphTestSuite) writeFile(directory string, name string, contents string) {
+	err := os.WriteFile(path.Join(directory, name), []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+}
+
+func (s *graphTestSuite) TestNewGraph() {
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123456.ra"),
+		path.Join(s.assemblyDir, "123457.ra"),
+		path.Join(s.assemblyDir, "123458-chain1.ra"),
+		path.Join(s.excludeDir, "except.ra"),
+		path.Join(s.includeDir, "shell.ra"),
+		path.Join(s.includeDir, "words.ra"),
+	}, graph.Files())
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123456.ra"),
+		path.Join(s.assemblyDir, "123457.ra"),
+		path.Join(s.assemblyDir, "123458-chain1.ra"),
+	}, graph.Rules())
+	s.Equal([]Edge{
+		{From: path.Join(s.assemblyDir, "123457.ra"), To: path.Join(s.includeDir, "words.ra"), Kind: IncludeEdge},
+		{From: path.Join(s.assemblyDir, "123457.ra"), To: path.Join(s.excludeDir, "except.ra"), Kind: ExcludeEdge},
+	}, graph.Edges(path.Join(s.assemblyDir, "123457.ra")))
+	s.Empty(graph.Missing())
+}
+
+func (s *graphTestSuite) TestDependencies() {
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		path.Join(s.includeDir, "shell.ra"),
+		path.Join(s.includeDir, "words.ra"),
+	}, graph.Dependencies(path.Join(s.assemblyDir, "123456.ra")))
+	s.Empty(graph.Dependencies(path.Join(s.assemblyDir, "123458-chain1.ra")))
+}
+
+func (s *graphTestSuite) TestDependents() {
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123456.ra"),
+		path.Join(s.assemblyDir, "123457.ra"),
+		path.Join(s.includeDir, "shell.ra"),
+	}, graph.Dependents(path.Join(s.includeDir, "words.ra")))
+}
+
+func (s *graphTestSuite) TestAffectedRules() {
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123456.ra"),
+	}, graph.AffectedRules(path.Join(s.includeDir, "shell.ra")))
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123457.ra"),
+		path.Join(s.assemblyDir, "123458-chain1.ra"),
+	}, graph.AffectedRules(path.Join(s.excludeDir, "except.ra"), path.Join(s.assemblyDir, "123458-chain1.ra")))
+	s.Empty(graph.AffectedRules(path.Join(s.assemblyDir, "removed.ra")))
+}
+
+func (s *graphTestSuite) TestNewGraph_IndentedIncludeExcept() {
+	s.writeFile(s.assemblyDir, "123459.ra", "##!> assemble\n  ##!> include-except words except\n##!<\n")
+
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]Edge{
+		{From: path.Join(s.assemblyDir, "123459.ra"), To: path.Join(s.includeDir, "words.ra"), Kind: IncludeEdge},
+		{From: path.Join(s.assemblyDir, "123459.ra"), To: path.Join(s.excludeDir, "except.ra"), Kind: ExcludeEdge},
+	}, graph.Edges(path.Join(s.assemblyDir, "123459.ra")))
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123457.ra"),
+		path.Join(s.assemblyDir, "123459.ra"),
+	}, graph.AffectedRules(path.Join(s.excludeDir, "except.ra")))
+}
+
+func (s *graphTestSuite) TestCycle() {
+	s.writeFile(s.includeDir, "a.ra", "##!> include b\n")
+	s.writeFile(s.includeDir, "b.ra", "##!> include a\n")
+	s.writeFile(s.assemblyDir, "123459.ra", "##!> include a\n")
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		path.Join(s.includeDir, "a.ra"),
+		path.Join(s.includeDir, "b.ra"),
+	}, graph.Dependencies(path.Join(s.assemblyDir, "123459.ra")))
+	s.Equal([]string{
+		path.Join(s.assemblyDir, "123459.ra"),
+	}, graph.AffectedRules(path.Join(s.includeDir, "b.ra")))
+}
+
+func (s *graphTestSuite) TestMissing() {
+	s.writeFile(s.assemblyDir, "123459.ra", "##!> include shell\n##!> include missing\n")
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	s.Equal([]MissingInclude{{From: path.Join(s.assemblyDir, "123459.ra"), Name: "missing"}}, graph.Missing())
+	s.Equal([]string{
+		path.Join(s.includeDir, "shell.ra"),
+		path.Join(s.includeDir, "words.ra"),
+	}, graph.Dependencies(path.Join(s.assemblyDir, "123459.ra")))
+}
+
+func (s *graphTestSuite) TestSubgraph() {
+	graph, err := NewGraph(s.rootContext)
+	s.Require().NoError(err)
+
+	rule := path.Join(s.assemblyDir, "123457.ra")
+	subgraph := graph.Subgraph([]string{rule, path.Join(s.includeDir, "words.ra")})
+	s.Equal([]string{rule, path.Join(s.includeDir, "words.ra")}, subgraph.Files())
+	s.Equal([]Edge{
+		{From: rule, To: path.Join(s.includeDir, "words.ra"), Kind: IncludeEdge},
+	}, subgraph.Edges(rule))
+}
@@ -0,0 +1,420 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package equivalence decides whether two regular expressions are semantically equivalent, i.e.,
+// whether they match the same inputs. Like @rx, expressions match if they match anywhere in the
+// input. Both expressions are compiled to automata, which are explored in parallel, breadth first,
+// until an input is found that only one of them matches, so that the input found is as short
+// as possible.
+//
+// Expressions are parsed with the semantics of Go's regexp/syntax package (RE2 with Perl flags),
+// which is close to, but not the same as, PCRE: `$` only matches at the end of the input, not
+// before a final newline, and lookarounds, backreferences and other PCRE only syntax are
+// rejected.
+package equivalence
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxStates is the default limit for the number of state pairs that `Compare` explores.
+const DefaultMaxStates = 100000
+
+// ErrTooComplex is returned if the expressions can't be compared within the state limit.
+var ErrTooComplex = errors.New("expressions are too complex to compare")
+
+// Difference describes an input that only one of two expressions matches.
+type Difference struct {
+	Input string
+	// MatchedByFirst is true if the first expression matches the input, false if the second does
+	MatchedByFirst bool
+}
+
+// Compare returns nil if `first` and `second` match the same inputs, or the shortest input that
+// only one of them matches otherwise. Returns ErrTooComplex if more than `DefaultMaxStates` state
+// pairs have to be explored.
+func Compare(first string, second string) (*Difference, error) {
+	return CompareWithLimit(first, second, DefaultMaxStates)
+}
+
+// CompareWithLimit behaves like Compare but explores at most `maxStates` state pairs.
+func CompareWithLimit(first string, second string, maxStates int) (*Difference, error) {
+	firstProg, err := compile(first)
+	if err != nil {
+		return nil, err
+	}
+	secondProg, err := compile(second)
+	if err != nil {
+		return nil, err
+	}
+	alphabet := newAlphabet(firstProg, secondProg)
+	firstMachine := newMachine(firstProg, alphabet)
+	secondMachine := newMachine(secondProg, alphabet)
+
+	type pair struct{ first, second int }
+	type visit struct {
+		parent pair
+		class  int
+	}
+	start := pair{firstMachine.start(), secondMachine.start()}
+	visited := map[pair]visit{start: {class: -1}}
+	queue := []pair{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		firstAccepts := firstMachine.accepts(current.first)
+		if firstAccepts != secondMachine.accepts(current.second) {
+			classes := []int{}
+			for p := current; visited[p].class >= 0; p = visited[p].parent {
+				classes = append(classes, visited[p].class)
+			}
+			var sb strings.Builder
+			for i := len(classes) - 1; i >= 0; i-- {
+				sb.WriteRune(alphabet.representatives[classes[i]])
+			}
+			return &Difference{Input: sb.String(), MatchedByFirst: firstAccepts}, nil
+		}
+
+		for class := range alphabet.representatives {
+			next := pair{firstMachine.step(current.first, class), secondMachine.step(current.second, class)}
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			if len(visited) >= maxStates {
+				return nil, ErrTooComplex
+			}
+			visited[next] = visit{parent: current, class: class}
+			queue = append(queue, next)
+		}
+	}
+	return nil, nil
+}
+
+// compile parses `expression` with the Perl flags of regexp/syntax, the closest match of PCRE
+// without flags as used by @rx, and compiles it to a program without case folding, so that the alphabet can be derived from the
+// rune ranges of the program.
+func compile(expression string) (*syntax.Prog, error) {
+	re, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse regular expression: %w", err)
+	}
+	prog, err := syntax.Compile(unfoldCase(re).Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regular expression: %w", err)
+	}
+	return prog, nil
+}
+
+// unfoldCase replaces case insensitive literals with character classes of all the case
+// variants of their characters. Character classes are already case folded by the parser.
+func unfoldCase(re *syntax.Regexp) *syntax.Regexp {
+	for i, sub := range re.Sub {
+		re.Sub[i] = unfoldCase(sub)
+	}
+	if re.Op != syntax.OpLiteral || re.Flags&syntax.FoldCase == 0 {
+		return re
+	}
+	classes := make([]*syntax.Regexp, 0, len(re.Rune))
+	for _, r := range re.Rune {
+		class := []rune{r, r}
+		for folded := unicode.SimpleFold(r); folded != r; folded = unicode.SimpleFold(folded) {
+			class = append(class, folded, folded)
+		}
+		classes = append(classes, &syntax.Regexp{Op: syntax.OpCharClass, Flags: re.Flags &^ syntax.FoldCase, Rune: class})
+	}
+	if len(classes) == 1 {
+		return classes[0]
+	}
+	return &syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags &^ syntax.FoldCase, Sub: classes}
+}
+
+// alphabet partitions the runes into classes that no instruction of the compared programs, and
+// no empty-width assertion, can tell apart. Each class is represented by one of its runes.
+type alphabet struct {
+	representatives []rune
+}
+
+func newAlphabet(progs ...*syntax.Prog) *alphabet {
+	// Runes at which a new class starts. Newlines and ASCII word characters are distinguished
+	// by assertions, surrogates can't be part of a valid UTF-8 input.
+	boundaries := []rune{0, '\n', '\n' + 1, '0', '9' + 1, 'A', 'Z' + 1, '_', '_' + 1, 'a', 'z' + 1, 0xd800, 0xe000, unicode.MaxRune + 1}
+	for _, prog := range progs {
+		for _, inst := range prog.Inst {
+			switch inst.Op {
+			case syntax.InstRune:
+				for i := 0; i+1 < len(inst.Rune); i += 2 {
+					boundaries = append(boundaries, inst.Rune[i], inst.Rune[i+1]+1)
+				}
+				if len(inst.Rune) == 1 {
+					boundaries = append(boundaries, inst.Rune[0], inst.Rune[0]+1)
+				}
+			case syntax.InstRune1:
+				boundaries = append(boundaries, inst.Rune[0], inst.Rune[0]+1)
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	boundaries = slices.Compact(boundaries)
+
+	a := &alphabet{}
+	for i := 0; i+1 < len(boundaries); i++ {
+		low, high := boundaries[i], boundaries[i+1]-1
+		if low > unicode.MaxRune || (low >= 0xd800 && high < 0xe000) {
+			continue
+		}
+		a.representatives = append(a.representatives, representative(low, high))
+	}
+	// Inputs are built from the classes explored first, prefer readable ones
+	sort.SliceStable(a.representatives, func(i, j int) bool {
+		return readability(a.representatives[i]) < readability(a.representatives[j])
+	})
+	return a
+}
+
+// readability ranks runes by how readable they are in a distinguishing input, lower is better.
+func readability(r rune) int {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return 0
+	case r >= 'A' && r <= 'Z':
+		return 1
+	case r >= '0' && r <= '9':
+		return 2
+	case r >= ' ' && r < 0x7f:
+		return 3
+	case r < 0x80:
+		return 5
+	default:
+		return 4
+	}
+}
+
+// representative returns a rune of the range that is readable in a distinguishing input,
+// preferably a printable ASCII character.
+func representative(low rune, high rune) rune {
+	for _, preferred := range []rune{'a', '0', ' '} {
+		if low <= preferred && preferred <= high {
+			return preferred
+		}
+	}
+	for r := low; r <= high && r < 0x80; r++ {
+		if r >= ' ' && r < 0x7f {
+			return r
+		}
+	}
+	return low
+}
+
+// dfaState is a state of the lazily built deterministic automaton of a program. It is the set
+// of instructions waiting for the next rune, the context of the previous rune for empty-width
+// assertions, and whether the input already contains a match.
+type dfaState struct {
+	pcs      []uint32
+	previous rune
+	matched  bool
+	next     []int
+	accepts  *bool
+	// closures caches the closures of the state by the context of empty-width assertions
+	closures map[syntax.EmptyOp]*closure
+}
+
+// closure is the result of following all empty transitions from a set of instructions.
+type closure struct {
+	// pcs are the instructions that consume a rune
+	pcs     []uint32
+	matched bool
+	// targets are the instructions reached by reading a rune, by class
+	targets [][]uint32
+}
+
+// machine builds the deterministic automaton of a program on demand.
+type machine struct {
+	prog     *syntax.Prog
+	alphabet *alphabet
+	states   []*dfaState
+	index    map[string]int
+	visited  []bool
+	// classes are the classes of runes that each instruction consumes, computed on demand
+	classes [][]int
+}
+
+func newMachine(prog *syntax.Prog, alphabet *alphabet) *machine {
+	return &machine{
+		prog:     prog,
+		alphabet: alphabet,
+		index:    map[string]int{},
+		visited:  make([]bool, len(prog.Inst)),
+		classes:  make([][]int, len(prog.Inst)),
+	}
+}
+
+// start returns the initial state, before any rune was read.
+func (m *machine) start() int {
+	return m.state(nil, -1, false)
+}
+
+// state returns the index of the state, adding it if it doesn't exist yet.
+func (m *machine) state(pcs []uint32, previous rune, matched bool) int {
+	if matched {
+		// The input contains a match, no matter what follows
+		pcs = nil
+		previous = 0
+	}
+	key := make([]byte, 0, 4*len(pcs)+5)
+	if matched {
+		key = append(key, 1)
+	} else {
+		key = append(key, 0)
+	}
+	key = binary.LittleEndian.AppendUint32(key, uint32(previous))
+	for _, pc := range pcs {
+		key = binary.LittleEndian.AppendUint32(key, pc)
+	}
+	if index, ok := m.index[string(key)]; ok {
+		return index
+	}
+	next := make([]int, len(m.alphabet.representatives))
+	for i := range next {
+		next[i] = -1
+	}
+	m.states = append(m.states, &dfaState{
+		pcs:      pcs,
+		previous: previous,
+		matched:  matched,
+		next:     next,
+		closures: map[syntax.EmptyOp]*closure{},
+	})
+	m.index[string(key)] = len(m.states) - 1
+	return len(m.states) - 1
+}
+
+// step returns the state reached from state `index` by reading a rune of class `class`.
+func (m *machine) step(index int, class int) int {
+	s := m.states[index]
+	if s.next[class] >= 0 {
+		return s.next[class]
+	}
+	r := m.alphabet.representatives[class]
+	var next int
+	if s.matched {
+		next = index
+	} else {
+		c := m.closure(s, syntax.EmptyOpContext(s.previous, r))
+		next = m.state(m.targets(c)[class], contextRune(r), c.matched)
+	}
+	// `m.state` may have grown the slice of states, `s` is still valid as states are pointers
+	s.next[class] = next
+	return next
+}
+
+// accepts returns whether the input that leads to state `index` matches.
+func (m *machine) accepts(index int) bool {
+	s := m.states[index]
+	if s.accepts == nil {
+		matched := s.matched || m.closure(s, syntax.EmptyOpContext(s.previous, -1)).matched
+		s.accepts = &matched
+	}
+	return *s.accepts
+}
+
+// closure follows all empty transitions from the instructions of state `s`, and from the start of
+// the program, as a match can start at any position. `context` holds the empty-width assertions
+// that are satisfied at the current position.
+func (m *machine) closure(s *dfaState, context syntax.EmptyOp) *closure {
+	if c, ok := s.closures[context]; ok {
+		return c
+	}
+	clear(m.visited)
+	stack := append([]uint32{uint32(m.prog.Start)}, s.pcs...)
+	runePcs := []uint32{}
+	matched := false
+	for len(stack) > 0 {
+		pc := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if m.visited[pc] {
+			continue
+		}
+		m.visited[pc] = true
+		inst := &m.prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			stack = append(stack, inst.Out, inst.Arg)
+		case syntax.InstCapture, syntax.InstNop:
+			stack = append(stack, inst.Out)
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(inst.Arg)&^context == 0 {
+				stack = append(stack, inst.Out)
+			}
+		case syntax.InstMatch:
+			matched = true
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			runePcs = append(runePcs, pc)
+		}
+	}
+	c := &closure{pcs: runePcs, matched: matched}
+	s.closures[context] = c
+	return c
+}
+
+// targets returns the instructions reached from closure `c` by reading a rune, by class.
+func (m *machine) targets(c *closure) [][]uint32 {
+	if c.targets != nil {
+		return c.targets
+	}
+	c.targets = make([][]uint32, len(m.alphabet.representatives))
+	for _, pc := range c.pcs {
+		for _, class := range m.runeClasses(pc) {
+			c.targets[class] = append(c.targets[class], m.prog.Inst[pc].Out)
+		}
+	}
+	for class, pcs := range c.targets {
+		slices.Sort(pcs)
+		c.targets[class] = slices.Compact(pcs)
+	}
+	return c.targets
+}
+
+// runeClasses returns the classes of runes that the instruction at `pc` consumes.
+func (m *machine) runeClasses(pc uint32) []int {
+	if m.classes[pc] == nil {
+		m.classes[pc] = []int{}
+		for class, r := range m.alphabet.representatives {
+			if matchRune(&m.prog.Inst[pc], r) {
+				m.classes[pc] = append(m.classes[pc], class)
+			}
+		}
+	}
+	return m.classes[pc]
+}
+
+func matchRune(inst *syntax.Inst, r rune) bool {
+	switch inst.Op {
+	case syntax.InstRuneAny:
+		return true
+	case syntax.InstRuneAnyNotNL:
+		return r != '\n'
+	default:
+		return inst.MatchRune(r)
+	}
+}
+
+// contextRune returns a rune that behaves like `r` for all empty-width assertions, so that
+// states only differ in the previous rune if the difference matters.
+func contextRune(r rune) rune {
+	switch {
+	case r == '\n':
+		return '\n'
+	case syntax.IsWordChar(r):
+		return 'a'
+	default:
+		return ' '
+	}
+}
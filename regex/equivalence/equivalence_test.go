@@ -0,0 +1,93 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package equivalence
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type equivalenceTestSuite struct {
+	suite.Suite
+}
+
+func TestRunEquivalenceTestSuite(t *testing.T) {
+	suite.Run(t, new(equivalenceTestSuite))
+}
+
+func (s *equivalenceTestSuite) TestCompare_Equivalent() {
+	tests := [][2]string{
+		{"foo", "foo"},
+		{"foo|bar", "bar|foo"},
+		{"(?:foo|foobar)", "foo(?:bar)?"},
+		{"a[bc]d", "abd|acd"},
+		{"(?i)drop", "[dD][rR][oO][pP]"},
+		{"x+", "x"},
+		{"^ab", "^a(?:b)"},
+		{`\bfoo`, `(?:^|\W)foo`},
+		{"a.b", `a[^\n]b`},
+	}
+	for _, test := range tests {
+		difference, err := Compare(test[0], test[1])
+		s.Require().NoError(err)
+		s.Nil(difference, "%s and %s", test[0], test[1])
+	}
+}
+
+func (s *equivalenceTestSuite) TestCompare_FindsShortestDistinguishingInput() {
+	difference, err := Compare("foo|bar", "foo|baz")
+	s.Require().NoError(err)
+	s.Require().NotNil(difference)
+	s.Equal("bar", difference.Input)
+	s.True(difference.MatchedByFirst)
+
+	difference, err = Compare("a", "(?i)a")
+	s.Require().NoError(err)
+	s.Require().NotNil(difference)
+	s.Equal("A", difference.Input)
+	s.False(difference.MatchedByFirst)
+}
+
+func (s *equivalenceTestSuite) TestCompare_RespectsAnchors() {
+	difference, err := Compare("^foo", "foo")
+	s.Require().NoError(err)
+	s.Require().NotNil(difference)
+	s.Equal("afoo", difference.Input)
+	s.False(difference.MatchedByFirst)
+
+	difference, err = Compare(`foo\b`, "foo")
+	s.Require().NoError(err)
+	s.Require().NotNil(difference)
+	s.Equal("fooa", difference.Input)
+	s.False(difference.MatchedByFirst)
+}
+
+func (s *equivalenceTestSuite) TestCompare_InputsAgreeWithRegexp() {
+	tests := [][2]string{
+		{"[a-f]{2}x", "[a-e]{2}x"},
+		{`\d+\s*;`, `[0-9]+ ?;`},
+		{"(?:ab)*c$", "(?:ab)+c$"},
+	}
+	for _, test := range tests {
+		difference, err := Compare(test[0], test[1])
+		s.Require().NoError(err)
+		s.Require().NotNil(difference)
+		first := regexp.MustCompile(test[0]).MatchString(difference.Input)
+		second := regexp.MustCompile(test[1]).MatchString(difference.Input)
+		s.NotEqual(first, second, "input %q", difference.Input)
+		s.Equal(first, difference.MatchedByFirst)
+	}
+}
+
+func (s *equivalenceTestSuite) TestCompare_InvalidExpression() {
+	_, err := Compare("(?=foo)", "foo")
+	s.ErrorContains(err, "failed to parse regular expression")
+}
+
+func (s *equivalenceTestSuite) TestCompareWithLimit_TooComplex() {
+	_, err := CompareWithLimit("[ab]{20}", "[ab]{19}c?", 10)
+	s.ErrorIs(err, ErrTooComplex)
+}
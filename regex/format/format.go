@@ -0,0 +1,163 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package format formats regex-assembly files. It is used by the `regex format` command, the
+// language server and the crstoolchain package.
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+	"github.com/coreruleset/crs-toolchain/v2/regex/validation"
+)
+
+const (
+	RegexAssemblyStandardHeader = "##! Please refer to the documentation at\n##! https://coreruleset.org/docs/development/regex_assembly/.\n"
+)
+
+var logger = log.With().Str("component", "format").Logger()
+
+var blockStartRegex = regex.ProcessorBlockStartRegex
+var blockEndRegex = regex.ProcessorEndRegex
+var includeRegex = regex.IncludeRegex
+var includeExceptRegex = regex.IncludeExceptRegex
+var definitionRegex = regex.DefinitionRegex
+var prefixRegex = regex.PrefixRegex
+var suffixRegex = regex.SuffixRegex
+var flagsRegex = regex.FlagsRegex
+var outputRegex = regex.OutputRegex
+
+// FormatAssembly formats the regex-assembly contents read from `reader` and returns the
+// formatted lines. Joining the lines with newlines produces the formatted file contents.
+// The parser used to read the contents is returned so that callers can inspect the flags.
+func FormatAssembly(ctxt *processors.Context, filePath string, reader io.Reader) ([]string, *parser.Parser, error) {
+	filename := path.Base(filePath)
+	formatLogger := ctxt.Logger(&logger)
+	raParser := parser.NewParserForFile(ctxt, filePath, reader)
+	parsedBytesBuffer, err := raParser.Parse(true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	formatLogger.Trace().Msg("Validating input")
+	if err := validation.ValidateAll(bytes.NewReader(parsedBytesBuffer.Bytes())); err != nil {
+		return nil, nil, err
+	}
+	formatLogger.Trace().Msg("Successfully validated input")
+
+	scanner := bufio.NewScanner(parsedBytesBuffer)
+	lines := []string{}
+
+	indent := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		line, indent, err = processLine(line, indent)
+		if err != nil {
+			formatLogger.Error().Err(err).Msgf("failed to format %s", filename)
+		}
+		lines = append(lines, string(line))
+	}
+
+	if !checkStandardHeader(lines) {
+		formatLogger.Info().Msgf("file %s does not have standard header", filename)
+		// prepend the standard header
+		lines = append([]string{RegexAssemblyStandardHeader}, lines...)
+	}
+	return formatEndOfFile(lines), raParser, nil
+}
+
+func processLine(line []byte, indent int) ([]byte, int, error) {
+	trimmedLine := bytes.TrimLeft(line, " \t")
+	if len(trimmedLine) == 0 {
+		return trimmedLine, indent, nil
+	}
+
+	blockIndent := indent
+	nextIndent := indent
+	if matches := blockStartRegex.FindSubmatch(line); matches != nil {
+		newLine := fmt.Sprintf("##!> %s", matches[1])
+		if len(matches[2]) > 0 {
+			newLine += " " + string(matches[2])
+		}
+		trimmedLine = []byte(newLine)
+		blockIndent = indent
+		nextIndent = blockIndent + 1
+	} else if blockEndRegex.Match(line) {
+		if blockIndent == 0 {
+			return nil, 0, errors.New("unbalanced processor block")
+		}
+		blockIndent = indent - 1
+		nextIndent = blockIndent
+	} else if matches := flagsRegex.FindSubmatch(line); matches != nil {
+		trimmedLine = []byte(fmt.Sprintf("##!+ %s", matches[1]))
+		blockIndent = 0
+	} else if matches := prefixRegex.FindSubmatch(line); matches != nil {
+		trimmedLine = []byte(fmt.Sprintf("##!^ %s", matches[1]))
+		blockIndent = 0
+	} else if matches := suffixRegex.FindSubmatch(line); matches != nil {
+		trimmedLine = []byte(fmt.Sprintf("##!$ %s", matches[1]))
+		blockIndent = 0
+	} else if matches := outputRegex.FindSubmatch(line); matches != nil {
+		trimmedLine = []byte(fmt.Sprintf("##!> output %s", matches[1]))
+		blockIndent = 0
+	} else if matches := definitionRegex.FindSubmatch(line); matches != nil {
+		trimmedLine = []byte(fmt.Sprintf("##!> define %s %s", matches[2], matches[3]))
+	} else if matches := includeRegex.FindSubmatch(line); matches != nil {
+		trimmedLineString := fmt.Sprintf("##!> include %s", matches[1])
+		if len(matches[2]) > 0 {
+			trimmedLineString += fmt.Sprintf(" -- %s", matches[2])
+		}
+		trimmedLine = []byte(trimmedLineString)
+	} else if matches := includeExceptRegex.FindSubmatch(line); matches != nil {
+		trimmedLineString := fmt.Sprintf("##!> include-except %s %s", matches[1], matches[2])
+		if len(matches[3]) > 0 {
+			trimmedLineString += fmt.Sprintf(" -- %s", matches[3])
+		}
+		trimmedLine = []byte(trimmedLineString)
+	}
+
+	adjustment := bytes.Repeat([]byte(" "), blockIndent*2)
+	trimmedLine = append(adjustment, trimmedLine...)
+
+	return trimmedLine, nextIndent, nil
+}
+
+func formatEndOfFile(lines []string) []string {
+	eof := len(lines) - 1
+	if eof < 0 {
+		// Lines will be joined with newlines, so
+		// two empty lines will result in a single
+		// newline character
+		return append(lines, "", "")
+	}
+
+	for i := eof; i >= 0; i-- {
+		line := lines[i]
+		if line == "" {
+			eof--
+		} else {
+			break
+		}
+	}
+	// Append a single empty line, which will be joined
+	// to the others by newline
+	return append(lines[:eof+1], "")
+}
+
+func checkStandardHeader(lines []string) bool {
+	if len(lines) >= 3 &&
+		fmt.Sprintf("%s\n%s\n%s", lines[0], lines[1], lines[2]) == RegexAssemblyStandardHeader {
+		return true
+	}
+	return false
+}
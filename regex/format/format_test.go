@@ -0,0 +1,64 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type formatTestSuite struct {
+	suite.Suite
+	ctx *processors.Context
+}
+
+func (s *formatTestSuite) SetupTest() {
+	s.ctx = processors.NewContext(context.New(s.T().TempDir(), "toolchain.yaml"))
+}
+
+func TestRunFormatTestSuite(t *testing.T) {
+	suite.Run(t, new(formatTestSuite))
+}
+
+func (s *formatTestSuite) TestFormatAssembly() {
+	lines, raParser, err := FormatAssembly(s.ctx, "123456.ra", strings.NewReader(`##!+   i
+##!>assemble
+homer
+	##!=>
+##!<
+
+
+`))
+	s.Require().NoError(err)
+	s.True(raParser.Flags['i'])
+	s.Equal(RegexAssemblyStandardHeader+`
+##!+ i
+##!> assemble
+  homer
+  ##!=>
+##!<
+`, strings.Join(lines, "\n"))
+}
+
+func (s *formatTestSuite) TestFormatAssembly_KeepsStandardHeader() {
+	lines, _, err := FormatAssembly(s.ctx, "123456.ra", strings.NewReader(RegexAssemblyStandardHeader+"\nhomer"))
+	s.Require().NoError(err)
+	s.Equal(RegexAssemblyStandardHeader+"\nhomer\n", strings.Join(lines, "\n"))
+}
+
+func (s *formatTestSuite) TestFormatAssembly_Empty() {
+	lines, _, err := FormatAssembly(s.ctx, "123456.ra", strings.NewReader(""))
+	s.Require().NoError(err)
+	s.Equal(RegexAssemblyStandardHeader+"\n", strings.Join(lines, "\n"))
+}
+
+func (s *formatTestSuite) TestFormatAssembly_InvalidInput() {
+	_, _, err := FormatAssembly(s.ctx, "123456.ra", strings.NewReader("homer\n[äb]\n"))
+	s.Error(err)
+}
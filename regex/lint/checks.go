@@ -0,0 +1,307 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+)
+
+// DuplicateLineCheck reports lines that appear more than once in the same block. Stash
+// directives start a new group of lines, as the lines before and after them are assembled
+// separately.
+var DuplicateLineCheck = &Check{
+	Name:        "duplicate-line",
+	Description: "lines that appear more than once in the same block",
+	Run: func(pass *Pass) {
+		checkDuplicateLines(pass, pass.File.Nodes)
+	},
+}
+
+// EmptyBlockCheck reports processor blocks without any content. Comments and test vectors are
+// not content.
+var EmptyBlockCheck = &Check{
+	Name:        "empty-block",
+	Description: "processor blocks without content",
+	Run: func(pass *Pass) {
+		pass.File.Inspect(func(node ast.Node) bool {
+			block, ok := node.(*ast.Block)
+			if !ok {
+				return true
+			}
+			empty := true
+			for _, child := range block.Body {
+				switch child.(type) {
+				case *ast.Empty, *ast.Comment, *ast.TestVector:
+				default:
+					empty = false
+				}
+			}
+			if empty {
+				pass.Report(startPosition(&block.Start), "empty %s block", block.Processor.Value)
+			}
+			return true
+		})
+	},
+}
+
+// UndefinedReferenceCheck reports references to definitions ({{name}}) that are not defined in
+// the file.
+var UndefinedReferenceCheck = &Check{
+	Name:        "undefined-reference",
+	Description: "references to definitions that don't exist",
+	Run: func(pass *Pass) {
+		defined := map[string]bool{}
+		for _, definition := range definitions(pass.File) {
+			defined[definition.Name.Value] = true
+		}
+		for _, reference := range references(pass.File) {
+			if !defined[reference.Value] {
+				pass.Report(reference.Position, "no definition found for {{%s}}", reference.Value)
+			}
+		}
+	},
+}
+
+// UnescapedDotCheck reports unescaped dots outside of character classes. Lines in cmdline
+// blocks are not checked, as the cmdline processor already rejects unescaped dots.
+var UnescapedDotCheck = &Check{
+	Name:        "unescaped-dot",
+	Description: "unescaped dots outside of character classes and cmdline blocks",
+	Run: func(pass *Pass) {
+		for _, expression := range expressions(pass.File, true) {
+			for _, index := range findUnescapedDots(expression.Value) {
+				pass.Report(advance(expression.Position, index),
+					"unescaped '.' matches any character, use '\\.' to match a dot")
+			}
+		}
+	},
+}
+
+// UnusedDefinitionCheck reports definitions that are never referenced in the file.
+var UnusedDefinitionCheck = &Check{
+	Name:        "unused-definition",
+	Description: "definitions that are never referenced",
+	Run: func(pass *Pass) {
+		referenced := map[string]bool{}
+		for _, reference := range references(pass.File) {
+			referenced[reference.Value] = true
+		}
+		for _, definition := range definitions(pass.File) {
+			if !referenced[definition.Name.Value] {
+				pass.Report(definition.Name.Position, "definition %s is never used", definition.Name.Value)
+			}
+		}
+	},
+}
+
+// UnusedStashCheck reports stash names that are stored (##!=< name) but never read (##!=> name).
+var UnusedStashCheck = &Check{
+	Name:        "unused-stash",
+	Description: "stored expressions that are never used",
+	Run: func(pass *Pass) {
+		inputs := []*ast.StashInput{}
+		read := map[string]bool{}
+		pass.File.Inspect(func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.StashInput:
+				inputs = append(inputs, n)
+			case *ast.StashOutput:
+				read[n.Name.Value] = true
+			}
+			return true
+		})
+		for _, input := range inputs {
+			if input.Name.Value != "" && !read[input.Name.Value] {
+				pass.Report(input.Name.Position, "stored expression %s is never used", input.Name.Value)
+			}
+		}
+	},
+}
+
+// UppercaseIgnoreCaseCheck reports uppercase letters in files with the ignore-case flag. The
+// uppercase letters are redundant and suggest that the author did not expect the flag.
+var UppercaseIgnoreCaseCheck = &Check{
+	Name:        "uppercase-ignore-case",
+	Description: "uppercase letters in files with the ignore-case flag",
+	Run: func(pass *Pass) {
+		ignoreCase := false
+		pass.File.Inspect(func(node ast.Node) bool {
+			if flags, ok := node.(*ast.Flags); ok && strings.ContainsRune(flags.Flags.Value, 'i') {
+				ignoreCase = true
+			}
+			return true
+		})
+		if !ignoreCase {
+			return
+		}
+		for _, expression := range expressions(pass.File, false) {
+			if index := findUppercase(expression.Value); index >= 0 {
+				pass.Report(advance(expression.Position, index),
+					"uppercase letter '%c' is redundant with the ignore-case flag", expression.Value[index])
+			}
+		}
+	},
+}
+
+func checkDuplicateLines(pass *Pass, nodes []ast.Node) {
+	seen := map[string]int{}
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.Pattern:
+			if line, found := seen[n.Value]; found {
+				pass.Report(startPosition(&n.Line), "duplicate line, first seen on line %d", line)
+			} else {
+				seen[n.Value] = n.Pos().Line
+			}
+		case *ast.StashInput, *ast.StashOutput:
+			seen = map[string]int{}
+		case *ast.Block:
+			checkDuplicateLines(pass, n.Body)
+		}
+	}
+}
+
+// definitions returns all definitions of the file.
+func definitions(file *ast.File) []*ast.Define {
+	defines := []*ast.Define{}
+	file.Inspect(func(node ast.Node) bool {
+		if define, ok := node.(*ast.Define); ok {
+			defines = append(defines, define)
+		}
+		return true
+	})
+	return defines
+}
+
+// references returns the names of all definitions referenced in the file. The position of
+// each reference is the position of its opening braces.
+func references(file *ast.File) []ast.Argument {
+	values := expressions(file, false)
+	file.Inspect(func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Prefix:
+			values = append(values, n.Value)
+		case *ast.Suffix:
+			values = append(values, n.Value)
+		}
+		return true
+	})
+
+	names := []ast.Argument{}
+	for _, value := range values {
+		for _, match := range regex.DefinitionReferenceRegex.FindAllStringSubmatchIndex(value.Value, -1) {
+			names = append(names, ast.Argument{
+				Position: advance(value.Position, match[0]),
+				Value:    value.Value[match[2]:match[3]],
+			})
+		}
+	}
+	return names
+}
+
+// expressions returns the values of all patterns and definitions of the file, i.e., all the
+// lines that contribute to the generated regular expression. If `skipCmdline` is set, the
+// contents of cmdline blocks are skipped.
+func expressions(file *ast.File, skipCmdline bool) []ast.Argument {
+	values := []ast.Argument{}
+	file.Inspect(func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Block:
+			return !skipCmdline || n.Processor.Value != "cmdline"
+		case *ast.Pattern:
+			values = append(values, ast.Argument{Position: startPosition(&n.Line), Value: n.Value})
+		case *ast.Define:
+			values = append(values, n.Value)
+		}
+		return true
+	})
+	return values
+}
+
+// findUnescapedDots returns the indices of all dots in `value` that are neither escaped nor
+// part of a character class.
+func findUnescapedDots(value string) []int {
+	indices := []int{}
+	inClass := false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '[':
+			if inClass {
+				// Skip named classes, e.g., [:alpha:]
+				if strings.HasPrefix(value[i:], "[:") {
+					if end := strings.Index(value[i:], ":]"); end > 0 {
+						i += end + 1
+					}
+				}
+				continue
+			}
+			inClass = true
+			// A closing bracket at the start of a class is a literal
+			if strings.HasPrefix(value[i+1:], "^") {
+				i++
+			}
+			if strings.HasPrefix(value[i+1:], "]") {
+				i++
+			}
+		case ']':
+			inClass = false
+		case '.':
+			if !inClass {
+				indices = append(indices, i)
+			}
+		}
+	}
+	return indices
+}
+
+// findUppercase returns the index of the first uppercase letter in `value` that is not part of
+// an escape sequence (e.g., \W, \p{Lu}), a named group or a reference to a definition.
+// Returns -1 if there is no such letter.
+func findUppercase(value string) int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\':
+			i++
+			if i < len(value) && (value[i] == 'p' || value[i] == 'P') {
+				if strings.HasPrefix(value[i+1:], "{") {
+					if end := strings.IndexByte(value[i:], '}'); end > 0 {
+						i += end
+					}
+				} else {
+					i++
+				}
+			}
+		case strings.HasPrefix(value[i:], "(?P<"):
+			if end := strings.IndexByte(value[i:], '>'); end > 0 {
+				i += end
+			}
+		case strings.HasPrefix(value[i:], "{{"):
+			if end := strings.Index(value[i:], "}}"); end > 0 {
+				i += end + 1
+			}
+		case value[i] >= 'A' && value[i] <= 'Z':
+			return i
+		}
+	}
+	return -1
+}
+
+// startPosition returns the position of the first character of `line` after the indentation.
+func startPosition(line *ast.Line) ast.Position {
+	return advance(line.Position, len(line.Indent()))
+}
+
+// advance returns the position `count` bytes after `position` on the same line.
+func advance(position ast.Position, count int) ast.Position {
+	return ast.Position{
+		Offset: position.Offset + count,
+		Line:   position.Line,
+		Column: position.Column + count,
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+)
+
+type checksTestSuite struct {
+	suite.Suite
+}
+
+func TestRunChecksTestSuite(t *testing.T) {
+	suite.Run(t, new(checksTestSuite))
+}
+
+// lint runs `check` on `contents` and returns the messages of the problems, prefixed with their position
+func (s *checksTestSuite) lint(check *Check, contents string) []string {
+	file, err := ast.Parse("", []byte(contents))
+	s.Require().NoError(err)
+	messages := []string{}
+	for _, problem := range Lint(file, nil, []*Check{check}) {
+		messages = append(messages, fmt.Sprintf("%d:%d: %s [%s]", problem.Line, problem.Column, problem.Message, problem.Check))
+	}
+	return messages
+}
+
+func (s *checksTestSuite) TestDuplicateLine() {
+	contents := `foo
+bar
+foo
+##!> assemble
+  foo
+  baz
+  ##!=>
+  baz
+  baz
+##!<
+`
+	s.Equal([]string{
+		"3:1: duplicate line, first seen on line 1 [duplicate-line]",
+		"9:3: duplicate line, first seen on line 8 [duplicate-line]",
+	}, s.lint(DuplicateLineCheck, contents))
+}
+
+func (s *checksTestSuite) TestEmptyBlock() {
+	contents := "##!> assemble\n  ##! comment\n\n##!<\n##!> cmdline unix\n  foo\n##!<\n  ##!> assemble\n  ##!<\n"
+	s.Equal([]string{
+		"1:1: empty assemble block [empty-block]",
+		"8:3: empty assemble block [empty-block]",
+	}, s.lint(EmptyBlockCheck, contents))
+}
+
+func (s *checksTestSuite) TestUndefinedReference() {
+	contents := "##!> define a foo\n{{a}}{{b}}\n##!^ {{c}}\n##!> define d {{e}}\n"
+	s.Equal([]string{
+		"2:6: no definition found for {{b}} [undefined-reference]",
+		"3:6: no definition found for {{c}} [undefined-reference]",
+		"4:15: no definition found for {{e}} [undefined-reference]",
+	}, s.lint(UndefinedReferenceCheck, contents))
+}
+
+func (s *checksTestSuite) TestUnusedDefinition() {
+	contents := "##!> define a foo\n##!> define b bar\n##!> define c {{b}}\n##!$ {{c}}\n"
+	s.Equal([]string{
+		"1:13: definition a is never used [unused-definition]",
+	}, s.lint(UnusedDefinitionCheck, contents))
+}
+
+func (s *checksTestSuite) TestUnescapedDot() {
+	contents := `a.b
+a\.b[.]
+[].][^].]x.
+[[:alpha:].]
+##!> define a .
+##!> cmdline unix
+  a.b
+##!<
+`
+	s.Equal([]string{
+		"1:2: unescaped '.' matches any character, use '\\.' to match a dot [unescaped-dot]",
+		"3:11: unescaped '.' matches any character, use '\\.' to match a dot [unescaped-dot]",
+		"5:15: unescaped '.' matches any character, use '\\.' to match a dot [unescaped-dot]",
+	}, s.lint(UnescapedDotCheck, contents))
+}
+
+func (s *checksTestSuite) TestUnusedStash() {
+	contents := `##!> assemble
+  foo
+  ##!=< used
+  bar
+  ##!=< unused
+  ##!=> used
+##!<
+`
+	s.Equal([]string{
+		"5:9: stored expression unused is never used [unused-stash]",
+	}, s.lint(UnusedStashCheck, contents))
+}
+
+func (s *checksTestSuite) TestUppercaseIgnoreCase() {
+	contents := `##!+ i
+##!> define Name [a-Z]
+foo\W\p{Lu}\pL(?P<Name>x){{Name}}
+  fOo
+`
+	s.Equal([]string{
+		"2:21: uppercase letter 'Z' is redundant with the ignore-case flag [uppercase-ignore-case]",
+		"4:4: uppercase letter 'O' is redundant with the ignore-case flag [uppercase-ignore-case]",
+	}, s.lint(UppercaseIgnoreCaseCheck, contents))
+}
+
+func (s *checksTestSuite) TestUppercaseWithoutIgnoreCase() {
+	s.Empty(s.lint(UppercaseIgnoreCaseCheck, "FOO\n"))
+}
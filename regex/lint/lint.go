@@ -0,0 +1,121 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lint implements checks for common mistakes in regex-assembly files. Checks work on the
+// syntax tree of a single file (see package ast). The built-in checks are returned by `Checks`,
+// custom checks can be run alongside them by passing them to `Lint`.
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+// Problem is a problem found by a check.
+type Problem struct {
+	Check   string `json:"check"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s [%s]", p.File, p.Line, p.Column, p.Message, p.Check)
+}
+
+// Check is a single lint check. `Run` inspects the file of the pass and reports problems
+// through the pass.
+type Check struct {
+	// Name identifies the check when enabling or disabling it, and in reports.
+	Name        string
+	Description string
+	Run         func(pass *Pass)
+}
+
+// Pass is a single run of a check on a file.
+type Pass struct {
+	File *ast.File
+	// Context is used by checks that need to run processors. It may be nil, in which case
+	// these checks skip the affected parts of the file.
+	Context  *processors.Context
+	check    *Check
+	problems []Problem
+}
+
+// Report records a problem at `position`.
+func (p *Pass) Report(position ast.Position, format string, args ...any) {
+	p.problems = append(p.problems, Problem{
+		Check:   p.check.Name,
+		File:    p.File.Name,
+		Line:    position.Line,
+		Column:  position.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Checks returns the built-in checks, sorted by name.
+func Checks() []*Check {
+	return []*Check{
+		DuplicateLineCheck,
+		EmptyBlockCheck,
+		RedundantAlternativeCheck,
+		UndefinedReferenceCheck,
+		UnescapedDotCheck,
+		UnusedDefinitionCheck,
+		UnusedStashCheck,
+		UppercaseIgnoreCaseCheck,
+	}
+}
+
+// Select returns the checks from `checks` to run. If `enable` is not empty, only the named
+// checks are selected, otherwise all of them. Checks named in `disable` are never selected.
+// Unknown names are an error.
+func Select(checks []*Check, enable []string, disable []string) ([]*Check, error) {
+	for _, name := range slices.Concat(enable, disable) {
+		if !slices.ContainsFunc(checks, func(check *Check) bool { return check.Name == name }) {
+			return nil, fmt.Errorf("unknown check '%s'", name)
+		}
+	}
+	selected := []*Check{}
+	for _, check := range checks {
+		if len(enable) > 0 && !slices.Contains(enable, check.Name) {
+			continue
+		}
+		if slices.Contains(disable, check.Name) {
+			continue
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
+
+// Lint runs `checks` on `file` and returns the problems found, ordered by position.
+// `ctx` is passed to the checks, see `Pass`.
+func Lint(file *ast.File, ctx *processors.Context, checks []*Check) []Problem {
+	problems := []Problem{}
+	for _, check := range checks {
+		pass := &Pass{File: file, Context: ctx, check: check}
+		check.Run(pass)
+		problems = append(problems, pass.problems...)
+	}
+	SortProblems(problems)
+	return problems
+}
+
+// SortProblems sorts problems by file and position. Problems at the same position are sorted by
+// the name of the check.
+func SortProblems(problems []Problem) {
+	slices.SortStableFunc(problems, func(a Problem, b Problem) int {
+		return cmp.Or(
+			strings.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			strings.Compare(a.Check, b.Check))
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+)
+
+type lintTestSuite struct {
+	suite.Suite
+}
+
+func TestRunLintTestSuite(t *testing.T) {
+	suite.Run(t, new(lintTestSuite))
+}
+
+func (s *lintTestSuite) TestSelect_All() {
+	checks, err := Select(Checks(), nil, nil)
+	s.Require().NoError(err)
+	s.Equal(Checks(), checks)
+}
+
+func (s *lintTestSuite) TestSelect_Enable() {
+	checks, err := Select(Checks(), []string{"unescaped-dot", "duplicate-line"}, nil)
+	s.Require().NoError(err)
+	s.Equal([]*Check{DuplicateLineCheck, UnescapedDotCheck}, checks)
+}
+
+func (s *lintTestSuite) TestSelect_Disable() {
+	checks, err := Select(Checks(), nil, []string{"unescaped-dot"})
+	s.Require().NoError(err)
+	s.Len(checks, len(Checks())-1)
+	s.NotContains(checks, UnescapedDotCheck)
+}
+
+func (s *lintTestSuite) TestSelect_UnknownCheck() {
+	_, err := Select(Checks(), nil, []string{"does-not-exist"})
+	s.EqualError(err, "unknown check 'does-not-exist'")
+}
+
+func (s *lintTestSuite) TestLint_CustomCheckAndOrder() {
+	custom := &Check{
+		Name: "custom",
+		Run: func(pass *Pass) {
+			pass.File.Inspect(func(node ast.Node) bool {
+				if pattern, ok := node.(*ast.Pattern); ok && pattern.Value == "bad" {
+					pass.Report(pattern.Pos(), "bad line")
+				}
+				return true
+			})
+		},
+	}
+	file, err := ast.Parse("123456.ra", []byte("bad\nfoo\nbad\n"))
+	s.Require().NoError(err)
+
+	problems := Lint(file, nil, []*Check{custom, DuplicateLineCheck})
+	s.Equal([]Problem{
+		{Check: "custom", File: "123456.ra", Line: 1, Column: 1, Message: "bad line"},
+		{Check: "custom", File: "123456.ra", Line: 3, Column: 1, Message: "bad line"},
+		{Check: "duplicate-line", File: "123456.ra", Line: 3, Column: 1, Message: "duplicate line, first seen on line 1"},
+	}, problems)
+	s.Equal("123456.ra:3:1: bad line [custom]", problems[1].String())
+}
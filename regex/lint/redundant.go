@@ -0,0 +1,390 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+// maxEnumeratedStrings is the maximum number of strings of a finite language that are
+// enumerated to compare it to another language.
+const maxEnumeratedStrings = 256
+
+// RedundantAlternativeCheck reports lines that only match what other lines of the same
+// alternation match already. The generated expression is used for searching, so a line is
+// redundant if every string it matches contains a match of another line, e.g., `cats` is
+// redundant next to `cat`, and `a` is redundant next to `[a-z]`. Lines in cmdline blocks are
+// compared after expansion, which requires a context.
+//
+// The languages of two lines are compared structurally, and by enumerating the strings of lines
+// with small, finite languages. Redundant lines may therefore go unreported, but reported lines
+// are always redundant.
+var RedundantAlternativeCheck = &Check{
+	Name:        "redundant-alternative",
+	Description: "lines that are already matched by other lines of the same alternation",
+	Run: func(pass *Pass) {
+		definitions := map[string]string{}
+		flags := syntax.Perl
+		pass.File.Inspect(func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.Define:
+				definitions[n.Name.Value] = n.Value.Value
+			case *ast.Flags:
+				if strings.ContainsRune(n.Flags.Value, 'i') {
+					flags |= syntax.FoldCase
+				}
+				if strings.ContainsRune(n.Flags.Value, 's') {
+					flags |= syntax.DotNL
+				}
+			}
+			return true
+		})
+		checker := &redundancyChecker{
+			pass:        pass,
+			definitions: definitions,
+			flags:       flags,
+		}
+		checker.checkNodes(pass.File.Nodes, nil)
+	},
+}
+
+type redundancyChecker struct {
+	pass        *Pass
+	definitions map[string]string
+	flags       syntax.Flags
+}
+
+// alternative is a line of an alternation, prepared for comparison.
+type alternative struct {
+	pattern *ast.Pattern
+	// atoms is the sequence of subexpressions that are concatenated by the expression, with
+	// literals split into single characters
+	atoms []*syntax.Regexp
+	// strings is the language of the expression, nil if it is infinite or too large
+	strings []string
+	// matcher searches for the expression, nil if the expression contains empty-width assertions
+	matcher *regexp.Regexp
+}
+
+// checkNodes checks the alternations formed by `nodes` and the blocks within them.
+// `cmdLine` is the processor used to expand lines, or nil outside of cmdline blocks.
+func (c *redundancyChecker) checkNodes(nodes []ast.Node, cmdLine *processors.CmdLine) {
+	group := []*alternative{}
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.Pattern:
+			if alt := c.newAlternative(n, cmdLine); alt != nil {
+				group = append(group, alt)
+			}
+		case *ast.StashInput, *ast.StashOutput:
+			// The lines before and after stash directives are assembled separately
+			c.checkGroup(group)
+			group = []*alternative{}
+		case *ast.Block:
+			var blockCmdLine *processors.CmdLine
+			if n.Processor.Value == "cmdline" {
+				cmdType, err := processors.CmdLineTypeFromString(n.Argument.Value)
+				if err != nil || c.pass.Context == nil {
+					continue
+				}
+				blockCmdLine = processors.NewCmdLine(c.pass.Context, cmdType)
+			}
+			c.checkNodes(n.Body, blockCmdLine)
+		}
+	}
+	c.checkGroup(group)
+}
+
+func (c *redundancyChecker) checkGroup(group []*alternative) {
+	for j, alt := range group {
+		for i, other := range group {
+			if i == j || other.pattern.Value == alt.pattern.Value {
+				// Identical lines are reported by the duplicate-line check
+				continue
+			}
+			if !covers(other, alt) {
+				continue
+			}
+			if i > j && covers(alt, other) {
+				// Both lines match the same, only report the second one
+				continue
+			}
+			c.pass.Report(startPosition(&alt.pattern.Line),
+				"redundant alternative, already matched by line %d (%s)", other.pattern.Pos().Line, other.pattern.Value)
+			break
+		}
+	}
+}
+
+// newAlternative prepares `pattern` for comparison. Returns nil if the expression can't be
+// parsed or refers to definitions that don't exist.
+func (c *redundancyChecker) newAlternative(pattern *ast.Pattern, cmdLine *processors.CmdLine) *alternative {
+	expression, ok := expandDefinitions(pattern.Value, c.definitions)
+	if !ok {
+		return nil
+	}
+	if cmdLine != nil {
+		expression = cmdLine.Expand(expression)
+	}
+	re, err := syntax.Parse(expression, c.flags)
+	if err != nil {
+		return nil
+	}
+	re = re.Simplify()
+
+	alt := &alternative{
+		pattern: pattern,
+		atoms:   atoms(re),
+	}
+	if strings, ok := enumerate(re, maxEnumeratedStrings); ok {
+		alt.strings = strings
+	}
+	if !hasAssertions(re) {
+		// The parsed expression is equivalent to the source, including flags
+		alt.matcher, err = regexp.Compile(re.String())
+		if err != nil {
+			return nil
+		}
+	}
+	return alt
+}
+
+// covers returns whether every string matched by `alt` contains a match of `other`.
+func covers(other *alternative, alt *alternative) bool {
+	if containsAtoms(alt.atoms, other.atoms) {
+		return true
+	}
+	// Without assertions, a match of `other` in a string of `alt` is a match in any string
+	// that contains the string of `alt`
+	if alt.strings == nil || other.matcher == nil {
+		return false
+	}
+	for _, s := range alt.strings {
+		if !other.matcher.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAtoms returns whether `atoms` contains a contiguous sequence of atoms, each of which
+// matches a subset of what the corresponding atom of `sequence` matches.
+func containsAtoms(atoms []*syntax.Regexp, sequence []*syntax.Regexp) bool {
+	for start := 0; start+len(sequence) <= len(atoms); start++ {
+		found := true
+		for i, atom := range sequence {
+			if !atomCovers(atom, atoms[start+i]) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// atomCovers returns whether `atom` matches everything `other` matches.
+func atomCovers(atom *syntax.Regexp, other *syntax.Regexp) bool {
+	if atom.Equal(other) {
+		return true
+	}
+	atomRanges, ok := characterRanges(atom)
+	if !ok {
+		return false
+	}
+	otherRanges, ok := characterRanges(other)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(otherRanges); i += 2 {
+		if !rangeCovered(atomRanges, otherRanges[i], otherRanges[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// characterRanges returns the characters matched by an expression that matches a single
+// character, as sorted pairs of inclusive bounds. Returns false for other expressions.
+func characterRanges(re *syntax.Regexp) ([]rune, bool) {
+	switch re.Op {
+	case syntax.OpCharClass:
+		return re.Rune, true
+	case syntax.OpAnyChar:
+		return []rune{0, unicode.MaxRune}, true
+	case syntax.OpAnyCharNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}, true
+	case syntax.OpLiteral:
+		if len(re.Rune) != 1 {
+			return nil, false
+		}
+		runes := []rune{re.Rune[0]}
+		if re.Flags&syntax.FoldCase != 0 {
+			for folded := unicode.SimpleFold(re.Rune[0]); folded != re.Rune[0]; folded = unicode.SimpleFold(folded) {
+				runes = append(runes, folded)
+			}
+		}
+		slices.Sort(runes)
+		ranges := make([]rune, 0, 2*len(runes))
+		for _, r := range runes {
+			ranges = append(ranges, r, r)
+		}
+		return ranges, true
+	default:
+		return nil, false
+	}
+}
+
+// rangeCovered returns whether all characters from `low` to `high` are in `ranges`.
+func rangeCovered(ranges []rune, low rune, high rune) bool {
+	for i := 0; i < len(ranges) && low <= high; i += 2 {
+		if ranges[i] <= low && low <= ranges[i+1] {
+			low = ranges[i+1] + 1
+		}
+	}
+	return low > high
+}
+
+// atoms returns the subexpressions concatenated by `re`, with literals split into single
+// characters.
+func atoms(re *syntax.Regexp) []*syntax.Regexp {
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	result := []*syntax.Regexp{}
+	for _, sub := range subs {
+		if sub.Op != syntax.OpLiteral {
+			result = append(result, sub)
+			continue
+		}
+		for _, r := range sub.Rune {
+			result = append(result, &syntax.Regexp{Op: syntax.OpLiteral, Flags: sub.Flags, Rune: []rune{r}})
+		}
+	}
+	return result
+}
+
+// enumerate returns all strings matched by `re` if there are no more than `limit` of them.
+func enumerate(re *syntax.Regexp, limit int) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return []string{""}, true
+	case syntax.OpLiteral:
+		result := []string{""}
+		for _, r := range re.Rune {
+			characters := []rune{r}
+			if re.Flags&syntax.FoldCase != 0 {
+				for folded := unicode.SimpleFold(r); folded != r; folded = unicode.SimpleFold(folded) {
+					characters = append(characters, folded)
+				}
+			}
+			alternatives := make([]string, 0, len(characters))
+			for _, character := range characters {
+				alternatives = append(alternatives, string(character))
+			}
+			var ok bool
+			if result, ok = product(result, alternatives, limit); !ok {
+				return nil, false
+			}
+		}
+		return result, true
+	case syntax.OpCharClass:
+		result := []string{}
+		for i := 0; i < len(re.Rune); i += 2 {
+			if int(re.Rune[i+1]-re.Rune[i])+len(result) >= limit {
+				return nil, false
+			}
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				result = append(result, string(r))
+			}
+		}
+		return result, true
+	case syntax.OpCapture:
+		return enumerate(re.Sub[0], limit)
+	case syntax.OpQuest:
+		result, ok := enumerate(re.Sub[0], limit-1)
+		if !ok {
+			return nil, false
+		}
+		return append([]string{""}, result...), true
+	case syntax.OpConcat:
+		result := []string{""}
+		for _, sub := range re.Sub {
+			strings, ok := enumerate(sub, limit)
+			if !ok {
+				return nil, false
+			}
+			if result, ok = product(result, strings, limit); !ok {
+				return nil, false
+			}
+		}
+		return result, true
+	case syntax.OpAlternate:
+		result := []string{}
+		for _, sub := range re.Sub {
+			strings, ok := enumerate(sub, limit-len(result))
+			if !ok {
+				return nil, false
+			}
+			result = append(result, strings...)
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// product returns all concatenations of a string from `prefixes` and a string from `suffixes`.
+func product(prefixes []string, suffixes []string, limit int) ([]string, bool) {
+	if len(prefixes)*len(suffixes) > limit {
+		return nil, false
+	}
+	result := make([]string, 0, len(prefixes)*len(suffixes))
+	for _, prefix := range prefixes {
+		for _, suffix := range suffixes {
+			result = append(result, prefix+suffix)
+		}
+	}
+	return result, true
+}
+
+// hasAssertions returns whether `re` contains empty-width assertions, e.g., anchors or word
+// boundaries, which match depending on the surrounding text.
+func hasAssertions(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	return slices.ContainsFunc(re.Sub, hasAssertions)
+}
+
+// expandDefinitions replaces references to definitions in `value`. Returns false if there are
+// references to definitions that don't exist.
+func expandDefinitions(value string, definitions map[string]string) (string, bool) {
+	// Definitions can reference other definitions, but not themselves
+	for range len(definitions) + 1 {
+		if !strings.Contains(value, "{{") {
+			return value, true
+		}
+		value = regex.DefinitionReferenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+			if definition, found := definitions[reference[2:len(reference)-2]]; found {
+				return definition
+			}
+			return reference
+		})
+	}
+	return value, !regex.DefinitionReferenceRegex.MatchString(value)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/configuration"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/ast"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type redundantTestSuite struct {
+	suite.Suite
+	ctx *processors.Context
+}
+
+func TestRunRedundantTestSuite(t *testing.T) {
+	suite.Run(t, new(redundantTestSuite))
+}
+
+func (s *redundantTestSuite) SetupTest() {
+	rootContext := context.NewWithConfiguration(os.TempDir(), &configuration.Configuration{
+		Patterns: configuration.Patterns{
+			AntiEvasion:              configuration.Pattern{Unix: `[\x5c'\"]*`},
+			AntiEvasionSuffix:        configuration.Pattern{Unix: `(?:\s|<|>).*`},
+			AntiEvasionNoSpaceSuffix: configuration.Pattern{Unix: `(?:<|>).*`},
+		},
+	})
+	s.ctx = processors.NewContext(rootContext)
+}
+
+// lint runs the check on `contents` and returns the messages of the problems, prefixed with their line
+func (s *redundantTestSuite) lint(contents string) []string {
+	file, err := ast.Parse("", []byte(contents))
+	s.Require().NoError(err)
+	messages := []string{}
+	for _, problem := range Lint(file, s.ctx, []*Check{RedundantAlternativeCheck}) {
+		messages = append(messages, fmt.Sprintf("%d: %s", problem.Line, problem.Message))
+	}
+	return messages
+}
+
+func (s *redundantTestSuite) TestLongerLiteral() {
+	s.Equal([]string{
+		"2: redundant alternative, already matched by line 1 (cat)",
+	}, s.lint("cat\ncats\ndog\n"))
+}
+
+func (s *redundantTestSuite) TestLiteralCoveredByCharacterClass() {
+	s.Equal([]string{
+		"1: redundant alternative, already matched by line 2 ([a-z])",
+		"3: redundant alternative, already matched by line 2 ([a-z])",
+	}, s.lint("a\n[a-z]\nb[0-9]\n"))
+}
+
+func (s *redundantTestSuite) TestEquivalentLines() {
+	s.Equal([]string{
+		"2: redundant alternative, already matched by line 1 ([ab])",
+	}, s.lint("[ab]\n(?:a|b)\n"))
+}
+
+func (s *redundantTestSuite) TestFiniteLanguage() {
+	s.Equal([]string{
+		"2: redundant alternative, already matched by line 1 ((?:cat|dog)s?)",
+	}, s.lint("(?:cat|dog)s?\ndogs\n"))
+}
+
+func (s *redundantTestSuite) TestAssertions() {
+	// `\bcat` only matches where `cat` matches, but not the other way round
+	s.Equal([]string{
+		"2: redundant alternative, already matched by line 1 (cat)",
+	}, s.lint("cat\n\\bcat\n"))
+	s.Empty(s.lint("cat\\b\ncats\n"))
+}
+
+func (s *redundantTestSuite) TestIgnoreCase() {
+	s.Empty(s.lint("Cat\ncat\n"))
+	s.Equal([]string{
+		"3: redundant alternative, already matched by line 2 (Cat)",
+	}, s.lint("##!+ i\nCat\ncat\n"))
+}
+
+func (s *redundantTestSuite) TestDefinitions() {
+	s.Equal([]string{
+		"3: redundant alternative, already matched by line 2 ({{letter}})",
+	}, s.lint("##!> define letter [a-z]\n{{letter}}\nx\n"))
+}
+
+func (s *redundantTestSuite) TestStashSeparatesAlternations() {
+	s.Empty(s.lint("##!> assemble\n  cat\n  ##!=>\n  cats\n##!<\n"))
+}
+
+func (s *redundantTestSuite) TestCmdLine() {
+	contents := `##!> cmdline unix
+  cat
+  cat@
+  dog~
+  dogs
+##!<
+`
+	s.Equal([]string{
+		"3: redundant alternative, already matched by line 2 (cat)",
+	}, s.lint(contents))
+}
+
+func (s *redundantTestSuite) TestCmdLineWithoutContext() {
+	s.ctx = nil
+	s.Empty(s.lint("##!> cmdline unix\n  cat\n  cat@\n##!<\n"))
+}
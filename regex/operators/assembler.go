@@ -22,52 +22,91 @@ import (
 	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
-// Create the processor stack
-var processorStack ProcessorStack
-var processor processors.IProcessor
-
 // NewAssembler creates a new Operator based on context.
 func NewAssembler(ctx *processors.Context) *Operator {
+	return NewAssemblerForFile(ctx, "")
+}
+
+// NewAssemblerForFile creates a new Operator based on context for the contents of the named
+// regex-assembly file. The file name is only used to report the location of errors.
+func NewAssemblerForFile(ctx *processors.Context, fileName string) *Operator {
 	return &Operator{
 		name:                          "assemble",
+		fileName:                      fileName,
 		details:                       make(map[string]string),
 		lines:                         []string{},
 		ctx:                           ctx,
 		stats:                         NewStats(),
 		groupReplacementStringBuilder: &strings.Builder{},
+		logger:                        ctx.Logger(&logger),
 	}
 }
 
 func (a *Operator) Run(input string) (string, error) {
-	processorStack = NewProcessorStack()
-	logger.Trace().Msg("Starting assembler")
-	assembleParser := parser.NewParser(a.ctx, strings.NewReader(input))
-	lines := assembleParser.Parse(false)
-	logger.Trace().Msgf("Parsed lines: %v", lines)
-	logger.Trace().Msg("Validating input")
+	assembled, _, err := a.run(input)
+	return assembled, err
+}
+
+// RunWithSourceMap works like Run but additionally returns a source map that maps
+// spans of the generated expression to the regex-assembly lines they were generated from.
+func (a *Operator) RunWithSourceMap(input string) (string, *SourceMap, error) {
+	a.collectSourceLines = true
+	a.sourceLines = []sourceLine{}
+	defer func() {
+		a.collectSourceLines = false
+		a.sourceLines = nil
+	}()
+
+	assembled, assembleParser, err := a.run(input)
+	if err != nil {
+		return "", nil, err
+	}
+	sourceMap, err := newSourceMap(assembled, a.sourceLines, assembleParser.Prefixes, assembleParser.Suffixes)
+	if err != nil {
+		return "", nil, err
+	}
+	return assembled, sourceMap, nil
+}
+
+func (a *Operator) run(input string) (string, *parser.Parser, error) {
+	a.processorStack = NewProcessorStack()
+	a.processorStack.logger = a.logger
+	a.processor = nil
+	a.lines = []string{}
+	a.logger.Trace().Msg("Starting assembler")
+	assembleParser := parser.NewParserForFile(a.ctx, a.fileName, strings.NewReader(input))
+	lines, err := assembleParser.Parse(false)
+	if err != nil {
+		return "", nil, err
+	}
+	a.logger.Trace().Msgf("Parsed lines: %v", lines)
+	a.logger.Trace().Msg("Validating input")
 	if err := validation.ValidateAll(bytes.NewReader(lines.Bytes())); err != nil {
-		return "", err
+		return "", nil, err
 	}
-	logger.Trace().Msg("Successfully validated input")
+	a.logger.Trace().Msg("Successfully validated input")
 
 	assembled, err := a.assemble(assembleParser, lines)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	if p, _ := processorStack.top(); p != nil {
-		return assembled, errors.New("stack has unprocessed items")
+	if p, _ := a.processorStack.top(); p != nil {
+		return assembled, nil, errors.New("stack has unprocessed items")
 	}
-	return assembled, err
+	return assembled, assembleParser, err
 }
 
 func (a *Operator) assemble(assembleParser *parser.Parser, input *bytes.Buffer) (string, error) {
 	fileScanner := bufio.NewScanner(bytes.NewReader(input.Bytes()))
-	processor = processors.NewAssemble(a.ctx)
-	processorStack.push(processor)
+	a.processor = processors.NewAssemble(a.ctx)
+	a.processorStack.push(a.processor)
 
+	origins := assembleParser.Origins()
+	lineIndex := -1
 	for fileScanner.Scan() {
+		lineIndex++
 		line := fileScanner.Text()
-		logger.Trace().Msgf("parsing line: %q", line)
+		a.logger.Trace().Msgf("parsing line: %q", line)
 
 		if procline := regex.ProcessorStartRegex.FindStringSubmatch(line); len(procline) > 0 {
 			if err := a.startPreprocessor(procline[1], procline[2:]); err != nil {
@@ -78,40 +117,43 @@ func (a *Operator) assemble(assembleParser *parser.Parser, input *bytes.Buffer)
 			if err != nil {
 				return "", err
 			}
-			if err = processor.Consume(lines); err != nil {
+			if err = a.processor.Consume(lines); err != nil {
 				return "", err
 			}
 		} else {
-			logger.Trace().Msg("Processor is processing line")
-			if err := processor.ProcessLine(line); err != nil {
-				logger.Error().Err(err).Msgf("failed to process line %s", line)
+			a.logger.Trace().Msg("Processor is processing line")
+			if err := a.processor.ProcessLine(line); err != nil {
+				a.logger.Error().Err(err).Msgf("failed to process line %s", line)
 				return "", err
 			}
+			if a.collectSourceLines && lineIndex < len(origins) {
+				a.collectSourceLine(a.processor, line, origins[lineIndex])
+			}
 		}
 	}
 
-	processor, err := processorStack.top()
+	processor, err := a.processorStack.top()
 	if err != nil {
-		logger.Error().Err(err).Msg("Mismatched end marker, processor stack is empty")
+		a.logger.Error().Err(err).Msg("Mismatched end marker, processor stack is empty")
 		return "", err
 	}
 	lines, err := processor.Complete()
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to complete processor")
+		a.logger.Error().Err(err).Msg("Failed to complete processor")
 		return "", err
 	}
-	logger.Trace().Msgf("** Got lines from Processor: %v\n", lines)
+	a.logger.Trace().Msgf("** Got lines from Processor: %v\n", lines)
 	a.lines = append(a.lines, lines...)
-	_, err = processorStack.pop()
+	_, err = a.processorStack.pop()
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to remove assembler processor.")
+		a.logger.Error().Err(err).Msg("Failed to remove assembler processor.")
 		return "", err
 	}
 	return a.complete(assembleParser)
 }
 
 func (a *Operator) complete(assembleParser *parser.Parser) (string, error) {
-	logger.Trace().Msgf("** completing using: %v\n", a.lines)
+	a.logger.Trace().Msgf("** completing using: %v\n", a.lines)
 	flagsPrefix := ""
 	if len(assembleParser.Flags) > 0 {
 		flags := make([]string, 0, len(assembleParser.Flags))
@@ -122,10 +164,10 @@ func (a *Operator) complete(assembleParser *parser.Parser) (string, error) {
 		flagsPrefix = "(?" + strings.Join(flags, "") + ")"
 	}
 
-	logger.Trace().Msg("Final alternation pass")
+	a.logger.Trace().Msg("Final alternation pass")
 	result, err := a.runFinalPass()
 	if err != nil {
-		logger.Error().Err(err).Msg("Final pass failed")
+		a.logger.Error().Err(err).Msg("Final pass failed")
 		return "", err
 	}
 
@@ -137,28 +179,31 @@ func (a *Operator) complete(assembleParser *parser.Parser) (string, error) {
 	result = prefixes + result + suffixes
 
 	if len(result) > 0 {
-		logger.Trace().Msgf("Applying last cleanups to %s\n", result)
-		result = a.runSimplificationAssembly(result)
-		logger.Trace().Msgf("After simplification assembly: %s\n", result)
+		a.logger.Trace().Msgf("Applying last cleanups to %s\n", result)
+		result, err = a.runSimplificationAssembly(result)
+		if err != nil {
+			return "", err
+		}
+		a.logger.Trace().Msgf("After simplification assembly: %s\n", result)
 		result = a.useHexEscapes(result)
-		logger.Trace().Msgf("After replacing non-printable characters with hex escapes: %s\n", result)
+		a.logger.Trace().Msgf("After replacing non-printable characters with hex escapes: %s\n", result)
 		result = a.escapeDoublequotes(result)
-		logger.Trace().Msgf("After escaping double quotes: %s\n", result)
+		a.logger.Trace().Msgf("After escaping double quotes: %s\n", result)
 		result = a.useHexBackslashes(result)
-		logger.Trace().Msgf("After replacing plain backslashes with hex escapes: %s\n", result)
+		a.logger.Trace().Msgf("After replacing plain backslashes with hex escapes: %s\n", result)
 		result = a.includeVerticalTabInSpaceClass(result)
-		logger.Trace().Msgf("After including vertical tabs: %s\n", result)
+		a.logger.Trace().Msgf("After including vertical tabs: %s\n", result)
 		result = a.dontUseFlagsForMetaCharacters(result)
-		logger.Trace().Msgf("After removing meta character flags: %s\n", result)
+		a.logger.Trace().Msgf("After removing meta character flags: %s\n", result)
 		result = a.removeOutermostNonCapturingGroup(result)
-		logger.Trace().Msgf("After removing outermost non-capturing group: %s\n", result)
-		logger.Trace().Msg("Running validation")
-		err := validation.ValidateAll(strings.NewReader(result))
+		a.logger.Trace().Msgf("After removing outermost non-capturing group: %s\n", result)
+		a.logger.Trace().Msg("Running validation")
+		err = validation.ValidateAll(strings.NewReader(result))
 		if err != nil {
-			logger.Error().Err(err).Msg("Validation failed")
+			a.logger.Error().Err(err).Msg("Validation failed")
 			return "", err
 		}
-		logger.Trace().Msg("Validation successful")
+		a.logger.Trace().Msg("Validation successful")
 	}
 
 	if len(flagsPrefix) > 0 && len(result) > 0 {
@@ -172,7 +217,7 @@ func (a *Operator) runFinalPass() (string, error) {
 	processor := processors.NewAssemble(a.ctx)
 	for _, line := range a.lines {
 		if err := processor.ProcessLine(line); err != nil {
-			logger.Error().Err(err).Msgf("failed to process line %s", line)
+			a.logger.Error().Err(err).Msgf("failed to process line %s", line)
 			return "", err
 		}
 	}
@@ -185,21 +230,21 @@ func (a *Operator) runFinalPass() (string, error) {
 
 // Once the entire expression has been assembled, run one last
 // pass to possibly simplify groups and concatenations.
-func (a *Operator) runSimplificationAssembly(input string) string {
-	logger.Trace().Msgf("Simplifying regex %s\n", input)
+func (a *Operator) runSimplificationAssembly(input string) (string, error) {
+	a.logger.Trace().Msgf("Simplifying regex %s\n", input)
 	result, err := rassemble.Join([]string{input})
-	logger.Trace().Msgf("=> Simplified to %s\n", result)
 	if err != nil {
-		logger.Fatal().Err(err).Str("regex", input).Msg("Failed to simplify regex")
+		return "", fmt.Errorf("failed to simplify regex %s: %w", input, err)
 	}
-	return result
+	a.logger.Trace().Msgf("=> Simplified to %s\n", result)
+	return result, nil
 }
 
 // escapeDoublequotes takes a double quote and adds the `\` char before it.
 // We need all double quotes to be escaped because we use them
 // as delimiters in rules.
 func (a *Operator) escapeDoublequotes(input string) string {
-	logger.Trace().Msg("Escaping double quotes")
+	a.logger.Trace().Msg("Escaping double quotes")
 	binput := []byte(input)
 	result := bytes.Buffer{}
 	for k, v := range binput {
@@ -237,7 +282,7 @@ func (a *Operator) useHexBackslashes(input string) string {
 // implementation of PCRE, `\v` was not illegal but led to the range token (`-`)
 // to be interpreted as a literal.
 func (a *Operator) includeVerticalTabInSpaceClass(input string) string {
-	logger.Trace().Msg("Fixing up regex to include vertical tab (VT) in white space class matches")
+	a.logger.Trace().Msg("Fixing up regex to include vertical tab (VT) in white space class matches")
 	return strings.ReplaceAll(input, `\t\n\f\r `, `\s\x0b`)
 }
 
@@ -381,45 +426,45 @@ func (o *Operator) findGroupBodyEnd(input string, groupBodyStart int) (int, bool
 }
 
 func (a *Operator) startPreprocessor(processorName string, args []string) error {
-	logger.Trace().Msgf("Found processor %s start\n", processorName)
+	a.logger.Trace().Msgf("Found processor %s start\n", processorName)
 	switch processorName {
 	case "assemble":
 		assemble := processors.NewAssemble(a.ctx)
-		processorStack.push(assemble)
-		processor = assemble
+		a.processorStack.push(assemble)
+		a.processor = assemble
 	case "cmdline":
 		cmdType, err := processors.CmdLineTypeFromString(args[0])
 		if err != nil {
-			logger.Error().Err(err).Msgf("Wrong cmdline type used: %s\n", args[0])
+			a.logger.Error().Err(err).Msgf("Wrong cmdline type used: %s\n", args[0])
 			return err
 		}
 		cmdline := processors.NewCmdLine(a.ctx, cmdType)
-		processorStack.push(cmdline)
-		processor = cmdline
+		a.processorStack.push(cmdline)
+		a.processor = cmdline
 	default:
-		logger.Error().Msgf("Unknown processor name found: %s\n", processorName)
+		a.logger.Error().Msgf("Unknown processor name found: %s\n", processorName)
 		return errors.New("unknown processor found")
 	}
 	return nil
 }
 
 func (a *Operator) endPreprocessor() ([]string, error) {
-	logger.Trace().Msg("Found processor end")
-	lines, err := processor.Complete()
+	a.logger.Trace().Msg("Found processor end")
+	lines, err := a.processor.Complete()
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to complete processor")
+		a.logger.Error().Err(err).Msg("Failed to complete processor")
 		return nil, err
 	}
-	logger.Trace().Msgf("** Got lines from Processor: %v\n", lines)
+	a.logger.Trace().Msgf("** Got lines from Processor: %v\n", lines)
 	// remove actual processor. read from top next processor.
-	_, err = processorStack.pop()
+	_, err = a.processorStack.pop()
 	if err != nil {
-		logger.Error().Err(err).Msg("Mismatched end marker, processor stack is empty")
+		a.logger.Error().Err(err).Msg("Mismatched end marker, processor stack is empty")
 		return nil, err
 	}
-	processor, err = processorStack.top()
+	a.processor, err = a.processorStack.top()
 	if err != nil {
-		logger.Error().Err(err).Msg("Ooops, nothing on top, processor stack is empty")
+		a.logger.Error().Err(err).Msg("Ooops, nothing on top, processor stack is empty")
 		return nil, err
 	}
 	return lines, nil
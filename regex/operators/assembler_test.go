@@ -5,6 +5,7 @@ package operators
 
 import (
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -259,9 +260,10 @@ func (s *specialCommentsTestSuite) TestHandlesSingleLineFlag() {
 
 func (s *specialCommentsTestSuite) TestHandlesNoOtherFlags() {
 	contents := "##!+mx"
-	assembler := NewAssembler(s.ctx)
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
 
-	s.PanicsWithValue("flag 'm' is not supported", func() { _, _ = assembler.Run(contents) }, "should panic because flags are not supported")
+	_, err := assembler.Run(contents)
+	s.EqualError(err, "123456.ra:1:5: flag 'm' is not supported", "should fail because flags are not supported")
 }
 
 func (s *specialCommentsTestSuite) TestHandlesPrefixComment() {
@@ -1121,3 +1123,31 @@ func (s *assemblerTestSuite) TestAssemble_ValidateCharacterClass() {
 
 	s.ErrorContains(err, "unicode hex escape codepoint too big: 1114111 > 255")
 }
+
+func (s *assemblerTestSuite) TestAssemble_ConcurrentOperators() {
+	inputs := map[string]string{
+		"##!> assemble\nab\n##!=>\n  ##!> cmdline unix\n    cat\n  ##!<\n##!<\n": "abcat",
+		"##!> assemble\nfoo\nbar\n##!<\n":                                        "foo|bar",
+		"##!> assemble\n  one\n  ##!> assemble\n    two\n  ##!<\n##!<\n":         "one|two",
+	}
+	wg := sync.WaitGroup{}
+	results := make(chan [2]string, 100)
+	for range 20 {
+		for input := range inputs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx := processors.NewContext(s.ctx.RootContext())
+				output, err := NewAssembler(ctx).Run(input)
+				s.NoError(err)
+				results <- [2]string{input, output}
+			}()
+		}
+	}
+	wg.Wait()
+	close(results)
+
+	for result := range results {
+		s.Equal(inputs[result[0]], result[1])
+	}
+}
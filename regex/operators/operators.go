@@ -8,6 +8,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
@@ -17,15 +18,22 @@ var logger = log.With().Str("component", "operators").Logger()
 
 type Operator struct {
 	name                          string
+	fileName                      string
 	details                       map[string]string
 	lines                         []string
 	stats                         *Stats
 	ctx                           *processors.Context
 	groupReplacementStringBuilder *strings.Builder
+	processorStack                ProcessorStack
+	processor                     processors.IProcessor
+	collectSourceLines            bool
+	sourceLines                   []sourceLine
+	logger                        *zerolog.Logger
 }
 
 type ProcessorStack struct {
 	processors []processors.IProcessor
+	logger     *zerolog.Logger
 }
 
 type IOperator interface {
@@ -34,7 +42,7 @@ type IOperator interface {
 }
 
 func NewProcessorStack() ProcessorStack {
-	return ProcessorStack{}
+	return ProcessorStack{logger: &logger}
 }
 
 func (p *ProcessorStack) push(processor processors.IProcessor) {
@@ -52,7 +60,7 @@ func (p *ProcessorStack) pop() (processors.IProcessor, error) {
 }
 
 func (p *ProcessorStack) top() (processors.IProcessor, error) {
-	logger.Trace().Msgf("Processor stack len: %d\n", len(p.processors))
+	p.logger.Trace().Msgf("Processor stack len: %d\n", len(p.processors))
 	if len(p.processors) == 0 {
 		return nil, errors.New("stack is empty")
 	}
@@ -0,0 +1,79 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+)
+
+// RunPhrases returns the literal phrases of a regex-assembly file that declares phrase output
+// (##!> output phrases), for use with @pm or @pmFromFile. Includes and definitions are resolved
+// like for regular expressions, but every resulting line must be a literal, where escaped
+// characters stand for themselves. The phrases are returned in order of appearance. As @pm
+// matches case-insensitively, phrases that only differ in case are only returned once.
+func (a *Operator) RunPhrases(input string) ([]string, error) {
+	phrasesParser := parser.NewParserForFile(a.ctx, a.fileName, strings.NewReader(input))
+	lines, err := phrasesParser.Parse(false)
+	if err != nil {
+		return nil, err
+	}
+	if phrasesParser.Output != parser.PhrasesOutput {
+		return nil, fmt.Errorf("%s doesn't declare phrase output (##!> output %s)", a.fileName, parser.PhrasesOutput)
+	}
+	if len(phrasesParser.Prefixes) > 0 || len(phrasesParser.Suffixes) > 0 {
+		return nil, errors.New("prefixes and suffixes are not supported for phrase output")
+	}
+	for flag := range phrasesParser.Flags {
+		if flag != 'i' {
+			return nil, fmt.Errorf("flag '%s' is not supported for phrase output", string(flag))
+		}
+	}
+
+	origins := phrasesParser.Origins()
+	phrases := []string{}
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(lines)
+	for index := 0; scanner.Scan(); index++ {
+		line := scanner.Text()
+		phrase, err := literalPhrase(line)
+		if err != nil {
+			if index < len(origins) {
+				return nil, fmt.Errorf("%s: %w", origins[index], err)
+			}
+			return nil, err
+		}
+		key := strings.ToLower(phrase)
+		if phrase == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		phrases = append(phrases, phrase)
+	}
+	return phrases, scanner.Err()
+}
+
+// literalPhrase returns the literal that `line` stands for, or an error if `line` isn't a
+// literal, e.g., because it contains an alternation or a processor block.
+func literalPhrase(line string) (string, error) {
+	if strings.HasPrefix(line, "##!") {
+		return "", fmt.Errorf("processors are not supported for phrase output: %s", line)
+	}
+	if line == "" {
+		return "", nil
+	}
+	re, err := syntax.Parse(line, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid phrase %s: %w", line, err)
+	}
+	if re.Op != syntax.OpLiteral {
+		return "", fmt.Errorf("not a literal phrase: %s", line)
+	}
+	return string(re.Rune), nil
+}
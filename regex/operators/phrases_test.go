@@ -0,0 +1,113 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type phrasesTestSuite struct {
+	suite.Suite
+	ctx     *processors.Context
+	tempDir string
+}
+
+func TestRunPhrasesTestSuite(t *testing.T) {
+	suite.Run(t, new(phrasesTestSuite))
+}
+
+func (s *phrasesTestSuite) SetupTest() {
+	s.tempDir = s.T().TempDir()
+	rootContext := context.New(s.tempDir, "toolchain.yaml")
+	s.ctx = processors.NewContext(rootContext)
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_ReturnsLiterals() {
+	contents := `##!> output phrases
+##! comment
+/bin/bash
+
+wget
+cmd\.exe
+a\ b
+`
+	phrases, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.Require().NoError(err)
+	s.Equal([]string{"/bin/bash", "wget", "cmd.exe", "a b"}, phrases)
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RemovesCaseInsensitiveDuplicates() {
+	contents := `##!> output phrases
+##!+ i
+wget
+curl
+WGET
+`
+	phrases, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.Require().NoError(err)
+	s.Equal([]string{"wget", "curl"}, phrases)
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_ResolvesIncludesAndDefinitions() {
+	includesDir := s.ctx.RootContext().IncludesDir()
+	s.Require().NoError(os.MkdirAll(includesDir, 0755))
+	s.Require().NoError(os.WriteFile(path.Join(includesDir, "shells.ra"), []byte("bash\nzsh\n"), 0644))
+	contents := `##!> output phrases
+##!> define bin /bin/
+##!> include shells
+{{bin}}sh
+`
+	phrases, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.Require().NoError(err)
+	s.Equal([]string{"bash", "zsh", "/bin/sh"}, phrases)
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RequiresPhraseOutput() {
+	_, err := NewAssembler(s.ctx).RunPhrases("wget\n")
+	s.ErrorContains(err, "doesn't declare phrase output")
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RejectsExpressions() {
+	contents := `##!> output phrases
+wget
+cu?rl
+`
+	_, err := NewAssemblerForFile(s.ctx, "test.ra").RunPhrases(contents)
+	s.EqualError(err, "test.ra:3: not a literal phrase: cu?rl")
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RejectsProcessors() {
+	contents := `##!> output phrases
+##!> cmdline unix
+wget
+##!<
+`
+	_, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.ErrorContains(err, "processors are not supported for phrase output")
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RejectsPrefixes() {
+	contents := `##!> output phrases
+##!^ a
+wget
+`
+	_, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.EqualError(err, "prefixes and suffixes are not supported for phrase output")
+}
+
+func (s *phrasesTestSuite) TestRunPhrases_RejectsFlags() {
+	contents := `##!> output phrases
+##!+ s
+wget
+`
+	_, err := NewAssembler(s.ctx).RunPhrases(contents)
+	s.EqualError(err, "flag 's' is not supported for phrase output")
+}
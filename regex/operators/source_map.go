@@ -0,0 +1,501 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+const branchGroupNamePrefix = "crs_branch"
+
+var leadingFlagsRegex = regexp.MustCompile(`^\(\?[a-zA-Z]+\)`)
+
+// SourceMap maps spans of a generated regular expression to the regex-assembly lines that
+// produced them.
+//
+// The assembler merges and factors alternatives, so there is no one-to-one relationship between
+// input lines and the generated expression. Instead, every alternative (branch of an alternation)
+// of the generated expression is a span, and a line is mapped to all the spans that match
+// a sample string generated from the line. The span covering the entire expression is always
+// mapped to all lines. Mapping is best effort: lines that can't be attributed to any alternative
+// are only mapped to the span covering the entire expression. Lines that can't be analyzed at all,
+// e.g., because they aren't valid expressions on their own, are listed in `Unmapped`.
+type SourceMap struct {
+	Regex    string                  `json:"regex"`
+	Mappings []SourceMapping         `json:"mappings"`
+	Unmapped []parser.SourceLocation `json:"unmapped,omitempty"`
+
+	branches     []branchSpan
+	instrumented *regexp.Regexp
+}
+
+// SourceMapping maps the span of the generated expression, starting at byte offset `Start` and
+// ending before byte offset `End`, to the lines that produced it.
+type SourceMapping struct {
+	Start   int                     `json:"start"`
+	End     int                     `json:"end"`
+	Sources []parser.SourceLocation `json:"sources"`
+}
+
+type sourceLine struct {
+	origin     parser.SourceLocation
+	expression string
+}
+
+type branchSpan struct {
+	start int
+	end   int
+}
+
+// SourcesAt returns the sources of the innermost mapping that contains the byte at `offset`.
+func (m *SourceMap) SourcesAt(offset int) []parser.SourceLocation {
+	var innermost *SourceMapping
+	for i := range m.Mappings {
+		mapping := &m.Mappings[i]
+		if offset < mapping.Start || offset >= mapping.End {
+			continue
+		}
+		if innermost == nil || mapping.End-mapping.Start < innermost.End-innermost.Start {
+			innermost = mapping
+		}
+	}
+	if innermost == nil {
+		return nil
+	}
+	return innermost.Sources
+}
+
+// collectSourceLine records the expression that `processor` generates for `line`,
+// so that the line can later be located in the generated expression.
+func (a *Operator) collectSourceLine(processor processors.IProcessor, line string, origin parser.SourceLocation) {
+	if regex.AssembleInputRegex.MatchString(line) || regex.AssembleOutputRegex.MatchString(line) {
+		return
+	}
+	expression := line
+	if cmdLine, ok := processor.(*processors.CmdLine); ok {
+		if len(line) == 0 {
+			return
+		}
+		expression = cmdLine.Expand(line)
+	}
+	a.sourceLines = append(a.sourceLines, sourceLine{origin: origin, expression: expression})
+}
+
+func newSourceMap(expression string, lines []sourceLine, prefixes []string, suffixes []string) (*SourceMap, error) {
+	sourceMap := &SourceMap{
+		Regex:    expression,
+		Mappings: []SourceMapping{},
+		branches: findBranches(expression),
+	}
+	if len(expression) == 0 {
+		return sourceMap, nil
+	}
+
+	instrumented, err := regexp.Compile(instrumentBranches(expression, sourceMap.branches))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile generated expression for source map: %w", err)
+	}
+	instrumented.Longest()
+	sourceMap.instrumented = instrumented
+
+	prefixSample := sampleOf(strings.Join(prefixes, ""))
+	suffixSample := sampleOf(strings.Join(suffixes, ""))
+	flagsPrefix := leadingFlagsRegex.FindString(expression)
+	branchMatchers := make([]*regexp.Regexp, len(sourceMap.branches))
+	// Try small branches first, they are the most precise
+	bySize := make([]int, len(sourceMap.branches))
+	for i := range bySize {
+		bySize[i] = i
+	}
+	sort.SliceStable(bySize, func(i, j int) bool {
+		left := sourceMap.branches[bySize[i]]
+		right := sourceMap.branches[bySize[j]]
+		return left.end-left.start < right.end-right.start
+	})
+
+	sources := make([][]parser.SourceLocation, len(sourceMap.branches))
+	rootSources := []parser.SourceLocation{}
+	for _, line := range lines {
+		rootSources = appendSource(rootSources, line.origin)
+		lineSample, ok := sample(line.expression)
+		if !ok {
+			sourceMap.Unmapped = append(sourceMap.Unmapped, line.origin)
+			continue
+		}
+
+		attributed := false
+		for _, candidate := range []string{lineSample, prefixSample + lineSample + suffixSample} {
+			for _, index := range sourceMap.matchingBranches(candidate) {
+				sources[index] = appendSource(sources[index], line.origin)
+				attributed = true
+			}
+			if attributed {
+				break
+			}
+		}
+		if attributed {
+			continue
+		}
+
+		// The sample might not match the entire expression, e.g., because the line is
+		// concatenated with other lines. Try to find the smallest branch that matches the sample.
+		for _, index := range bySize {
+			matcher := branchMatchers[index]
+			if matcher == nil {
+				branch := sourceMap.branches[index]
+				matcher, err = regexp.Compile(flagsPrefix + "^(?:" + expression[branch.start:branch.end] + ")$")
+				if err != nil {
+					continue
+				}
+				branchMatchers[index] = matcher
+			}
+			if matcher.MatchString(lineSample) {
+				sources[index] = appendSource(sources[index], line.origin)
+				break
+			}
+		}
+	}
+
+	sourceMap.Mappings = append(sourceMap.Mappings, SourceMapping{
+		Start:   0,
+		End:     len(expression),
+		Sources: rootSources,
+	})
+	for index, branchSources := range sources {
+		if len(branchSources) == 0 {
+			continue
+		}
+		branch := sourceMap.branches[index]
+		sourceMap.Mappings = append(sourceMap.Mappings, SourceMapping{
+			Start:   branch.start,
+			End:     branch.end,
+			Sources: branchSources,
+		})
+	}
+	sort.SliceStable(sourceMap.Mappings, func(i, j int) bool {
+		left := sourceMap.Mappings[i]
+		right := sourceMap.Mappings[j]
+		if left.Start != right.Start {
+			return left.Start < right.Start
+		}
+		return left.End > right.End
+	})
+
+	return sourceMap, nil
+}
+
+// Match describes a match of the generated expression against an input. `Start` and `End` are
+// the byte offsets of the matched text in the input, `Sources` are the regex-assembly lines
+// that produced the innermost alternatives that participated in the match.
+type Match struct {
+	Start   int
+	End     int
+	Text    string
+	Sources []parser.SourceLocation
+}
+
+// Match runs the generated expression against `input` and returns the leftmost-longest match,
+// together with the regex-assembly lines that are responsible for it. Returns nil if the
+// expression doesn't match.
+func (m *SourceMap) Match(input string) *Match {
+	match, indices := m.findMatch(input)
+	if match == nil {
+		return nil
+	}
+	result := &Match{
+		Start:   match[0],
+		End:     match[1],
+		Text:    input[match[0]:match[1]],
+		Sources: []parser.SourceLocation{},
+	}
+
+	mapped := []*SourceMapping{}
+	for _, index := range indices {
+		branch := m.branches[index]
+		if mapping := m.mappingFor(branch); mapping != nil {
+			mapped = append(mapped, mapping)
+		}
+	}
+	for _, mapping := range mapped {
+		innermost := true
+		for _, other := range mapped {
+			if other != mapping && other.Start >= mapping.Start && other.End <= mapping.End {
+				innermost = false
+				break
+			}
+		}
+		if !innermost {
+			continue
+		}
+		for _, source := range mapping.Sources {
+			result.Sources = appendSource(result.Sources, source)
+		}
+	}
+	if len(result.Sources) == 0 {
+		// No alternative could be attributed, fall back to the entire expression
+		result.Sources = append(result.Sources, m.SourcesAt(0)...)
+	}
+	return result
+}
+
+func (m *SourceMap) mappingFor(branch branchSpan) *SourceMapping {
+	for i := range m.Mappings {
+		mapping := &m.Mappings[i]
+		if mapping.Start == branch.start && mapping.End == branch.end {
+			return mapping
+		}
+	}
+	return nil
+}
+
+// matchingBranches returns the indices of the branches of the generated expression that
+// participate in the leftmost-longest match of `input`.
+func (m *SourceMap) matchingBranches(input string) []int {
+	_, indices := m.findMatch(input)
+	return indices
+}
+
+func (m *SourceMap) findMatch(input string) ([]int, []int) {
+	if m.instrumented == nil {
+		return nil, nil
+	}
+	match := m.instrumented.FindStringSubmatchIndex(input)
+	if match == nil {
+		return nil, nil
+	}
+	indices := []int{}
+	for groupIndex, name := range m.instrumented.SubexpNames() {
+		if !strings.HasPrefix(name, branchGroupNamePrefix) || match[2*groupIndex] < 0 {
+			continue
+		}
+		var branchIndex int
+		if _, err := fmt.Sscanf(name, branchGroupNamePrefix+"%d", &branchIndex); err == nil {
+			indices = append(indices, branchIndex)
+		}
+	}
+	return match, indices
+}
+
+func appendSource(sources []parser.SourceLocation, source parser.SourceLocation) []parser.SourceLocation {
+	for _, existing := range sources {
+		if existing == source {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
+
+// findBranches returns the spans of all branches of all alternations in `expression`, including
+// the top level. Groups without alternation don't produce branches.
+func findBranches(expression string) []branchSpan {
+	type group struct {
+		branchStart int
+		branches    []branchSpan
+	}
+	branches := []branchSpan{}
+	stack := []*group{{branchStart: 0}}
+	closeGroup := func(g *group, end int) {
+		if len(g.branches) == 0 {
+			return
+		}
+		g.branches = append(g.branches, branchSpan{g.branchStart, end})
+		branches = append(branches, g.branches...)
+	}
+
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\\':
+			i++
+		case '[':
+			i = findCharacterClassEnd(expression, i)
+		case '(':
+			bodyStart, isGroup := findGroupBodyStart(expression, i)
+			if isGroup {
+				stack = append(stack, &group{branchStart: bodyStart})
+			}
+			i = bodyStart - 1
+		case '|':
+			top := stack[len(stack)-1]
+			top.branches = append(top.branches, branchSpan{top.branchStart, i})
+			top.branchStart = i + 1
+		case ')':
+			if len(stack) > 1 {
+				closeGroup(stack[len(stack)-1], i)
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	closeGroup(stack[0], len(expression))
+
+	sort.SliceStable(branches, func(i, j int) bool {
+		return branches[i].start < branches[j].start
+	})
+	return branches
+}
+
+// findGroupBodyStart returns the index of the first token of the body of the group that starts
+// at `groupStart`. Returns `false` if the parentheses don't start a group but set flags, e.g., `(?i)`.
+// In that case the returned index points past the closing parenthesis.
+func findGroupBodyStart(expression string, groupStart int) (int, bool) {
+	index := groupStart + 1
+	if index >= len(expression) || expression[index] != '?' {
+		return index, true
+	}
+	for ; index < len(expression); index++ {
+		switch expression[index] {
+		case ':', '>':
+			return index + 1, true
+		case ')':
+			return index + 1, false
+		}
+	}
+	return index, true
+}
+
+// findCharacterClassEnd returns the index of the closing bracket of the character class
+// that starts at `classStart`.
+func findCharacterClassEnd(expression string, classStart int) int {
+	index := classStart + 1
+	if index < len(expression) && expression[index] == '^' {
+		index++
+	}
+	if index < len(expression) && expression[index] == ']' {
+		// literal closing bracket
+		index++
+	}
+	for ; index < len(expression); index++ {
+		switch expression[index] {
+		case '\\':
+			index++
+		case '[':
+			if index+1 < len(expression) && expression[index+1] == ':' {
+				if end := strings.Index(expression[index:], ":]"); end > 0 {
+					index += end + 1
+				}
+			}
+		case ']':
+			return index
+		}
+	}
+	return index
+}
+
+// instrumentBranches wraps every branch in a named capturing group, so that the branches that
+// participate in a match can be identified.
+func instrumentBranches(expression string, branches []branchSpan) string {
+	openers := map[int][]int{}
+	closers := map[int][]int{}
+	for index, branch := range branches {
+		openers[branch.start] = append(openers[branch.start], index)
+		closers[branch.end] = append(closers[branch.end], index)
+	}
+
+	var sb strings.Builder
+	for i := 0; i <= len(expression); i++ {
+		// Close non-empty branches first, they can't overlap with branches that start here
+		for _, index := range closers[i] {
+			if branches[index].start != i {
+				sb.WriteString(")")
+			}
+		}
+		for _, index := range openers[i] {
+			fmt.Fprintf(&sb, "(?P<%s%d>", branchGroupNamePrefix, index)
+			if branches[index].end == i {
+				sb.WriteString(")")
+			}
+		}
+		if i < len(expression) {
+			sb.WriteByte(expression[i])
+		}
+	}
+	return sb.String()
+}
+
+// sampleOf returns a sample string matched by `expression`, or the empty string if
+// no sample can be generated.
+func sampleOf(expression string) string {
+	result, _ := sample(expression)
+	return result
+}
+
+// sample generates a short string that is matched by `expression`. Returns `false` if
+// the expression can't be parsed or can't match anything.
+func sample(expression string) (string, bool) {
+	parsed, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	if !writeSample(&sb, parsed.Simplify()) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+func writeSample(sb *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		sb.WriteRune(sampleRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune('a')
+	case syntax.OpCapture, syntax.OpPlus:
+		return writeSample(sb, re.Sub[0])
+	case syntax.OpRepeat:
+		for range re.Min {
+			if !writeSample(sb, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writeSample(sb, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		shortest := ""
+		found := false
+		for _, sub := range re.Sub {
+			var alternative strings.Builder
+			if writeSample(&alternative, sub) && (!found || alternative.Len() < len(shortest)) {
+				shortest = alternative.String()
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+		sb.WriteString(shortest)
+	}
+	// Empty matches, assertions, `*` and `?` don't need to produce any output
+	return true
+}
+
+// sampleRune picks a rune from the ranges of a character class, preferring printable characters.
+func sampleRune(ranges []rune) rune {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= unicode.MaxASCII; r++ {
+			if unicode.IsPrint(r) && r != ' ' {
+				return r
+			}
+		}
+	}
+	return ranges[0]
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package operators
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/configuration"
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/parser"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type sourceMapTestSuite struct {
+	suite.Suite
+	ctx        *processors.Context
+	includeDir string
+}
+
+func TestRunSourceMapTestSuite(t *testing.T) {
+	suite.Run(t, new(sourceMapTestSuite))
+}
+
+func (s *sourceMapTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.includeDir = path.Join(rootDir, "regex-assembly", "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+
+	rootContext := context.NewWithConfiguration(rootDir, &configuration.Configuration{
+		Patterns: configuration.Patterns{
+			AntiEvasion: configuration.Pattern{
+				Unix: `[\x5c'\"]*`,
+			},
+			AntiEvasionSuffix: configuration.Pattern{
+				Unix: `(?:\s|<|>).*`,
+			},
+			AntiEvasionNoSpaceSuffix: configuration.Pattern{
+				Unix: `(?:[^\s]).*`,
+			},
+		},
+	})
+	s.ctx = processors.NewContext(rootContext)
+}
+
+func (s *sourceMapTestSuite) mappingFor(sourceMap *SourceMap, span string) *SourceMapping {
+	for i := range sourceMap.Mappings {
+		mapping := sourceMap.Mappings[i]
+		if sourceMap.Regex[mapping.Start:mapping.End] == span {
+			return &mapping
+		}
+	}
+	return nil
+}
+
+func (s *sourceMapTestSuite) TestSourceMap_SimpleAlternation() {
+	contents := "##! comment\nfoo\nbar\nbaz\n"
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
+	output, sourceMap, err := assembler.RunWithSourceMap(contents)
+	s.Require().NoError(err)
+
+	s.Equal("foo|ba[rz]", output)
+	s.Equal(output, sourceMap.Regex)
+	s.Empty(sourceMap.Unmapped)
+
+	root := s.mappingFor(sourceMap, output)
+	s.Require().NotNil(root)
+	s.Len(root.Sources, 3)
+
+	foo := s.mappingFor(sourceMap, "foo")
+	s.Require().NotNil(foo)
+	s.Equal([]parser.SourceLocation{{File: "123456.ra", Line: 2}}, foo.Sources)
+
+	bar := s.mappingFor(sourceMap, "ba[rz]")
+	s.Require().NotNil(bar)
+	s.Equal([]parser.SourceLocation{
+		{File: "123456.ra", Line: 3},
+		{File: "123456.ra", Line: 4},
+	}, bar.Sources)
+
+	s.Equal([]parser.SourceLocation{{File: "123456.ra", Line: 2}}, sourceMap.SourcesAt(1))
+}
+
+func (s *sourceMapTestSuite) TestSourceMap_Include() {
+	includePath := path.Join(s.includeDir, "words.ra")
+	err := os.WriteFile(includePath, []byte("##! words\ncat\nls\n"), fs.ModePerm)
+	s.Require().NoError(err)
+
+	contents := "##!> include words\nwhoami\n"
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
+	output, sourceMap, err := assembler.RunWithSourceMap(contents)
+	s.Require().NoError(err)
+
+	s.Equal("cat|ls|whoami", output)
+	cat := s.mappingFor(sourceMap, "cat")
+	s.Require().NotNil(cat)
+	s.Equal([]parser.SourceLocation{{File: includePath, Line: 2}}, cat.Sources)
+	whoami := s.mappingFor(sourceMap, "whoami")
+	s.Require().NotNil(whoami)
+	s.Equal([]parser.SourceLocation{{File: "123456.ra", Line: 2}}, whoami.Sources)
+}
+
+func (s *sourceMapTestSuite) TestSourceMap_CmdLine() {
+	contents := "##!> cmdline unix\n  python~\n  cat@\n##!<\nfoo\n"
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
+	_, sourceMap, err := assembler.RunWithSourceMap(contents)
+	s.Require().NoError(err)
+
+	s.Empty(sourceMap.Unmapped)
+	python := sourceMap.matchingBranches("python3")
+	s.NotEmpty(python)
+	found := false
+	for _, index := range python {
+		branch := sourceMap.branches[index]
+		sources := s.mappingFor(sourceMap, sourceMap.Regex[branch.start:branch.end])
+		if sources != nil && len(sources.Sources) == 1 {
+			s.Equal(parser.SourceLocation{File: "123456.ra", Line: 2}, sources.Sources[0])
+			found = true
+		}
+	}
+	s.True(found)
+}
+
+func (s *sourceMapTestSuite) TestSourceMap_Concatenation() {
+	contents := "##!> assemble\nfoo\nbaz\n##!=>\nbar\n##!<\n"
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
+	output, sourceMap, err := assembler.RunWithSourceMap(contents)
+	s.Require().NoError(err)
+
+	s.Equal("(?:foo|baz)bar", output)
+	s.Empty(sourceMap.Unmapped)
+	foo := s.mappingFor(sourceMap, "foo")
+	s.Require().NotNil(foo)
+	s.Equal([]parser.SourceLocation{{File: "123456.ra", Line: 2}}, foo.Sources)
+}
+
+func (s *sourceMapTestSuite) TestFindBranches() {
+	expression := `a(?:b|[|(]c|\|)(?i)d|(?P<name>e|)`
+	branches := findBranches(expression)
+	spans := []string{}
+	for _, branch := range branches {
+		spans = append(spans, expression[branch.start:branch.end])
+	}
+	s.Equal([]string{`a(?:b|[|(]c|\|)(?i)d`, "b", "[|(]c", `\|`, "(?P<name>e|)", "e", ""}, spans)
+}
+
+func (s *sourceMapTestSuite) TestSample() {
+	for expression, expected := range map[string]string{
+		"foo":          "foo",
+		"fo+[a-c]?x*":  "fo",
+		"(?:abc|d)e":   "de",
+		`a[\s><]b{2}.`: "a<bba",
+		`\bcat\b`:      "cat",
+	} {
+		actual, ok := sample(expression)
+		s.True(ok, expression)
+		s.Equal(expected, actual, expression)
+	}
+}
+
+func (s *sourceMapTestSuite) TestSourceMap_Match() {
+	contents := "##!> assemble\nfoo\nbaz\n##!=>\nbar\n##!<\n"
+	assembler := NewAssemblerForFile(s.ctx, "123456.ra")
+	_, sourceMap, err := assembler.RunWithSourceMap(contents)
+	s.Require().NoError(err)
+
+	match := sourceMap.Match("xx bazbar yy")
+	s.Require().NotNil(match)
+	s.Equal(3, match.Start)
+	s.Equal(9, match.End)
+	s.Equal("bazbar", match.Text)
+	s.Equal([]parser.SourceLocation{{File: "123456.ra", Line: 3}}, match.Sources)
+
+	s.Nil(sourceMap.Match("foo baz"))
+}
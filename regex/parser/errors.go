@@ -0,0 +1,75 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError is returned by the parser when a regex-assembly file can't be parsed.
+// `Location` points to the offending line, `IncludeStack` contains the locations of the
+// include directives that led to the file containing the offending line, outermost first.
+type ParseError struct {
+	Location     SourceLocation
+	IncludeStack []SourceLocation
+	Err          error
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	for _, frame := range e.IncludeStack {
+		// The column of include directives is noise in the chain
+		frame.Column = 0
+		sb.WriteString(frame.String())
+		sb.WriteString(" -> ")
+	}
+	sb.WriteString(e.Location.String())
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// IncludeCycleError is reported when a file includes itself, directly or transitively.
+// `Files` contains the paths of the files that form the cycle, starting and ending with
+// the same file.
+type IncludeCycleError struct {
+	Files []string
+}
+
+func (e *IncludeCycleError) Error() string {
+	return "include cycle: " + strings.Join(e.Files, " -> ")
+}
+
+// IncludeError is returned by the parser when include directives can't be resolved, because the
+// referenced files don't exist or because they form a cycle. It contains one *ParseError per
+// offending directive, in the order they were found.
+type IncludeError struct {
+	Errors []*ParseError
+}
+
+func (e *IncludeError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d includes could not be resolved:", len(e.Errors))
+	for _, err := range e.Errors {
+		sb.WriteString("\n  ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e *IncludeError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
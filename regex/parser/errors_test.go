@@ -0,0 +1,190 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type parserErrorsTestSuite struct {
+	suite.Suite
+	ctx        *processors.Context
+	includeDir string
+	excludeDir string
+}
+
+func TestRunParserErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(parserErrorsTestSuite))
+}
+
+func (s *parserErrorsTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.includeDir = path.Join(rootDir, "regex-assembly", "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	s.excludeDir = path.Join(rootDir, "regex-assembly", "exclude")
+	err = os.MkdirAll(s.excludeDir, fs.ModePerm)
+	s.Require().NoError(err)
+
+	rootContext := context.New(rootDir, "toolchain.yaml")
+	s.ctx = processors.NewContext(rootContext)
+}
+
+func (s *parserErrorsTestSuite) writeFile(directory string, name string, contents string) string {
+	filePath := path.Join(directory, name)
+	err := os.WriteFile(filePath, []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+	return filePath
+}
+
+func (s *parserErrorsTestSuite) TestMissingInclude() {
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("foo\n##!> include does-not-exist\n"))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 2, Column: 14}, parseError.Location)
+	s.Empty(parseError.IncludeStack)
+	s.True(errors.Is(err, fs.ErrNotExist))
+}
+
+func (s *parserErrorsTestSuite) TestErrorInNestedInclude() {
+	s.writeFile(s.includeDir, "outer.ra", "outer\n##!> include inner\n")
+	innerPath := s.writeFile(s.includeDir, "inner.ra", "inner\n\n  ##!> include missing -- @ a ~\n")
+	outerPath := path.Join(s.includeDir, "outer.ra")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##! comment\n##!> include outer\n"))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: innerPath, Line: 3, Column: 27}, parseError.Location)
+	s.Equal([]SourceLocation{
+		{File: "123456.ra", Line: 2, Column: 14},
+		{File: outerPath, Line: 2, Column: 14},
+	}, parseError.IncludeStack)
+	s.Equal("123456.ra:2 -> "+outerPath+":2 -> "+innerPath+":3:27: uneven number of arguments found: @ a ~", err.Error())
+}
+
+func (s *parserErrorsTestSuite) TestMissingExcludeFile() {
+	s.writeFile(s.includeDir, "include.ra", "foo\nbar\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "bar\n")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include-except include exclude missing\n"))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 1, Column: 37}, parseError.Location)
+}
+
+func (s *parserErrorsTestSuite) TestFlagsInIncludeFile() {
+	s.writeFile(s.includeDir, "include.ra", "##!+ i\nfoo\n")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include include\n"))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 1, Column: 14}, parseError.Location)
+	s.Contains(err.Error(), "include files must not contain flags")
+}
+
+func (s *parserErrorsTestSuite) TestMissingIncludesAreReportedTogether() {
+	s.writeFile(s.includeDir, "include.ra", "foo\n##!> include inner-missing\n")
+	includePath := path.Join(s.includeDir, "include.ra")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader(
+		"##!> include missing\n##!> include include\n##!> include-except include other-missing\n"))
+	_, err := parser.Parse(false)
+
+	var includeError *IncludeError
+	s.Require().ErrorAs(err, &includeError)
+	s.Require().Len(includeError.Errors, 4)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 1, Column: 14}, includeError.Errors[0].Location)
+	s.Equal(SourceLocation{File: includePath, Line: 2, Column: 14}, includeError.Errors[1].Location)
+	s.Equal([]SourceLocation{{File: "123456.ra", Line: 2, Column: 14}}, includeError.Errors[1].IncludeStack)
+	s.Equal(SourceLocation{File: includePath, Line: 2, Column: 14}, includeError.Errors[2].Location)
+	s.Equal([]SourceLocation{{File: "123456.ra", Line: 3, Column: 21}}, includeError.Errors[2].IncludeStack)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 3, Column: 29}, includeError.Errors[3].Location)
+	for _, parseError := range includeError.Errors {
+		s.ErrorIs(parseError, fs.ErrNotExist)
+	}
+	s.True(strings.HasPrefix(err.Error(), "4 includes could not be resolved:\n  123456.ra:1:14: cannot open file for parsing"))
+}
+
+func (s *parserErrorsTestSuite) TestIncludeCycle() {
+	aPath := s.writeFile(s.includeDir, "a.ra", "a\n##!> include b\n")
+	bPath := s.writeFile(s.includeDir, "b.ra", "b\n##!> include c\n")
+	cPath := s.writeFile(s.includeDir, "c.ra", "c\n##!> include a\n")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include a\n"))
+	_, err := parser.Parse(false)
+
+	var cycleError *IncludeCycleError
+	s.Require().ErrorAs(err, &cycleError)
+	s.Equal([]string{aPath, bPath, cPath, aPath}, cycleError.Files)
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: cPath, Line: 2, Column: 14}, parseError.Location)
+	s.Equal("123456.ra:1 -> "+aPath+":2 -> "+bPath+":2 -> "+cPath+":2:14: include cycle: "+
+		aPath+" -> "+bPath+" -> "+cPath+" -> "+aPath, err.Error())
+}
+
+func (s *parserErrorsTestSuite) TestSelfInclude() {
+	filePath := s.writeFile(s.includeDir, "self.ra", "foo\n##!> include self\n")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include self\n"))
+	_, err := parser.Parse(false)
+
+	var cycleError *IncludeCycleError
+	s.Require().ErrorAs(err, &cycleError)
+	s.Equal([]string{filePath, filePath}, cycleError.Files)
+}
+
+func (s *parserErrorsTestSuite) TestRuleFileIncludesItself() {
+	filePath := s.writeFile(s.T().TempDir(), "123456.ra", "")
+	s.writeFile(s.includeDir, "include.ra", "##!> include "+filePath+"\n")
+
+	parser := NewParserForFile(s.ctx, filePath, strings.NewReader("##!> include include\n"))
+	_, err := parser.Parse(false)
+
+	var cycleError *IncludeCycleError
+	s.Require().ErrorAs(err, &cycleError)
+	s.Equal([]string{filePath, path.Join(s.includeDir, "include.ra"), filePath}, cycleError.Files)
+}
+
+func (s *parserErrorsTestSuite) TestRelativeRuleFileIncludesItself() {
+	assemblyDir := path.Dir(s.includeDir)
+	filePath := s.writeFile(assemblyDir, "123456.ra", "")
+	s.T().Chdir(assemblyDir)
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include ../123456\n"))
+	_, err := parser.Parse(false)
+
+	var cycleError *IncludeCycleError
+	s.Require().ErrorAs(err, &cycleError)
+	s.Equal([]string{filePath, filePath}, cycleError.Files)
+}
+
+func (s *parserErrorsTestSuite) TestRepeatedIncludeIsNotACycle() {
+	s.writeFile(s.includeDir, "include.ra", "foo\n")
+	s.writeFile(s.includeDir, "outer.ra", "##!> include include\n##!> include include\n")
+
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include outer\n##!> include include\n"))
+	output, err := parser.Parse(false)
+
+	s.Require().NoError(err)
+	s.Equal("foo\nfoo\nfoo\n", output.String())
+}
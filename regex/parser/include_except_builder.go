@@ -12,8 +12,9 @@ import (
 )
 
 type inclusionLine struct {
-	line  string
-	order int
+	line   string
+	order  int
+	origin SourceLocation
 }
 
 type inclusionLineSlice []inclusionLine
@@ -32,27 +33,37 @@ func (h inclusionLineSlice) Swap(i, j int) {
 	h[i], h[j] = h[j], h[i]
 }
 
-func buildIncludeString(parser *Parser, parsedLine ParsedLine) (string, error) {
-	content, _ := parseFile(parser, parsedLine.includeFileName, nil)
-	return replaceSuffixes(content, parsedLine.suffixReplacements)
+func buildIncludeString(parser *Parser, parsedLine ParsedLine) (string, []SourceLocation, error) {
+	content, origins, _, err := parseFile(parser, parsedLine.includeFileName, parser.location(parsedLine.submatches[2]), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	text, err := replaceSuffixes(content, parsedLine.suffixReplacements)
+	return text, origins, err
 }
 
-func buildIncludeExceptString(parser *Parser, parsedLine ParsedLine) (string, error) {
+func buildIncludeExceptString(parser *Parser, parsedLine ParsedLine) (string, []SourceLocation, error) {
 	// 1. build a map with lines as keys for fast access;
 	//    store the line itself and its position in the value (an inclusionLine) for later
 	// 2. remove exclusions from the map
 	// 3. put the inclusionLines back into an array, still out of order
 	// 4. build the resulting string by sorting the array and joining the lines
-	includeMap, definitions := buildinclusionLineMap(parser, parsedLine.includeFileName)
-	removeExclusions(parser, parsedLine.excludeFileNames, includeMap, definitions)
+	includeMap, definitions, err := buildinclusionLineMap(parser, parsedLine.includeFileName, parser.location(parsedLine.submatches[2]))
+	if err != nil {
+		return "", nil, err
+	}
+	if err := removeExclusions(parser, parsedLine, includeMap, definitions); err != nil {
+		return "", nil, err
+	}
 
 	inclusionLines := make(inclusionLineSlice, 0, len(includeMap))
 	for _, value := range includeMap {
 		inclusionLines = append(inclusionLines, value)
 	}
 
-	contentWithoutExclusions := stringFromInclusionLines(inclusionLines)
-	return replaceSuffixes(bytes.NewBufferString(contentWithoutExclusions), parsedLine.suffixReplacements)
+	contentWithoutExclusions, origins := stringFromInclusionLines(inclusionLines)
+	text, err := replaceSuffixes(bytes.NewBufferString(contentWithoutExclusions), parsedLine.suffixReplacements)
+	return text, origins, err
 }
 
 func replaceSuffixes(inputLines *bytes.Buffer, suffixReplacements map[string]string) (string, error) {
@@ -80,51 +91,63 @@ func replaceSuffixes(inputLines *bytes.Buffer, suffixReplacements map[string]str
 	return sb.String(), nil
 }
 
-func removeExclusions(parser *Parser, excludeFileNames []string, includeMap map[string]inclusionLine, definitions map[string]string) {
-	for _, fileName := range excludeFileNames {
-		logger.Debug().Msgf("Processing exclusions from %s", fileName)
-		excludeContent, _ := parseFile(parser, fileName, definitions)
+func removeExclusions(parser *Parser, parsedLine ParsedLine, includeMap map[string]inclusionLine, definitions map[string]string) error {
+	for i, fileName := range parsedLine.excludeFileNames {
+		parser.logger.Debug().Msgf("Processing exclusions from %s", fileName)
+		excludeContent, _, _, err := parseFile(parser, fileName, parser.location(parsedLine.excludeOffsets[i]), definitions)
+		if err != nil {
+			return err
+		}
 		scanner := bufio.NewScanner(excludeContent)
 		for scanner.Scan() {
 			exclusion := scanner.Text()
 			delete(includeMap, exclusion)
-			logger.Debug().Msgf("Excluded entry from include file: %s", exclusion)
+			parser.logger.Debug().Msgf("Excluded entry from include file: %s", exclusion)
 		}
 	}
+	return nil
 }
 
-func buildinclusionLineMap(parser *Parser, includeFileName string) (inclusionLineMap, map[string]string) {
-	includeContent, definitions := parseFile(parser, includeFileName, nil)
+func buildinclusionLineMap(parser *Parser, includeFileName string, directive SourceLocation) (inclusionLineMap, map[string]string, error) {
+	includeContent, origins, definitions, err := parseFile(parser, includeFileName, directive, nil)
+	if err != nil {
+		return nil, nil, err
+	}
 	includeScanner := bufio.NewScanner(includeContent)
 	includeMap := make(inclusionLineMap, 100)
 	index := 0
 	for includeScanner.Scan() {
 		entry := includeScanner.Text()
-		includeMap[entry] = inclusionLine{entry, index}
+		includeMap[entry] = inclusionLine{entry, index, origins[index]}
 		index++
 	}
-	return includeMap, definitions
+	return includeMap, definitions, nil
 }
 
-func stringFromInclusionLines(inclusionLines inclusionLineSlice) string {
+// stringFromInclusionLines joins the lines in their original order and returns the result
+// and the origin of each line.
+func stringFromInclusionLines(inclusionLines inclusionLineSlice) (string, []SourceLocation) {
 	// Ensure that the last line is always empty.
 	// Corresponds to "regular" lines in the parser, to which `\n` is appended
 	switch len(inclusionLines) {
 	case 0:
-		return ""
+		return "", []SourceLocation{}
 	case 1:
-		return inclusionLines[0].line + "\n"
+		return inclusionLines[0].line + "\n", []SourceLocation{inclusionLines[0].origin}
 	}
 
 	sort.Sort(inclusionLines)
+	origins := make([]SourceLocation, 0, len(inclusionLines))
 	var stringBuilder strings.Builder
 	stringBuilder.Grow(len(inclusionLines) * 20)
 	stringBuilder.WriteString(inclusionLines[0].line)
+	origins = append(origins, inclusionLines[0].origin)
 	for _, h := range inclusionLines[1:] {
 		stringBuilder.WriteString("\n")
 		stringBuilder.WriteString(h.line)
+		origins = append(origins, h.origin)
 	}
 	stringBuilder.WriteString("\n")
 
-	return stringBuilder.String()
+	return stringBuilder.String(), origins
 }
@@ -81,7 +81,8 @@ a*b|include3`, s.excludeDir)
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal("leave me alone\n", actual.String())
 }
@@ -103,7 +104,8 @@ leave me alone
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal(`\s*include1
 leave me alone
@@ -127,7 +129,8 @@ a*b|include3`, s.excludeDir)
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal(`\s*include1`+"\n", actual.String())
 }
@@ -148,7 +151,8 @@ include3{{homer}}`, s.excludeDir)
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal("leave me alone\n", actual.String())
 }
@@ -163,7 +167,8 @@ func (s *parserIncludeExceptTestSuite) TestIncludeExcept_DontPanicWhenInclusions
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Empty(actual)
 }
@@ -178,7 +183,8 @@ func (s *parserIncludeExceptTestSuite) TestIncludeExcept_DontPanicWhenExclusions
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal("include1\n", actual.String())
 }
@@ -202,7 +208,8 @@ no suffix 2`,
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := `suffix with[\s><]
 suffix with[^\s]
 no suffix 2
@@ -230,7 +237,8 @@ no suffix 2`,
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := `suffix with
 suffix with
 no suffix 2
@@ -253,7 +261,8 @@ a*b|include3`, s.excludeDir)
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	s.Equal("leave me alone\n", actual.String())
 }
@@ -278,7 +287,8 @@ no suffix 2`,
 	defer assemblyFile.Close()
 
 	parser := NewParser(s.ctx, assemblyFile)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := `suffix with[\s><]
 suffix with[^\s]
 `
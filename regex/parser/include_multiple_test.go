@@ -67,7 +67,8 @@ func (s *parserMultiIncludeTestSuite) TearDownSuite() {
 
 func (s *parserMultiIncludeTestSuite) TestParserMultiInclude_FromMultiFile() {
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(
 		"This is comment 3.\n" +
 			"This is comment 2.\n" +
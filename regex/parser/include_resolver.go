@@ -0,0 +1,93 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/dependencies"
+)
+
+// includeResolver resolves the files referenced by include directives. A single resolver is
+// shared by the parser of a regex-assembly file and the parsers of all the files it includes,
+// so that it can track the stack of files that are being parsed, and collect the problems with
+// include directives across the whole run instead of stopping at the first one.
+//
+// With `localOnly`, only names that refer to files in the include and exclude directories are
+// resolved, see processors.Context.RestrictIncludes.
+type includeResolver struct {
+	rootContext *context.Context
+	localOnly   bool
+	active      []string
+	errors      []*ParseError
+}
+
+func newIncludeResolver(rootContext *context.Context, localOnly bool, fileName string) *includeResolver {
+	resolver := &includeResolver{
+		rootContext: rootContext,
+		localOnly:   localOnly,
+		active:      []string{},
+		errors:      []*ParseError{},
+	}
+	if fileName != "" {
+		resolver.active = append(resolver.active, absolutePath(fileName))
+	}
+	return resolver
+}
+
+// resolve returns the path of the file referenced by the include named `name`.
+func (r *includeResolver) resolve(name string) (string, error) {
+	if r.localOnly && !filepath.IsLocal(name) {
+		return "", fmt.Errorf("include %s is not allowed, only files in the include and exclude directories can be included", name)
+	}
+	filePath, err := dependencies.Resolve(r.rootContext, name)
+	if err != nil {
+		return "", fmt.Errorf("cannot open file for parsing: %w", err)
+	}
+	return filePath, nil
+}
+
+// enter pushes `filePath` onto the stack of files that are being parsed. If `filePath` is
+// already being parsed, the file is not pushed and an error describing the cycle is returned.
+// Paths are compared in their absolute form.
+func (r *includeResolver) enter(filePath string) error {
+	filePath = absolutePath(filePath)
+	if index := slices.Index(r.active, filePath); index >= 0 {
+		cycle := append(slices.Clone(r.active[index:]), filePath)
+		return &IncludeCycleError{Files: cycle}
+	}
+	r.active = append(r.active, filePath)
+	return nil
+}
+
+// leave pops the file on top of the stack of files that are being parsed.
+func (r *includeResolver) leave() {
+	r.active = r.active[:len(r.active)-1]
+}
+
+// absolutePath returns `filePath` as an absolute path, so that the same file is recognized
+// however it was named, or the cleaned `filePath` if there is no absolute path.
+func absolutePath(filePath string) string {
+	absolute, err := filepath.Abs(filePath)
+	if err != nil {
+		return filepath.Clean(filePath)
+	}
+	return absolute
+}
+
+// report records a problem with an include directive.
+func (r *includeResolver) report(err *ParseError) {
+	r.errors = append(r.errors, err)
+}
+
+// err returns an *IncludeError with all the problems that were reported, or nil if there were none.
+func (r *includeResolver) err() error {
+	if len(r.errors) == 0 {
+		return nil
+	}
+	return &IncludeError{Errors: r.errors}
+}
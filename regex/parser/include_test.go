@@ -60,7 +60,8 @@ func (s *parserIncludeTestSuite) TearDownTest() {
 func (s *parserIncludeTestSuite) TestParserInclude_FromFile() {
 	s.writeDataFile("This data comes from the include file.\n", "##!This is a comment\n")
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString("This data comes from the include file.\n")
 
 	s.Equal(expected.String(), actual.String())
@@ -71,7 +72,8 @@ func (s *parserIncludeTestSuite) TestParserInclude_Prefixes() {
 ##!^ prefix2
 included regex`, "data regex")
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`##!> assemble
 prefix1
 ##!=>
@@ -89,7 +91,8 @@ func (s *parserIncludeTestSuite) TestParserInclude_Suffixes() {
 ##!$ suffix2
 included regex`, "data regex")
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`##!> assemble
 included regex
 ##!=>
@@ -111,7 +114,8 @@ func (s *parserIncludeTestSuite) TestParserInclude_FlagsPrefixesSuffixes() {
 ##!^ prefix2
 included regex`, "data regex")
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`##!> assemble
 prefix1
 ##!=>
@@ -142,7 +146,8 @@ no suffix 2`)
 		"@", `[\s><]`,
 		"~", `[^\s]`))
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`no suffix1
 suffix with[\s><]
 suffix with[^\s]
@@ -164,7 +169,8 @@ no suffix 2`)
 		"  @", `[\s><]  `,
 		"~   ", `   [^\s]`))
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`no suffix1
 suffix with[\s><]
 suffix with[^\s]
@@ -186,7 +192,8 @@ no suffix 2`)
 		"@", `""`,
 		"~", `""`))
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(`no suffix1
 suffix with
 suffix with
@@ -195,3 +202,24 @@ no suffix 2
 
 	s.Equal(expected.String(), actual.String())
 }
+
+func (s *parserIncludeTestSuite) TestParserInclude_RestrictedIncludesByName() {
+	s.ctx.RestrictIncludes()
+	_, err := s.includeFile.WriteString("homer\n")
+	s.Require().NoError(err, "writing temp include file failed")
+
+	parser := NewParser(s.ctx, strings.NewReader("##!> include test\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("homer\n", actual.String())
+}
+
+func (s *parserIncludeTestSuite) TestParserInclude_RestrictedIncludesByPath() {
+	s.ctx.RestrictIncludes()
+	s.writeDataFile("homer\n", "")
+
+	parser := NewParser(s.ctx, s.reader)
+	_, err := parser.Parse(false)
+	s.ErrorContains(err, "is not allowed")
+}
@@ -36,7 +36,8 @@ func TestRunParserIncludeWithDefinitionsTestSuite(t *testing.T) {
 
 func (s *parserIncludeWithDefinitions) TestParser_IncludeWithDefinitions() {
 	parser := NewParser(s.ctx, s.reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString(
 		"This is comment 3.\n" +
 			"[a-zA-J]+8 to see if definitions work when included\n" +
@@ -63,7 +64,9 @@ func (s *parserIncludeWithDefinitions) TestParser_DanglingDefinitions() {
 	rootContext := context.New(os.TempDir(), "toolchain.yaml")
 	parser := NewParser(processors.NewContext(rootContext), reader)
 
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString("world\n{{hallo}}\n")
 	s.Equal(expected.String(), actual.String())
 
@@ -0,0 +1,29 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import "fmt"
+
+// unnamedInput is used in place of a file name when the parsed input was not read from a file,
+// e.g., when reading from stdin.
+const unnamedInput = "<input>"
+
+// SourceLocation identifies a position in a regex-assembly file. Lines and columns are 1-based.
+// A column of 0 means that the column is unknown.
+type SourceLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+func (l SourceLocation) String() string {
+	file := l.File
+	if file == "" {
+		file = unnamedInput
+	}
+	if l.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", file, l.Line, l.Column)
+	}
+	return fmt.Sprintf("%s:%d", file, l.Line)
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type parserOriginsTestSuite struct {
+	suite.Suite
+	ctx        *processors.Context
+	includeDir string
+	excludeDir string
+}
+
+func TestRunParserOriginsTestSuite(t *testing.T) {
+	suite.Run(t, new(parserOriginsTestSuite))
+}
+
+func (s *parserOriginsTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.includeDir = path.Join(rootDir, "regex-assembly", "include")
+	err := os.MkdirAll(s.includeDir, fs.ModePerm)
+	s.Require().NoError(err)
+	s.excludeDir = path.Join(rootDir, "regex-assembly", "exclude")
+	err = os.MkdirAll(s.excludeDir, fs.ModePerm)
+	s.Require().NoError(err)
+
+	rootContext := context.New(rootDir, "toolchain.yaml")
+	s.ctx = processors.NewContext(rootContext)
+}
+
+func (s *parserOriginsTestSuite) writeFile(directory string, name string, contents string) string {
+	filePath := path.Join(directory, name)
+	err := os.WriteFile(filePath, []byte(contents), fs.ModePerm)
+	s.Require().NoError(err)
+	return filePath
+}
+
+func (s *parserOriginsTestSuite) TestOrigins_Regular() {
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##! comment\nfoo\n\n  bar\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("foo\nbar\n", actual.String())
+	s.Equal([]SourceLocation{
+		{File: "123456.ra", Line: 2},
+		{File: "123456.ra", Line: 4},
+	}, parser.Origins())
+}
+
+func (s *parserOriginsTestSuite) TestOrigins_Include() {
+	includePath := s.writeFile(s.includeDir, "include.ra", "##! comment\ninc1\ninc2\n")
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("foo\n##!> include include\nbar\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("foo\ninc1\ninc2\nbar\n", actual.String())
+	s.Equal([]SourceLocation{
+		{File: "123456.ra", Line: 1},
+		{File: includePath, Line: 2},
+		{File: includePath, Line: 3},
+		{File: "123456.ra", Line: 3},
+	}, parser.Origins())
+}
+
+func (s *parserOriginsTestSuite) TestOrigins_IncludeWithPrefix() {
+	includePath := s.writeFile(s.includeDir, "include.ra", "##!^ prefix\ninc1\n")
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include include\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("##!> assemble\nprefix\n##!=>\ninc1\n##!<\n", actual.String())
+	s.Equal([]SourceLocation{
+		{File: "123456.ra", Line: 1},
+		{File: includePath, Line: 1},
+		{File: "123456.ra", Line: 1},
+		{File: includePath, Line: 2},
+		{File: "123456.ra", Line: 1},
+	}, parser.Origins())
+}
+
+func (s *parserOriginsTestSuite) TestOrigins_IncludeExcept() {
+	includePath := s.writeFile(s.includeDir, "include.ra", "inc1\ninc2\ninc3\n")
+	s.writeFile(s.excludeDir, "exclude.ra", "inc2\n")
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("##!> include-except include exclude\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("inc1\ninc3\n", actual.String())
+	s.Equal([]SourceLocation{
+		{File: includePath, Line: 1},
+		{File: includePath, Line: 3},
+	}, parser.Origins())
+}
@@ -10,14 +10,14 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
-	"path"
-	"path/filepath"
 	"regexp"
 	"strings"
 
 	"dario.cat/mergo"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/coreruleset/crs-toolchain/v2/regex"
@@ -37,6 +37,8 @@ const (
 	flagsPatternName         string     = "flags"
 	prefixPatternName        string     = "prefix"
 	suffixPatternName        string     = "suffix"
+	testVectorPatternName    string     = "test-vector"
+	outputPatternName        string     = "output"
 	regular                  parsedType = iota
 	empty
 	include
@@ -46,18 +48,39 @@ const (
 	flags
 	prefix
 	suffix
+	testVector
+	output
+)
+
+// Output kinds that regex-assembly files can declare (##!> output <kind>), see `Parser.Output`
+const (
+	// RegexOutput is the default output, a regular expression for @rx
+	RegexOutput = "regex"
+	// PhrasesOutput is a list of literal phrases for @pm or @pmFromFile
+	PhrasesOutput = "phrases"
 )
 
 // Parser is the base parser type. It will provide processors with all the inclusions and definitions resolved.
 type Parser struct {
-	ctx       *processors.Context
-	src       io.Reader
-	dest      *bytes.Buffer
-	variables map[string]string
-	Flags     map[rune]bool
-	Prefixes  []string
-	Suffixes  []string
-	patterns  map[string]*regexp.Regexp
+	ctx           *processors.Context
+	src           io.Reader
+	dest          *bytes.Buffer
+	variables     map[string]string
+	Flags         map[rune]bool
+	Prefixes      []string
+	Suffixes      []string
+	Output        string
+	testVectors   []TestVector
+	patterns      map[string]*regexp.Regexp
+	fileName      string
+	resolver      *includeResolver
+	includeStack  []SourceLocation
+	origins       []SourceLocation
+	prefixOrigins []SourceLocation
+	suffixOrigins []SourceLocation
+	currentLine   int
+	currentIndent int
+	logger        *zerolog.Logger
 }
 
 // ParsedLine will store the results of parsing the line. `parsedType` will discriminate how you read the results:
@@ -66,6 +89,8 @@ type Parser struct {
 type ParsedLine struct {
 	parsedType         parsedType
 	line               string
+	submatches         []int
+	excludeOffsets     []int
 	includeFileName    string
 	excludeFileNames   []string
 	suffixReplacements map[string]string
@@ -73,18 +98,31 @@ type ParsedLine struct {
 	prefix             string
 	suffix             string
 	flags              string
+	testVector         TestVector
+	output             string
 }
 
 // NewParser creates a new parser from an io.Reader.
 func NewParser(ctx *processors.Context, reader io.Reader) *Parser {
+	return NewParserForFile(ctx, "", reader)
+}
+
+// NewParserForFile creates a new parser from an io.Reader that reads the contents of the named file.
+// The file name is only used to report the location of errors.
+func NewParserForFile(ctx *processors.Context, fileName string, reader io.Reader) *Parser {
 	p := &Parser{
-		ctx:       ctx,
-		src:       reader,
-		dest:      &bytes.Buffer{},
-		variables: make(map[string]string),
-		Flags:     make(map[rune]bool),
-		Prefixes:  []string{},
-		Suffixes:  []string{},
+		ctx:          ctx,
+		src:          reader,
+		dest:         &bytes.Buffer{},
+		variables:    make(map[string]string),
+		Flags:        make(map[rune]bool),
+		Prefixes:     []string{},
+		Suffixes:     []string{},
+		Output:       RegexOutput,
+		fileName:     fileName,
+		includeStack: []SourceLocation{},
+		origins:      []SourceLocation{},
+		logger:       ctx.Logger(&logger),
 		patterns: map[string]*regexp.Regexp{
 			includePatternName:       regex.IncludeRegex,
 			includeExceptPatternName: regex.IncludeExceptRegex,
@@ -93,23 +131,38 @@ func NewParser(ctx *processors.Context, reader io.Reader) *Parser {
 			flagsPatternName:         regex.FlagsRegex,
 			prefixPatternName:        regex.PrefixRegex,
 			suffixPatternName:        regex.SuffixRegex,
+			testVectorPatternName:    regex.TestVectorRegex,
+			outputPatternName:        regex.OutputRegex,
 		},
 	}
 	return p
 }
 
 // Parse does the parsing and returns a buffer with all the bytes to process.
-func (p *Parser) Parse(formatOnly bool) *bytes.Buffer {
+// Any problem with the input is reported as a *ParseError. Missing and cyclic includes don't stop
+// the parser, they are collected and reported together as an *IncludeError once parsing is done.
+func (p *Parser) Parse(formatOnly bool) (*bytes.Buffer, error) {
+	isRoot := p.resolver == nil
+	if isRoot {
+		p.resolver = newIncludeResolver(p.ctx.RootContext(), p.ctx.IncludesRestricted(), p.fileName)
+	}
 	fileScanner := bufio.NewScanner(p.src)
 	var text string
+	var origins []SourceLocation
 
 	for fileScanner.Scan() {
-		line := fileScanner.Text()
+		p.currentLine++
+		rawLine := fileScanner.Text()
 		// remove indentation
-		line = strings.TrimLeft(line, " \t")
+		line := strings.TrimLeft(rawLine, " \t")
+		p.currentIndent = len(rawLine) - len(line)
 		text = "" // empty text each iteration
-		logger.Trace().Msgf("parsing line: %q", line)
-		parsedLine := p.parseLine(line)
+		origins = []SourceLocation{p.lineOrigin()}
+		p.logger.Trace().Msgf("parsing line: %q", line)
+		parsedLine, err := p.parseLine(line)
+		if err != nil {
+			return nil, err
+		}
 		switch parsedLine.parsedType {
 		case regular:
 			text = line + "\n"
@@ -120,87 +173,164 @@ func (p *Parser) Parse(formatOnly bool) *bytes.Buffer {
 				// merge maps p.variables and parsedLine.definitions
 				err := mergo.Merge(&p.variables, parsedLine.definitions)
 				if err != nil {
-					logger.Error().Err(err).Msg("error merging definitions")
+					p.logger.Error().Err(err).Msg("error merging definitions")
 				}
 			}
 		case include:
 			if !formatOnly {
 				// go read the included file and paste text here
-				var err error
-				text, err = buildIncludeString(p, parsedLine)
+				text, origins, err = buildIncludeString(p, parsedLine)
 				if err != nil {
-					logger.Panic().Err(err).Msg("Failed to parse `include` directive")
+					return nil, err
 				}
 			}
 		case includeExcept:
 			if !formatOnly {
 				// go read the included files but exclude exclusions
-				var err error
-				text, err = buildIncludeExceptString(p, parsedLine)
+				text, origins, err = buildIncludeExceptString(p, parsedLine)
 				if err != nil {
-					logger.Panic().Err(err).Msg("Failed to parse `include-except` directive")
+					return nil, err
 				}
 			}
 		case flags:
-			for _, flag := range parsedLine.flags {
+			flagsOffset := parsedLine.submatches[2]
+			for i, flag := range parsedLine.flags {
 				if flagIsAllowed(flag) {
 					p.Flags[flag] = true
 				} else {
-					logger.Panic().Msgf("flag '%s' is not supported", string(flag))
+					return nil, p.newParseError(flagsOffset+i, fmt.Errorf("flag '%s' is not supported", string(flag)))
 				}
 			}
 		case prefix:
 			p.Prefixes = append(p.Prefixes, parsedLine.prefix)
+			p.prefixOrigins = append(p.prefixOrigins, p.lineOrigin())
 		case suffix:
 			p.Suffixes = append(p.Suffixes, parsedLine.suffix)
+			p.suffixOrigins = append(p.suffixOrigins, p.lineOrigin())
+		case testVector:
+			p.testVectors = append(p.testVectors, parsedLine.testVector)
+		case output:
+			p.Output = parsedLine.output
 		}
 		if formatOnly {
 			text = line + "\n"
+			origins = []SourceLocation{p.lineOrigin()}
 		} else if text == "" {
 			continue
 		}
 
-		logger.Trace().Msgf("** ADDING text: %q", text)
+		p.logger.Trace().Msgf("** ADDING text: %q", text)
 		// err is always nil
 		p.dest.WriteString(text)
+		p.origins = append(p.origins, origins...)
 
 	}
+	if err := fileScanner.Err(); err != nil {
+		return nil, p.newParseError(0, err)
+	}
+	if isRoot {
+		if err := p.resolver.err(); err != nil {
+			return nil, err
+		}
+	}
 
 	// now that the file was parsed, we replace all definitions
 	if len(p.variables) > 0 {
-		p.dest = expandDefinitions(p.dest, p.variables)
+		p.dest = p.expandDefinitions(p.dest, p.variables)
+	}
+	return p.dest, nil
+}
+
+// Origins returns the origin of each line in the buffer returned by `Parse`, i.e., the n-th entry is the
+// location in the source files that produced the n-th line of output. Lines pulled in by `include` and
+// `include-except` directives point to the included file. Lines that were generated by the parser, e.g.,
+// to wrap included files with prefixes or suffixes, point to the including directive.
+func (p *Parser) Origins() []SourceLocation {
+	return p.origins
+}
+
+// TestVectors returns the test vectors (##!? match <input>, ##!? nomatch <input>) of the parsed file.
+// Test vectors of included files are not part of the result, as they apply to the included file on
+// its own.
+func (p *Parser) TestVectors() []TestVector {
+	return p.testVectors
+}
+
+// lineOrigin returns the origin of the line that is currently being parsed.
+func (p *Parser) lineOrigin() SourceLocation {
+	return SourceLocation{
+		File: p.fileName,
+		Line: p.currentLine,
+	}
+}
+
+// location returns the location of the byte at `offset` in the (unindented) line that is currently
+// being parsed.
+func (p *Parser) location(offset int) SourceLocation {
+	column := 0
+	if offset >= 0 {
+		column = p.currentIndent + offset + 1
+	}
+	return SourceLocation{
+		File:   p.fileName,
+		Line:   p.currentLine,
+		Column: column,
+	}
+}
+
+// newParseError wraps `err` in a *ParseError pointing to the byte at `offset` in the line that is
+// currently being parsed. Errors that already are parse errors are passed through untouched, as they
+// already point to the correct location.
+func (p *Parser) newParseError(offset int, err error) error {
+	var parseError *ParseError
+	if errors.As(err, &parseError) {
+		return err
+	}
+	return &ParseError{
+		Location:     p.location(offset),
+		IncludeStack: p.includeStack,
+		Err:          err,
 	}
-	return p.dest
 }
 
 // parseLine iterates over the pattern list and if found, creates the ParsedLine object with the results.
-func (p *Parser) parseLine(line string) ParsedLine {
+func (p *Parser) parseLine(line string) (ParsedLine, error) {
 	pl := ParsedLine{
 		parsedType: regular,
 		line:       line,
 	}
 	if len(strings.TrimSpace(line)) == 0 {
 		pl.parsedType = empty
-		return pl
+		return pl, nil
 	}
 
 	for name, pattern := range p.patterns {
-		found := pattern.FindStringSubmatch(line)
+		submatches := pattern.FindStringSubmatchIndex(line)
 		// found[0] has the whole line that matched, found[N] has the subgroup
-		if len(found) > 0 {
-			logger.Trace().Msgf("found %s statement: %v", name, found[0])
+		if len(submatches) > 0 {
+			found := submatchStrings(line, submatches)
+			pl.submatches = submatches
+			p.logger.Trace().Msgf("found %s statement: %v", name, found[0])
+			var err error
 			switch name {
 			case commentPatternName:
 				pl.parsedType = comment
 			case includePatternName:
 				pl.parsedType = include
 				pl.includeFileName = found[1]
-				pl.suffixReplacements = buildPairMap(found[2])
+				pl.suffixReplacements, err = p.buildPairMap(found[2])
+				if err != nil {
+					return pl, p.newParseError(submatches[4], err)
+				}
 			case includeExceptPatternName:
 				pl.parsedType = includeExcept
 				pl.includeFileName = found[1]
-				pl.suffixReplacements = buildPairMap(found[3])
+				pl.suffixReplacements, err = p.buildPairMap(found[3])
+				if err != nil {
+					return pl, p.newParseError(submatches[6], err)
+				}
 				pl.excludeFileNames = splitArgs(found[2])
+				pl.excludeOffsets = argumentOffsets(line, submatches[4], pl.excludeFileNames)
 			case definitionPatternName:
 				pl.parsedType = definition
 				pl.definitions = map[string]string{found[2]: found[3]}
@@ -213,22 +343,67 @@ func (p *Parser) parseLine(line string) ParsedLine {
 			case suffixPatternName:
 				pl.parsedType = suffix
 				pl.suffix = found[1]
+			case outputPatternName:
+				pl.parsedType = output
+				pl.output = found[1]
+				if pl.output != RegexOutput && pl.output != PhrasesOutput {
+					return pl, p.newParseError(submatches[2], fmt.Errorf("unknown output '%s', expected '%s' or '%s'", pl.output, RegexOutput, PhrasesOutput))
+				}
+			case testVectorPatternName:
+				pl.parsedType = testVector
+				pl.testVector.Location = p.lineOrigin()
+				pl.testVector.Match, err = parseTestVectorKind(found[1])
+				if err != nil {
+					return pl, p.newParseError(submatches[2], err)
+				}
+				pl.testVector.Input, err = parseTestVectorInput(found[2])
+				if err != nil {
+					return pl, p.newParseError(submatches[4], err)
+				}
 			}
 			break
 		}
 	}
-	return pl
+	return pl, nil
+}
+
+// submatchStrings converts the result of `FindStringSubmatchIndex` to the equivalent
+// result of `FindStringSubmatch`.
+func submatchStrings(line string, submatches []int) []string {
+	found := make([]string, len(submatches)/2)
+	for i := range found {
+		if submatches[2*i] >= 0 {
+			found[i] = line[submatches[2*i]:submatches[2*i+1]]
+		}
+	}
+	return found
 }
 
-func buildPairMap(input string) map[string]string {
+// argumentOffsets returns the offsets of `args` in `line`, starting the search at `start`.
+func argumentOffsets(line string, start int, args []string) []int {
+	offsets := make([]int, 0, len(args))
+	for _, arg := range args {
+		index := strings.Index(line[start:], arg)
+		if index < 0 {
+			offsets = append(offsets, -1)
+			continue
+		}
+		start += index
+		offsets = append(offsets, start)
+		start += len(arg)
+	}
+	return offsets
+}
+
+func (p *Parser) buildPairMap(input string) (map[string]string, error) {
 	if len(strings.TrimSpace(input)) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	logger.Trace().Msgf("Building pair map for: %s", input)
+	p.logger.Trace().Msgf("Building pair map for: %s", input)
 	list := splitArgs(input)
 	if len(list)%2 > 0 {
-		logger.Panic().Msgf("uneven number of arguments found: %s", input)
+		return nil, fmt.Errorf("uneven number of arguments found: %s", input)
 	}
 
 	pairMap := map[string]string{}
@@ -236,8 +411,8 @@ func buildPairMap(input string) map[string]string {
 		pairMap[list[i]] = list[i+1]
 	}
 
-	logger.Trace().Msgf("Built pair map: %v", pairMap)
-	return pairMap
+	p.logger.Trace().Msgf("Built pair map: %v", pairMap)
+	return pairMap, nil
 }
 
 func splitArgs(input string) []string {
@@ -246,69 +421,89 @@ func splitArgs(input string) []string {
 }
 
 // parseFile does just a new call to the Parser on the named file. It will use the context to find files that have relative filenames.
-func parseFile(rootParser *Parser, filename string, definitions map[string]string) (*bytes.Buffer, map[string]string) {
-	logger.Debug().Msgf("reading file: %v", filename)
-	if path.Ext(filename) != ".ra" {
-		filename += ".ra"
-	}
-
-	// check if filename has an absolute path
-	// if it is relative, use the context to get the parent directory where we should search for the file.
-	rootContext := rootParser.ctx.RootContext()
-	var err error
-	var readFile *os.File
-	filePath := filename
-	for _, directory := range []string{rootContext.IncludesDir(), rootContext.ExcludesDir()} {
-		if !filepath.IsAbs(filename) {
-			filePath = filepath.Join(directory, filename)
-		}
-		readFile, err = os.Open(filePath)
-		if err == nil {
-			break
-		}
+// `directive` is the location of the directive in the parent parser that references the file. It is used to report errors.
+// Missing files and include cycles are reported to the resolver and yield empty output, so that parsing can continue.
+func parseFile(rootParser *Parser, filename string, directive SourceLocation, definitions map[string]string) (*bytes.Buffer, []SourceLocation, map[string]string, error) {
+	rootParser.logger.Debug().Msgf("reading file: %v", filename)
+	resolver := rootParser.resolver
+	filePath, err := resolver.resolve(filename)
+	if err == nil {
+		err = resolver.enter(filePath)
 	}
 	if err != nil {
-		logger.Fatal().Msgf("cannot open file for parsing: %v", err.Error())
+		resolver.report(&ParseError{
+			Location:     directive,
+			IncludeStack: rootParser.includeStack,
+			Err:          err,
+		})
+		return new(bytes.Buffer), nil, definitions, nil
 	}
-	newP := NewParser(rootParser.ctx, bufio.NewReader(readFile))
+	defer resolver.leave()
+
+	readFile, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, &ParseError{
+			Location:     directive,
+			IncludeStack: rootParser.includeStack,
+			Err:          fmt.Errorf("cannot open file for parsing: %w", err),
+		}
+	}
+	defer readFile.Close()
+
+	newP := NewParserForFile(rootParser.ctx, filePath, bufio.NewReader(readFile))
+	newP.resolver = resolver
+	newP.includeStack = append(append(newP.includeStack, rootParser.includeStack...), directive)
 	if definitions != nil {
 		newP.variables = definitions
 	}
-	out := newP.Parse(false)
-	newOut, err := mergePrefixesSuffixes(newP, out)
+	out, err := newP.Parse(false)
 	if err != nil {
-		logger.Fatal().Msgf("error parsing file: %v", err.Error())
+		return nil, nil, nil, err
+	}
+	newOut, origins, err := mergePrefixesSuffixes(newP, out, directive)
+	if err != nil {
+		return nil, nil, nil, &ParseError{
+			Location:     directive,
+			IncludeStack: rootParser.includeStack,
+			Err:          fmt.Errorf("error parsing file %s: %w", filePath, err),
+		}
 	}
-	logger.Trace().Msg(newOut.String())
-	return newOut, newP.variables
+	rootParser.logger.Trace().Msg(newOut.String())
+	return newOut, origins, newP.variables, nil
 }
 
 // Merge prefixes, and suffixes from include files into another parser.
 // All of these need to be treated as local to the source parser.
 // We removed flag merging because of https://github.com/coreruleset/crs-toolchain/issues/72
-func mergePrefixesSuffixes(source *Parser, out *bytes.Buffer) (*bytes.Buffer, error) {
-	logger.Trace().Msg("merging prefixes, suffixes from included file")
+// Returns the merged output and the origins of its lines. Lines that are generated here point to `directive`.
+func mergePrefixesSuffixes(source *Parser, out *bytes.Buffer, directive SourceLocation) (*bytes.Buffer, []SourceLocation, error) {
+	source.logger.Trace().Msg("merging prefixes, suffixes from included file")
 	// If the included file has flags, this is an error
 	if len(source.Flags) > 0 {
-		return new(bytes.Buffer), errors.New("include files must not contain flags. See https://github.com/coreruleset/crs-toolchain/v2/issues/71")
+		return new(bytes.Buffer), nil, errors.New("include files must not contain flags. See https://github.com/coreruleset/crs-toolchain/v2/issues/71")
 	}
 	// IMPORTANT: don't write the assemble block at all if there are no flags, prefixes, or
 	// suffixes. Enclosing the output in an assemble block can change the semantics, for example,
 	// when the included content is processed by the cmdline processor in the including file.
 	if len(source.Prefixes) == 0 && len(source.Suffixes) == 0 {
-		return out, nil
+		return out, source.origins, nil
 	}
 
+	directive.Column = 0
+	origins := make([]SourceLocation, 0, len(source.origins)+2*len(source.Prefixes)+2*len(source.Suffixes)+3)
 	newOut := new(bytes.Buffer)
 	newOut.WriteString("##!> assemble\n")
+	origins = append(origins, directive)
 
-	for _, prefix := range source.Prefixes {
+	for i, prefix := range source.Prefixes {
 		newOut.WriteString(prefix)
 		newOut.WriteString("\n##!=>\n")
+		origins = append(origins, source.prefixOrigins[i], directive)
 	}
 	if _, err := out.WriteTo(newOut); err != nil {
-		logger.Fatal().Err(err).Msg("failed to copy output to new buffer")
+		return nil, nil, fmt.Errorf("failed to copy output to new buffer: %w", err)
 	}
+	origins = append(origins, source.origins...)
 
 	sawNewLine := false
 	if err := out.UnreadByte(); err == nil {
@@ -319,20 +514,24 @@ func mergePrefixesSuffixes(source *Parser, out *bytes.Buffer) (*bytes.Buffer, er
 	}
 	if sawNewLine {
 		newOut.WriteString("\n")
+		origins = append(origins, directive)
 	}
 	if len(source.Suffixes) > 0 {
 		newOut.WriteString("##!=>\n")
+		origins = append(origins, directive)
 	}
-	for _, suffix := range source.Suffixes {
+	for i, suffix := range source.Suffixes {
 		newOut.WriteString(suffix)
 		newOut.WriteString("\n##!=>\n")
+		origins = append(origins, source.suffixOrigins[i], directive)
 	}
 	newOut.WriteString("##!<\n")
-	return newOut, nil
+	origins = append(origins, directive)
+	return newOut, origins, nil
 }
 
-func expandDefinitions(src *bytes.Buffer, variables map[string]string) *bytes.Buffer {
-	logger.Trace().Msgf("expanding definitions in: %v", src.String())
+func (p *Parser) expandDefinitions(src *bytes.Buffer, variables map[string]string) *bytes.Buffer {
+	p.logger.Trace().Msgf("expanding definitions in: %v", src.String())
 	// Definitions can contain definitions themeselves
 	for needle, replacement := range variables {
 		needle := "{{" + needle + "}}"
@@ -349,9 +548,9 @@ func expandDefinitions(src *bytes.Buffer, variables map[string]string) *bytes.Bu
 	// yet, or there is a typo.
 	dangling := regex.DefinitionReferenceRegex.FindSubmatch(src.Bytes())
 	if dangling != nil {
-		logger.Warn().Msgf("no match found for definition: {{%s}}. could be a typo, or you forgot to define it?", string(dangling[1]))
+		p.logger.Warn().Msgf("no match found for definition: {{%s}}. could be a typo, or you forgot to define it?", string(dangling[1]))
 	}
-	logger.Trace().Msgf("expanded all definitions in: %v", src.String())
+	p.logger.Trace().Msgf("expanded all definitions in: %v", src.String())
 	return src
 }
 
@@ -30,13 +30,17 @@ func TestRunParserTestSuite(t *testing.T) {
 func (s *parserTestSuite) TestParser_NewParser() {
 	rootContext := context.New(os.TempDir(), "toolchain.yaml")
 	expected := &Parser{
-		ctx:       processors.NewContext(rootContext),
-		src:       s.reader,
-		dest:      &bytes.Buffer{},
-		Flags:     make(map[rune]bool),
-		Prefixes:  []string{},
-		Suffixes:  []string{},
-		variables: make(map[string]string),
+		ctx:          processors.NewContext(rootContext),
+		src:          s.reader,
+		dest:         &bytes.Buffer{},
+		Flags:        make(map[rune]bool),
+		Prefixes:     []string{},
+		Suffixes:     []string{},
+		Output:       RegexOutput,
+		variables:    make(map[string]string),
+		includeStack: []SourceLocation{},
+		origins:      []SourceLocation{},
+		logger:       &logger,
 		patterns: map[string]*regexp.Regexp{
 			includePatternName:       regex.IncludeRegex,
 			includeExceptPatternName: regex.IncludeExceptRegex,
@@ -45,6 +49,8 @@ func (s *parserTestSuite) TestParser_NewParser() {
 			flagsPatternName:         regex.FlagsRegex,
 			prefixPatternName:        regex.PrefixRegex,
 			suffixPatternName:        regex.SuffixRegex,
+			testVectorPatternName:    regex.TestVectorRegex,
+			outputPatternName:        regex.OutputRegex,
 		},
 	}
 	actual := NewParser(processors.NewContext(rootContext), s.reader)
@@ -57,7 +63,9 @@ func (s *parserTestSuite) TestParser_ParseTwoComments() {
 	rootContext := context.New(os.TempDir(), "toolchain.yaml")
 	parser := NewParser(processors.NewContext(rootContext), reader)
 
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+
+	s.Require().NoError(err)
 	expected := bytes.NewBufferString("")
 
 	s.Equal(expected.String(), actual.String())
@@ -68,18 +76,57 @@ func (s *parserTestSuite) TestIgnoresEmptyLines() {
 	reader := strings.NewReader(contents)
 	rootContext := context.New(os.TempDir(), "toolchain.yaml")
 	parser := NewParser(processors.NewContext(rootContext), reader)
-	actual := parser.Parse(false)
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
 
 	expected := "some line\nanother line\n"
 	s.Equal(expected, actual.String())
 }
 
-func (s *parserTestSuite) TestPanicsOnUnrecognizedFlag() {
+func (s *parserTestSuite) TestFailsOnUnrecognizedFlag() {
 	contents := "##!+ flag"
 	reader := strings.NewReader(contents)
 	rootContext := context.New(os.TempDir(), "toolchain.yaml")
-	parser := NewParser(processors.NewContext(rootContext), reader)
+	parser := NewParserForFile(processors.NewContext(rootContext), "123456.ra", reader)
+
+	_, err := parser.Parse(false)
+	s.Require().Error(err, "should fail because flags are not supported")
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: "123456.ra", Line: 1, Column: 6}, parseError.Location)
+	s.Empty(parseError.IncludeStack)
+	s.Equal("123456.ra:1:6: flag 'f' is not supported", err.Error())
+}
+
+func (s *parserTestSuite) TestParsesOutput() {
+	contents := "##!> output phrases\nwget\n"
+	rootContext := context.New(os.TempDir(), "toolchain.yaml")
+	parser := NewParser(processors.NewContext(rootContext), strings.NewReader(contents))
+
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+	s.Equal(PhrasesOutput, parser.Output)
+	s.Equal("wget\n", actual.String())
+}
 
-	s.PanicsWithValue("flag 'f' is not supported", func() { parser.Parse(false) }, "should panic because flags are not supported")
+func (s *parserTestSuite) TestFailsOnUnknownOutput() {
+	contents := "##!> output words"
+	rootContext := context.New(os.TempDir(), "toolchain.yaml")
+	parser := NewParserForFile(processors.NewContext(rootContext), "123456.ra", strings.NewReader(contents))
+
+	_, err := parser.Parse(false)
+	s.EqualError(err, "123456.ra:1:13: unknown output 'words', expected 'regex' or 'phrases'")
+}
+
+func (s *parserTestSuite) TestFailsOnUnevenSuffixReplacements() {
+	contents := "##! comment\n  ##!> include foo -- @"
+	reader := strings.NewReader(contents)
+	rootContext := context.New(os.TempDir(), "toolchain.yaml")
+	parser := NewParser(processors.NewContext(rootContext), reader)
 
+	_, err := parser.Parse(false)
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(SourceLocation{File: "", Line: 2, Column: 23}, parseError.Location)
+	s.Equal("<input>:2:23: uneven number of arguments found: @", err.Error())
 }
@@ -0,0 +1,63 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	matchVectorKind   = "match"
+	noMatchVectorKind = "nomatch"
+)
+
+// TestVector is an input that the regular expression generated from a regex-assembly file must
+// match (##!? match <input>), or must not match (##!? nomatch <input>). The input is the rest of
+// the line after the kind. Inputs enclosed in double quotes are unquoted with Go syntax, which
+// allows inputs with leading or trailing whitespace and with escape sequences, e.g., "\n".
+type TestVector struct {
+	Match    bool
+	Input    string
+	Location SourceLocation
+}
+
+func (v TestVector) String() string {
+	kind := noMatchVectorKind
+	if v.Match {
+		kind = matchVectorKind
+	}
+	return fmt.Sprintf("%s %q", kind, v.Input)
+}
+
+// Verify returns whether `expression` behaves as expected by the vector.
+func (v TestVector) Verify(expression *regexp.Regexp) bool {
+	return expression.MatchString(v.Input) == v.Match
+}
+
+// parseTestVectorKind returns whether `kind` is the kind of a vector that must match.
+func parseTestVectorKind(kind string) (bool, error) {
+	switch kind {
+	case matchVectorKind:
+		return true, nil
+	case noMatchVectorKind:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown test vector kind '%s', expected '%s' or '%s'", kind, matchVectorKind, noMatchVectorKind)
+	}
+}
+
+// parseTestVectorInput unquotes `input` if it is enclosed in double quotes.
+func parseTestVectorInput(input string) (string, error) {
+	if len(input) < 2 || !strings.HasPrefix(input, `"`) || !strings.HasSuffix(input, `"`) {
+		return input, nil
+	}
+	unquoted, err := strconv.Unquote(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted test vector input %s", input)
+	}
+	return unquoted, nil
+}
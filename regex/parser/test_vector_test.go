@@ -0,0 +1,110 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package parser
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/regex/processors"
+)
+
+type testVectorTestSuite struct {
+	suite.Suite
+	ctx        *processors.Context
+	includeDir string
+}
+
+func TestRunTestVectorTestSuite(t *testing.T) {
+	suite.Run(t, new(testVectorTestSuite))
+}
+
+func (s *testVectorTestSuite) SetupTest() {
+	rootDir := s.T().TempDir()
+	s.includeDir = path.Join(rootDir, "regex-assembly", "include")
+	s.Require().NoError(os.MkdirAll(s.includeDir, fs.ModePerm))
+	s.ctx = processors.NewContext(context.New(rootDir, "toolchain.yaml"))
+}
+
+func (s *testVectorTestSuite) TestCollectsTestVectors() {
+	contents := `##!? match foo bar
+foo
+##!> assemble
+  ##!? nomatch "\tbaz "
+  bar
+##!<
+##!? nomatch
+`
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader(contents))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("foo\n##!> assemble\nbar\n##!<\n", actual.String())
+	s.Equal([]TestVector{
+		{Match: true, Input: "foo bar", Location: SourceLocation{File: "123456.ra", Line: 1}},
+		{Match: false, Input: "\tbaz ", Location: SourceLocation{File: "123456.ra", Line: 4}},
+		{Match: false, Input: "", Location: SourceLocation{File: "123456.ra", Line: 7}},
+	}, parser.TestVectors())
+}
+
+func (s *testVectorTestSuite) TestIgnoresTestVectorsOfIncludedFiles() {
+	err := os.WriteFile(path.Join(s.includeDir, "words.ra"), []byte("##!? match cat\ncat\n"), fs.ModePerm)
+	s.Require().NoError(err)
+
+	parser := NewParser(s.ctx, strings.NewReader("##!> include words\n##!? match dog\ndog\n"))
+	actual, err := parser.Parse(false)
+	s.Require().NoError(err)
+
+	s.Equal("cat\ndog\n", actual.String())
+	s.Equal([]TestVector{{Match: true, Input: "dog", Location: SourceLocation{Line: 2}}}, parser.TestVectors())
+}
+
+func (s *testVectorTestSuite) TestFailsOnUnknownKind() {
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader("foo\n  ##!? matches foo\n"))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal("123456.ra:2:8: unknown test vector kind 'matches', expected 'match' or 'nomatch'", err.Error())
+}
+
+func (s *testVectorTestSuite) TestFailsOnInvalidQuotedInput() {
+	parser := NewParserForFile(s.ctx, "123456.ra", strings.NewReader(`##!? match "\q"`))
+	_, err := parser.Parse(false)
+
+	var parseError *ParseError
+	s.Require().ErrorAs(err, &parseError)
+	s.Equal(`123456.ra:1:12: invalid quoted test vector input "\q"`, err.Error())
+}
+
+func (s *testVectorTestSuite) TestCollectsTestVectorsWhenFormatting() {
+	contents := "##!? match foo\nfoo\n"
+	parser := NewParser(s.ctx, strings.NewReader(contents))
+	actual, err := parser.Parse(true)
+	s.Require().NoError(err)
+
+	s.Equal(contents, actual.String())
+	s.Len(parser.TestVectors(), 1)
+}
+
+func (s *testVectorTestSuite) TestVerify() {
+	expression := regexp.MustCompile(`(?i)foo`)
+
+	s.True(TestVector{Match: true, Input: "a FOO"}.Verify(expression))
+	s.False(TestVector{Match: true, Input: "bar"}.Verify(expression))
+	s.True(TestVector{Match: false, Input: "bar"}.Verify(expression))
+	s.False(TestVector{Match: false, Input: "foo"}.Verify(expression))
+}
+
+func (s *testVectorTestSuite) TestString() {
+	s.Equal(`match "foo"`, TestVector{Match: true, Input: "foo"}.String())
+	s.Equal(`nomatch "\tbar"`, TestVector{Match: false, Input: "\tbar"}.String())
+}
@@ -35,7 +35,7 @@ func (a *Assemble) ProcessLine(line string) error {
 	match := regex.AssembleInputRegex.FindStringSubmatch(line)
 	if len(match) > 0 {
 		if err := a.store(match[1]); err != nil {
-			logger.Error().Err(err).Msgf("Failed to store input: %s", line)
+			a.proc.logger.Error().Err(err).Msgf("Failed to store input: %s", line)
 			return err
 		}
 		return nil
@@ -51,7 +51,7 @@ func (a *Assemble) ProcessLine(line string) error {
 			} else {
 				message = "Failed to append output of previous block"
 			}
-			logger.Error().Err(err).Msg(message)
+			a.proc.logger.Error().Err(err).Msg(message)
 			return err
 		}
 	} else {
@@ -62,14 +62,14 @@ func (a *Assemble) ProcessLine(line string) error {
 
 // Complete finalizes the processor, producing its output
 func (a *Assemble) Complete() ([]string, error) {
-	logger.Trace().Msg("Completing assembly")
+	a.proc.logger.Trace().Msg("Completing assembly")
 	regex, err := a.runAssemble()
 	if err != nil {
 		return nil, err
 	}
 
 	result := a.wrapCompletedAssembly(regex)
-	logger.Trace().Msgf("Completed assembly: %s", result)
+	a.proc.logger.Trace().Msgf("Completed assembly: %s", result)
 
 	if result == "" {
 		return []string{}, nil
@@ -102,7 +102,7 @@ func (a *Assemble) store(identifier string) error {
 	// the value we just stored
 	a.output.Reset()
 
-	logger.Debug().Msgf("Storing expression at %s: %s", identifier, outputString)
+	a.proc.logger.Debug().Msgf("Storing expression at %s: %s", identifier, outputString)
 	a.proc.ctx.stash[identifier] = outputString
 	return nil
 }
@@ -135,8 +135,8 @@ func (a *Assemble) append(identifier string) error {
 		if !ok {
 			return fmt.Errorf("no entry in the stash for name '%s'", identifier)
 		}
-		logger.Debug().Msgf("Appending stored expression at %s", identifier)
-		logger.Trace().Msgf("Expression stored at %s is %s", identifier, stored)
+		a.proc.logger.Debug().Msgf("Appending stored expression at %s", identifier)
+		a.proc.logger.Trace().Msgf("Expression stored at %s is %s", identifier, stored)
 
 		_, err = a.output.WriteString(stored)
 		if err != nil {
@@ -109,8 +109,8 @@ func (c *CmdLine) ProcessLine(line string) error {
 
 	processed := c.expandWithPatterns(line)
 	c.proc.lines = append(c.proc.lines, processed)
-	logger.Trace().Msgf("cmdline in: %s", line)
-	logger.Trace().Msgf("cmdline out: %s", processed)
+	c.proc.logger.Trace().Msgf("cmdline in: %s", line)
+	c.proc.logger.Trace().Msgf("cmdline out: %s", processed)
 
 	return nil
 }
@@ -121,7 +121,7 @@ func (c *CmdLine) Complete() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	logger.Trace().Msgf("cmdLine Complete result: %v", assembly)
+	c.proc.logger.Trace().Msgf("cmdLine Complete result: %v", assembly)
 	return []string{assembly}, nil
 }
 
@@ -135,6 +135,12 @@ func (c *CmdLine) Consume(lines []string) error {
 	return nil
 }
 
+// Expand returns the expression the processor generates for a single line of input,
+// without validating the line.
+func (c *CmdLine) Expand(line string) string {
+	return c.expandWithPatterns(line)
+}
+
 // validateLine returns an error when regex metacharacters `.` or `+` are
 // found unescaped. Technically, we could append them to the previous output.
 // However, the same files can be included using the include processor and there
@@ -161,7 +167,7 @@ func (c *CmdLine) validateLine(input string) error {
 // suffix patterns if required. Escape will be retained (i.e., backslashes will not be
 // treated as characters to which an anti-evasion pattern needs to be appended).
 func (c *CmdLine) expandWithPatterns(input string) string {
-	logger.Trace().Msgf("regexpStr: %s", input)
+	c.proc.logger.Trace().Msgf("regexpStr: %s", input)
 	// By convention, if the line starts with ' char, copy the rest verbatim.
 	if strings.Index(input, "'") == 0 {
 		return input[1:]
@@ -6,12 +6,14 @@ package processors
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
 
 	"github.com/coreruleset/crs-toolchain/v2/configuration"
 	"github.com/coreruleset/crs-toolchain/v2/context"
+	"github.com/coreruleset/crs-toolchain/v2/transformations"
 )
 
 type cmdLineTestSuite struct {
@@ -51,8 +53,9 @@ func (s *cmdLineTestSuite) TestCmdLine_NewParser() {
 	patterns := s.ctx.rootContext.Configuration().Patterns
 	expected := &CmdLine{
 		proc: &Processor{
-			ctx:   s.ctx,
-			lines: []string{},
+			ctx:    s.ctx,
+			lines:  []string{},
+			logger: &logger,
 		},
 		cmdType: CmdLineUnix,
 		evasionPatterns: map[EvasionPatterns]string{
@@ -173,6 +176,25 @@ func (s *cmdLineTestSuite) TestCmdLine_ProcessLineFooWindows() {
 	s.Equal(`f_av-w_o_av-w_o`, cmd.proc.lines[0])
 }
 
+func (s *cmdLineTestSuite) TestCmdLine_WindowsMatchesCmdLineTransformation() {
+	// Windows rules apply t:cmdLine, which removes quotes and carets, so the anti-evasion
+	// pattern only needs to handle what remains after the transformation
+	configuration := s.newTestConfiguration()
+	configuration.Patterns.AntiEvasion.Windows = `[\"\^]*`
+	ctx := NewContext(context.NewWithConfiguration(os.TempDir(), configuration))
+	cmd := NewCmdLine(ctx, CmdLineWindows)
+	s.Require().NoError(cmd.ProcessLine("net user"))
+	assembly, err := cmd.Complete()
+	s.Require().NoError(err)
+	expression := regexp.MustCompile(assembly[0])
+
+	cmdLine, err := transformations.Lookup("cmdLine")
+	s.Require().NoError(err)
+	for _, payload := range []string{`NET USER`, `n^e"t u's'er`, `net;user`, `n\et,,user`} {
+		s.Regexp(expression, cmdLine(payload), payload)
+	}
+}
+
 func (s *cmdLineTestSuite) TestCmdLine_ProcessTildeOnlyAtLineEnd() {
 	cmd := NewCmdLine(s.ctx, CmdLineUnix)
 
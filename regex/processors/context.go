@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/rs/zerolog"
+
 	"github.com/coreruleset/crs-toolchain/v2/context"
 )
 
@@ -15,6 +17,8 @@ type Context struct {
 	singleRuleID      int
 	singleChainOffset bool
 	stash             map[string]string
+	localIncludesOnly bool
+	logger            *zerolog.Logger
 }
 
 // NewContext creates a new processor context using the `rootDir` as the root directory.
@@ -36,3 +40,30 @@ func (ctx *Context) Dump(w io.Writer) {
 func (ctx *Context) RootContext() *context.Context {
 	return ctx.rootContext
 }
+
+// RestrictIncludes makes the parser reject include and include-except directives that
+// reference files outside of the include and exclude directories, e.g., by absolute path.
+// Use it when processing untrusted regex-assembly input.
+func (ctx *Context) RestrictIncludes() {
+	ctx.localIncludesOnly = true
+}
+
+// IncludesRestricted returns true if includes are restricted to the include and exclude
+// directories, see RestrictIncludes.
+func (ctx *Context) IncludesRestricted() bool {
+	return ctx.localIncludesOnly
+}
+
+// SetLogger makes the parser, operators and processors that use the context log to `logger`
+// instead of the global logger.
+func (ctx *Context) SetLogger(logger zerolog.Logger) {
+	ctx.logger = &logger
+}
+
+// Logger returns the logger set with SetLogger, or `defaultLogger` if no logger was set.
+func (ctx *Context) Logger(defaultLogger *zerolog.Logger) *zerolog.Logger {
+	if ctx.logger != nil {
+		return ctx.logger
+	}
+	return defaultLogger
+}
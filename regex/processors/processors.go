@@ -4,14 +4,16 @@
 package processors
 
 import (
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 var logger = log.With().Str("component", "processors").Logger()
 
 type Processor struct {
-	ctx   *Context
-	lines []string
+	ctx    *Context
+	lines  []string
+	logger *zerolog.Logger
 }
 
 type IProcessor interface {
@@ -26,7 +28,8 @@ type IProcessor interface {
 // NewProcessor creates a new processor with passed context.
 func NewProcessor(ctx *Context) *Processor {
 	return &Processor{
-		ctx:   ctx,
-		lines: []string{},
+		ctx:    ctx,
+		lines:  []string{},
+		logger: ctx.Logger(&logger),
 	}
 }
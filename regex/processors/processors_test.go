@@ -7,6 +7,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/coreruleset/crs-toolchain/v2/context"
@@ -28,8 +29,9 @@ func TestRunProcessorTestSuite(t *testing.T) {
 
 func (s *processorTestSuite) TestProcessor_New() {
 	expected := &Processor{
-		ctx:   s.ctx,
-		lines: []string{},
+		ctx:    s.ctx,
+		lines:  []string{},
+		logger: &logger,
 	}
 
 	actual := NewProcessor(s.ctx)
@@ -38,10 +40,19 @@ func (s *processorTestSuite) TestProcessor_New() {
 
 func (s *processorTestSuite) TestProcessor_NewWithContext() {
 	expected := &Processor{
-		ctx:   s.ctx,
-		lines: []string{},
+		ctx:    s.ctx,
+		lines:  []string{},
+		logger: &logger,
 	}
 
 	actual := NewProcessor(s.ctx)
 	s.Equal(expected, actual)
 }
+
+func (s *processorTestSuite) TestProcessor_NewWithLogger() {
+	contextLogger := zerolog.Nop()
+	s.ctx.SetLogger(contextLogger)
+
+	actual := NewProcessor(s.ctx)
+	s.Equal(&contextLogger, actual.logger)
+}
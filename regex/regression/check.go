@@ -0,0 +1,96 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/coreruleset/crs-toolchain/v2/transformations"
+)
+
+// DefaultTransformations are the names of the transformations applied to payloads by default.
+var DefaultTransformations = []string{"urlDecodeUni", "htmlEntityDecode", "lowercase"}
+
+// Checker checks regular expressions against the payloads of regression tests.
+type Checker struct {
+	// Transformations are applied to every payload. Payloads are checked both as they are and
+	// after applying the transformations. May be nil.
+	Transformations *transformations.Chain
+	// MatchOnly restricts checks to the stages that expect the rule to match. This is useful for
+	// chained rules, where a rule may match although the chain doesn't.
+	MatchOnly bool
+}
+
+// Failure is a stage of a test that the regular expression doesn't satisfy.
+type Failure struct {
+	RuleId      int
+	Test        *Test
+	Stage       int
+	Expectation Expectation
+	// Payload is the payload that was matched unexpectedly.
+	Payload string
+}
+
+func (f Failure) String() string {
+	name := f.Test.Name(f.RuleId)
+	if len(f.Test.Stages) > 1 {
+		name = fmt.Sprintf("%s, stage %d", name, f.Stage+1)
+	}
+	if f.Expectation == ExpectMatch {
+		return fmt.Sprintf("test %s: expected rule %d to match, but no payload matches", name, f.RuleId)
+	}
+	return fmt.Sprintf("test %s: expected rule %d not to match, but %q matches", name, f.RuleId, f.Payload)
+}
+
+// Check checks `expression`, the regular expression of rule `ruleId`, against the tests in
+// `testFile`. Returns the number of tests that state an expectation about the rule and the stages
+// that fail.
+func (c *Checker) Check(testFile *TestFile, ruleId int, expression *regexp.Regexp) (int, []Failure, error) {
+	checked := 0
+	failures := []Failure{}
+	for testIndex := range testFile.Tests {
+		test := &testFile.Tests[testIndex]
+		relevant := false
+		for stageIndex, stage := range test.Stages {
+			expectation := stage.Output.Expectation(ruleId)
+			if expectation == NoExpectation || (c.MatchOnly && expectation != ExpectMatch) {
+				continue
+			}
+			relevant = true
+			payloads, err := stage.Input.Payloads()
+			if err != nil {
+				return 0, nil, fmt.Errorf("test %s: %w", test.Name(ruleId), err)
+			}
+			matched, found := c.match(expression, payloads)
+			if found != (expectation == ExpectMatch) {
+				failures = append(failures, Failure{
+					RuleId:      ruleId,
+					Test:        test,
+					Stage:       stageIndex,
+					Expectation: expectation,
+					Payload:     matched,
+				})
+			}
+		}
+		if relevant {
+			checked++
+		}
+	}
+	return checked, failures, nil
+}
+
+// match returns the first of `payloads` that `expression` matches, either as it is, or after
+// applying the transformations.
+func (c *Checker) match(expression *regexp.Regexp, payloads []string) (string, bool) {
+	for _, payload := range payloads {
+		if expression.MatchString(payload) {
+			return payload, true
+		}
+		if c.Transformations != nil && expression.MatchString(c.Transformations.Apply(payload)) {
+			return payload, true
+		}
+	}
+	return "", false
+}
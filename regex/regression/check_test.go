@@ -0,0 +1,74 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/coreruleset/crs-toolchain/v2/transformations"
+)
+
+type checkTestSuite struct {
+	suite.Suite
+	testFile *TestFile
+}
+
+func TestRunCheckTestSuite(t *testing.T) {
+	suite.Run(t, new(checkTestSuite))
+}
+
+func (s *checkTestSuite) SetupTest() {
+	match := Output{Log: Log{ExpectIds: []int{932100}}}
+	noMatch := Output{Log: Log{NoExpectIds: []int{932100}}}
+	s.testFile = &TestFile{
+		RuleId: 932100,
+		Tests: []Test{
+			{Id: 1, Stages: []Stage{{Input: Input{URI: "/?x=%3Bcat%20/etc/passwd"}, Output: match}}},
+			{Id: 2, Stages: []Stage{{Input: Input{Data: "x=%253B%2543AT"}, Output: match}}},
+			{Id: 3, Stages: []Stage{{Input: Input{URI: "/?x=concatenate"}, Output: noMatch}}},
+			{Id: 4, Stages: []Stage{{Input: Input{URI: "/?x=;cat"}, Output: Output{Log: Log{ExpectIds: []int{932110}}}}}},
+		},
+	}
+}
+
+func (s *checkTestSuite) TestCheck_Passes() {
+	chain, err := transformations.NewChain(DefaultTransformations)
+	s.Require().NoError(err)
+	checker := &Checker{Transformations: chain}
+
+	checked, failures, err := checker.Check(s.testFile, 932100, regexp.MustCompile(`;cat`))
+	s.Require().NoError(err)
+	s.Equal(3, checked)
+	s.Empty(failures)
+}
+
+func (s *checkTestSuite) TestCheck_Failures() {
+	checker := &Checker{}
+
+	checked, failures, err := checker.Check(s.testFile, 932100, regexp.MustCompile(`cat`))
+	s.Require().NoError(err)
+	s.Equal(3, checked)
+	s.Require().Len(failures, 2)
+	s.Equal("test 932100-2: expected rule 932100 to match, but no payload matches", failures[0].String())
+	s.Equal(`test 932100-3: expected rule 932100 not to match, but "/?x=concatenate" matches`, failures[1].String())
+}
+
+func (s *checkTestSuite) TestCheck_MatchOnly() {
+	checker := &Checker{MatchOnly: true}
+
+	checked, failures, err := checker.Check(s.testFile, 932100, regexp.MustCompile(`cat`))
+	s.Require().NoError(err)
+	s.Equal(2, checked)
+	s.Len(failures, 1)
+}
+
+func (s *checkTestSuite) TestFailure_MultipleStages() {
+	test := &Test{Id: 7, Stages: []Stage{{}, {}}}
+	failure := Failure{RuleId: 932100, Test: test, Stage: 1, Expectation: ExpectMatch}
+
+	s.Equal("test 932100-7, stage 2: expected rule 932100 to match, but no payload matches", failure.String())
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Payloads returns the candidate payloads of the request: the URI, its decoded path, the names and
+// values of query and body arguments, the names and values of headers and cookies, and the body.
+// Encoded and raw requests are returned as a whole, as they may not be valid HTTP.
+// Payloads are returned in order of appearance, without duplicates.
+func (i *Input) Payloads() ([]string, error) {
+	payloads := []string{}
+	add := func(values ...string) {
+		for _, value := range values {
+			if value != "" && !slices.Contains(payloads, value) {
+				payloads = append(payloads, value)
+			}
+		}
+	}
+
+	if i.URI != "" {
+		add(i.URI)
+		uriPath, query, _ := strings.Cut(i.URI, "?")
+		if decoded, err := url.PathUnescape(uriPath); err == nil {
+			add(decoded)
+		}
+		add(arguments(query)...)
+	}
+
+	// Sort the headers, maps have no order
+	names := make([]string, 0, len(i.Headers))
+	for name := range i.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := i.Headers[name]
+		add(name, value)
+		if strings.EqualFold(name, "cookie") {
+			for _, cookie := range strings.Split(value, ";") {
+				cookieName, cookieValue, _ := strings.Cut(strings.TrimSpace(cookie), "=")
+				add(cookieName, cookieValue)
+			}
+		}
+	}
+
+	if i.Data != "" {
+		add(i.Data)
+		add(arguments(i.Data)...)
+	}
+
+	if i.EncodedRequest != "" {
+		decoded, err := base64.StdEncoding.DecodeString(i.EncodedRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encoded_request: %w", err)
+		}
+		add(string(decoded))
+	}
+	add(i.RawRequest)
+
+	return payloads, nil
+}
+
+// arguments returns the names and values of the arguments in the URL encoded `query`. Arguments
+// that can't be decoded are returned as they are.
+func arguments(query string) []string {
+	values := []string{}
+	for _, argument := range strings.Split(query, "&") {
+		name, value, _ := strings.Cut(argument, "=")
+		values = append(values, unescapeQuery(name), unescapeQuery(value))
+	}
+	return values
+}
+
+func unescapeQuery(value string) string {
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type payloadsTestSuite struct {
+	suite.Suite
+}
+
+func TestRunPayloadsTestSuite(t *testing.T) {
+	suite.Run(t, new(payloadsTestSuite))
+}
+
+func (s *payloadsTestSuite) TestPayloads() {
+	input := &Input{
+		URI: "/a%20b/?x=%3Bls&y",
+		Headers: map[string]string{
+			"User-Agent": "agent",
+			"Cookie":     "session=abc; theme=dark",
+		},
+		Data: "z=cat+%2Fetc%2Fpasswd",
+	}
+	payloads, err := input.Payloads()
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		"/a%20b/?x=%3Bls&y",
+		"/a b/",
+		"x", ";ls", "y",
+		"Cookie", "session=abc; theme=dark", "session", "abc", "theme", "dark",
+		"User-Agent", "agent",
+		"z=cat+%2Fetc%2Fpasswd", "z", "cat /etc/passwd",
+	}, payloads)
+}
+
+func (s *payloadsTestSuite) TestPayloads_InvalidEscapes() {
+	input := &Input{URI: "/?x=%zz"}
+	payloads, err := input.Payloads()
+	s.Require().NoError(err)
+
+	s.Equal([]string{"/?x=%zz", "/", "x", "%zz"}, payloads)
+}
+
+func (s *payloadsTestSuite) TestPayloads_EncodedRequest() {
+	request := "GET /?x=1 HTTP/1.1\r\nHost: localhost\r\n\r\n"
+	input := &Input{EncodedRequest: base64.StdEncoding.EncodeToString([]byte(request))}
+	payloads, err := input.Payloads()
+	s.Require().NoError(err)
+
+	s.Equal([]string{request}, payloads)
+}
+
+func (s *payloadsTestSuite) TestPayloads_InvalidEncodedRequest() {
+	input := &Input{EncodedRequest: "not base64!"}
+	_, err := input.Payloads()
+
+	s.ErrorContains(err, "failed to decode encoded_request")
+}
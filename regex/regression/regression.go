@@ -0,0 +1,160 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package regression reads go-ftw regression tests and checks regular expressions against the
+// payloads of the tests, without sending any requests. This is an approximation of running the
+// tests against a WAF: the variables a rule inspects are not known, so every part of a request is
+// a candidate payload, and the transformations of the rule are approximated by a configurable chain
+// (see `Checker`).
+package regression
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// TestFile is a file of go-ftw regression tests for a single rule.
+type TestFile struct {
+	RuleId int    `yaml:"rule_id"`
+	Tests  []Test `yaml:"tests"`
+}
+
+// Test is a single regression test. Older test files identify tests by title, newer ones by
+// number.
+type Test struct {
+	Id     int     `yaml:"test_id"`
+	Title  string  `yaml:"test_title"`
+	Stages []Stage `yaml:"stages"`
+	// Line is the line of the test in the test file.
+	Line int `yaml:"-"`
+}
+
+// Stage is a single request of a test and the expected outcome.
+type Stage struct {
+	Input  Input  `yaml:"input"`
+	Output Output `yaml:"output"`
+}
+
+// Input describes the request of a stage. Only the parts that can contain payloads are read.
+type Input struct {
+	URI            string            `yaml:"uri"`
+	Headers        map[string]string `yaml:"headers"`
+	Data           string            `yaml:"data"`
+	EncodedRequest string            `yaml:"encoded_request"`
+	RawRequest     string            `yaml:"raw_request"`
+}
+
+// Output describes the expected outcome of a stage. Only the expectations regarding rules are read.
+type Output struct {
+	LogContains   string `yaml:"log_contains"`
+	NoLogContains string `yaml:"no_log_contains"`
+	Log           Log    `yaml:"log"`
+}
+
+// Log lists the rules that are expected to match, or not to match, a request.
+type Log struct {
+	ExpectIds   []int `yaml:"expect_ids"`
+	NoExpectIds []int `yaml:"no_expect_ids"`
+}
+
+// Expectation is the expected outcome of a stage for a specific rule.
+type Expectation int
+
+const (
+	// NoExpectation means that the stage doesn't state whether the rule matches.
+	NoExpectation Expectation = iota
+	ExpectMatch
+	ExpectNoMatch
+)
+
+func (t *Test) UnmarshalYAML(node *yaml.Node) error {
+	type plain Test
+	if err := node.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.Line = node.Line
+	return nil
+}
+
+// Name returns the title of the test, or `<rule ID>-<test ID>` if the test has no title.
+func (t *Test) Name(ruleId int) string {
+	if t.Title != "" {
+		return t.Title
+	}
+	return fmt.Sprintf("%d-%d", ruleId, t.Id)
+}
+
+func (s *Stage) UnmarshalYAML(node *yaml.Node) error {
+	type plain Stage
+	// Older test files nest input and output in a `stage` key
+	legacy := struct {
+		Stage *plain `yaml:"stage"`
+	}{}
+	if err := node.Decode(&legacy); err != nil {
+		return err
+	}
+	if legacy.Stage != nil {
+		*s = Stage(*legacy.Stage)
+		return nil
+	}
+	return node.Decode((*plain)(s))
+}
+
+// Expectation returns whether the stage expects rule `ruleId` to match or not to match.
+func (o *Output) Expectation(ruleId int) Expectation {
+	for _, id := range o.Log.ExpectIds {
+		if id == ruleId {
+			return ExpectMatch
+		}
+	}
+	for _, id := range o.Log.NoExpectIds {
+		if id == ruleId {
+			return ExpectNoMatch
+		}
+	}
+	idRegex := regexp.MustCompile(`(?:^|\D)` + strconv.Itoa(ruleId) + `(?:\D|$)`)
+	if o.LogContains != "" && idRegex.MatchString(o.LogContains) {
+		return ExpectMatch
+	}
+	if o.NoLogContains != "" && idRegex.MatchString(o.NoLogContains) {
+		return ExpectNoMatch
+	}
+	return NoExpectation
+}
+
+// Load reads the test file at `filePath`.
+func Load(filePath string) (*TestFile, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read regression tests %s: %w", filePath, err)
+	}
+	testFile := &TestFile{}
+	if err := yaml.Unmarshal(contents, testFile); err != nil {
+		return nil, fmt.Errorf("failed to parse regression tests %s: %w", filePath, err)
+	}
+	return testFile, nil
+}
+
+// FindTestFile returns the path of the test file of rule `ruleId` in `directory`, the directory
+// of regression tests. Test files are expected in subdirectories, one per rule file, and named
+// after the rule, e.g., REQUEST-932-APPLICATION-ATTACK-RCE/932100.yaml. Returns an empty path if
+// there is no test file for the rule.
+func FindTestFile(directory string, ruleId string) (string, error) {
+	candidates, err := filepath.Glob(path.Join(directory, "*", ruleId+".y*ml"))
+	if err != nil {
+		return "", err
+	}
+	if len(candidates) > 1 {
+		return "", fmt.Errorf("found multiple test files for rule %s: %v", ruleId, candidates)
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+	return candidates[0], nil
+}
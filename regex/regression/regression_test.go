@@ -0,0 +1,130 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package regression
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type regressionTestSuite struct {
+	suite.Suite
+	testsDir string
+}
+
+func TestRunRegressionTestSuite(t *testing.T) {
+	suite.Run(t, new(regressionTestSuite))
+}
+
+func (s *regressionTestSuite) SetupTest() {
+	s.testsDir = s.T().TempDir()
+}
+
+func (s *regressionTestSuite) writeTestFile(directory string, fileName string, contents string) string {
+	s.Require().NoError(os.MkdirAll(path.Join(s.testsDir, directory), fs.ModePerm))
+	filePath := path.Join(s.testsDir, directory, fileName)
+	s.Require().NoError(os.WriteFile(filePath, []byte(contents), fs.ModePerm))
+	return filePath
+}
+
+func (s *regressionTestSuite) TestLoad() {
+	filePath := s.writeTestFile("REQUEST-932-APPLICATION-ATTACK-RCE", "932100.yaml", `---
+meta:
+  author: "crs-team"
+rule_id: 932100
+tests:
+  - test_id: 1
+    stages:
+      - input:
+          uri: "/get?foo=bar"
+          headers:
+            Host: localhost
+        output:
+          log:
+            expect_ids: [932100]
+  - test_id: 2
+    stages:
+      - input:
+          data: "a=b"
+        output:
+          log:
+            no_expect_ids: [932100]
+`)
+	testFile, err := Load(filePath)
+	s.Require().NoError(err)
+
+	s.Equal(932100, testFile.RuleId)
+	s.Require().Len(testFile.Tests, 2)
+	s.Equal(1, testFile.Tests[0].Id)
+	s.Equal(6, testFile.Tests[0].Line)
+	s.Require().Len(testFile.Tests[0].Stages, 1)
+	s.Equal(Input{URI: "/get?foo=bar", Headers: map[string]string{"Host": "localhost"}}, testFile.Tests[0].Stages[0].Input)
+	s.Equal([]int{932100}, testFile.Tests[0].Stages[0].Output.Log.ExpectIds)
+	s.Equal(15, testFile.Tests[1].Line)
+	s.Equal("a=b", testFile.Tests[1].Stages[0].Input.Data)
+	s.Equal([]int{932100}, testFile.Tests[1].Stages[0].Output.Log.NoExpectIds)
+}
+
+func (s *regressionTestSuite) TestLoad_LegacyFormat() {
+	filePath := s.writeTestFile("REQUEST-932-APPLICATION-ATTACK-RCE", "932100.yaml", `---
+tests:
+  - test_title: 932100-1
+    stages:
+      - stage:
+          input:
+            uri: "/?x=y"
+          output:
+            log_contains: id "932100"
+`)
+	testFile, err := Load(filePath)
+	s.Require().NoError(err)
+
+	s.Require().Len(testFile.Tests, 1)
+	test := testFile.Tests[0]
+	s.Equal("932100-1", test.Name(932100))
+	s.Require().Len(test.Stages, 1)
+	s.Equal("/?x=y", test.Stages[0].Input.URI)
+	s.Equal(`id "932100"`, test.Stages[0].Output.LogContains)
+}
+
+func (s *regressionTestSuite) TestLoad_InvalidYaml() {
+	filePath := s.writeTestFile("REQUEST-932-APPLICATION-ATTACK-RCE", "932100.yaml", "tests: [")
+	_, err := Load(filePath)
+	s.ErrorContains(err, "failed to parse regression tests")
+}
+
+func (s *regressionTestSuite) TestExpectation() {
+	s.Equal(ExpectMatch, (&Output{Log: Log{ExpectIds: []int{1, 932100}}}).Expectation(932100))
+	s.Equal(ExpectNoMatch, (&Output{Log: Log{NoExpectIds: []int{932100}}}).Expectation(932100))
+	s.Equal(ExpectMatch, (&Output{LogContains: `id "932100"`}).Expectation(932100))
+	s.Equal(ExpectNoMatch, (&Output{NoLogContains: `id "932100"`}).Expectation(932100))
+	s.Equal(NoExpectation, (&Output{LogContains: `id "9321001"`}).Expectation(932100))
+	s.Equal(NoExpectation, (&Output{Log: Log{ExpectIds: []int{932110}}}).Expectation(932100))
+}
+
+func (s *regressionTestSuite) TestName() {
+	s.Equal("932100-3", (&Test{Id: 3}).Name(932100))
+	s.Equal("custom", (&Test{Id: 3, Title: "custom"}).Name(932100))
+}
+
+func (s *regressionTestSuite) TestFindTestFile() {
+	expected := s.writeTestFile("REQUEST-932-APPLICATION-ATTACK-RCE", "932100.yaml", "")
+	s.writeTestFile("REQUEST-932-APPLICATION-ATTACK-RCE", "932110.yml", "")
+
+	filePath, err := FindTestFile(s.testsDir, "932100")
+	s.Require().NoError(err)
+	s.Equal(expected, filePath)
+
+	filePath, err = FindTestFile(s.testsDir, "932110")
+	s.Require().NoError(err)
+	s.Equal("932110.yml", path.Base(filePath))
+
+	filePath, err = FindTestFile(s.testsDir, "932120")
+	s.Require().NoError(err)
+	s.Empty(filePath)
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"errors"
+	"strings"
+)
+
+// ParseActions parses `actions`, the action list of a rule, e.g., "id:1,phase:2,t:none". The
+// list may be enclosed in double quotes. Actions are separated by commas that aren't part of a
+// single quoted argument. The spans of the actions are relative to `actions`.
+func ParseActions(actions string) ([]Action, error) {
+	offset := 0
+	trimmed := strings.TrimSpace(actions)
+	if len(trimmed) >= 2 && trimmed[0] == '"' && trimmed[len(trimmed)-1] == '"' {
+		offset = strings.Index(actions, trimmed) + 1
+		actions = trimmed[1 : len(trimmed)-1]
+	}
+
+	result := []Action{}
+	quoted := false
+	start := 0
+	add := func(end int) {
+		if action, ok := newAction(actions, start, end); ok {
+			action.Span.Start += offset
+			action.Span.End += offset
+			result = append(result, action)
+		}
+	}
+	for i := 0; i < len(actions); i++ {
+		switch actions[i] {
+		case '\\':
+			// Skip the escaped character
+			i++
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				add(i)
+				start = i + 1
+			}
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote in action list")
+	}
+	add(len(actions))
+	return result, nil
+}
+
+// newAction creates the action from the text between `start` and `end`, ignoring surrounding
+// white space and line continuations. Returns false if there is no action.
+func newAction(actions string, start int, end int) (Action, bool) {
+	for start < end {
+		if isSpace(actions[start]) {
+			start++
+		} else if length := continuationLength([]byte(actions[start:end])); length > 0 {
+			start += length
+		} else {
+			break
+		}
+	}
+	for end > start {
+		if isSpace(actions[end-1]) {
+			end--
+		} else if actions[end-1] == '\\' && end < len(actions) && (actions[end] == '\n' || actions[end] == '\r') {
+			// The backslash of a trailing line continuation
+			end--
+		} else {
+			break
+		}
+	}
+	if start == end {
+		return Action{}, false
+	}
+
+	text := removeContinuations(actions[start:end])
+	name, argument, _ := strings.Cut(text, ":")
+	argument = strings.TrimSpace(argument)
+	if len(argument) >= 2 && argument[0] == '\'' && argument[len(argument)-1] == '\'' {
+		argument = argument[1 : len(argument)-1]
+	}
+	return Action{
+		Name:     strings.TrimSpace(name),
+		Argument: argument,
+		Span:     Span{Start: start, End: end},
+	}, true
+}
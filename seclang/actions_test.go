@@ -0,0 +1,55 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type actionsTestSuite struct {
+	suite.Suite
+}
+
+func TestRunActionsTestSuite(t *testing.T) {
+	suite.Run(t, new(actionsTestSuite))
+}
+
+func (s *actionsTestSuite) TestParseActions() {
+	actions, err := ParseActions(`"id:1, pass,msg:'a, b',t:none"`)
+	s.Require().NoError(err)
+
+	s.Equal([]Action{
+		{Name: "id", Argument: "1", Span: Span{Start: 1, End: 5}},
+		{Name: "pass", Argument: "", Span: Span{Start: 7, End: 11}},
+		{Name: "msg", Argument: "a, b", Span: Span{Start: 12, End: 22}},
+		{Name: "t", Argument: "none", Span: Span{Start: 23, End: 29}},
+	}, actions)
+}
+
+func (s *actionsTestSuite) TestParseActions_LineContinuations() {
+	actions, err := ParseActions("id:1,\\\n    phase:2,\\\r\n    pass\\\n")
+	s.Require().NoError(err)
+
+	s.Require().Len(actions, 3)
+	s.Equal("phase", actions[1].Name)
+	s.Equal(Span{Start: 11, End: 18}, actions[1].Span)
+	s.Equal("pass", actions[2].Name)
+	s.Equal(Span{Start: 26, End: 30}, actions[2].Span)
+}
+
+func (s *actionsTestSuite) TestParseActions_EscapedQuote() {
+	actions, err := ParseActions(`msg:'it\'s, here',pass`)
+	s.Require().NoError(err)
+
+	s.Require().Len(actions, 2)
+	s.Equal(`it\'s, here`, actions[0].Argument)
+}
+
+func (s *actionsTestSuite) TestParseActions_UnterminatedQuote() {
+	_, err := ParseActions("id:1,msg:'foo")
+
+	s.EqualError(err, "unterminated quote in action list")
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	secRule   = "SecRule"
+	secAction = "SecAction"
+)
+
+type parser struct {
+	file *File
+	pos  int
+}
+
+// ParseFile reads and parses the rule file at `filePath`.
+func ParseFile(filePath string) (*File, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file %s: %w", filePath, err)
+	}
+	return Parse(filePath, contents)
+}
+
+// Parse parses `contents`, the contents of the rule file called `name`. Directives other than
+// SecRule and SecAction are parsed into directives but not interpreted.
+func Parse(name string, contents []byte) (*File, error) {
+	p := &parser{file: &File{
+		Name:       name,
+		Contents:   contents,
+		Directives: []*Directive{},
+		Rules:      []*Rule{},
+	}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.file, nil
+}
+
+func (p *parser) parse() error {
+	var chainStarter *Rule
+	chainOpen := false
+	for {
+		p.skipBlankLines()
+		if p.pos >= len(p.file.Contents) {
+			break
+		}
+		directive, err := p.parseDirective()
+		if err != nil {
+			return err
+		}
+		p.file.Directives = append(p.file.Directives, directive)
+		if !isRule(directive) {
+			continue
+		}
+
+		rule, err := p.newRule(directive)
+		if err != nil {
+			return err
+		}
+		if chainOpen {
+			chainStarter.Chain = append(chainStarter.Chain, rule)
+		} else {
+			p.file.Rules = append(p.file.Rules, rule)
+			chainStarter = rule
+		}
+		chainOpen = rule.Action("chain") != nil
+	}
+
+	if chainOpen {
+		return p.errorAt(chainStarter.Directive.Span.Start, fmt.Errorf("chain of rule %s is not terminated", chainStarter.Id()))
+	}
+	return nil
+}
+
+// skipBlankLines skips white space, empty lines and comment lines
+func (p *parser) skipBlankLines() {
+	data := p.file.Contents
+	for p.pos < len(data) {
+		switch data[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			for p.pos < len(data) && data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseDirective parses the directive at the current position, up to the end of the line,
+// following line continuations
+func (p *parser) parseDirective() (*Directive, error) {
+	data := p.file.Contents
+	line, _ := p.file.Position(p.pos)
+	directive := &Directive{
+		Arguments: []Token{},
+		Span:      Span{Start: p.pos},
+		Line:      line,
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(data) || data[p.pos] == '\n' || data[p.pos] == '\r' {
+			break
+		}
+		token := p.parseToken()
+		directive.Span.End = p.pos
+		if directive.Name == "" {
+			directive.Name = token.Value
+		} else {
+			directive.Arguments = append(directive.Arguments, token)
+		}
+	}
+	return directive, nil
+}
+
+// skipSpace skips spaces, tabs and line continuations
+func (p *parser) skipSpace() {
+	data := p.file.Contents
+	for p.pos < len(data) {
+		if data[p.pos] == ' ' || data[p.pos] == '\t' {
+			p.pos++
+		} else if length := continuationLength(data[p.pos:]); length > 0 {
+			p.pos += length
+		} else {
+			return
+		}
+	}
+}
+
+// parseToken parses a quoted or unquoted token at the current position. Like Apache, a quoted
+// token without closing quote extends to the end of the line.
+func (p *parser) parseToken() Token {
+	data := p.file.Contents
+	start := p.pos
+	quote := data[p.pos]
+	if quote != '"' && quote != '\'' {
+		for p.pos < len(data) && !isSpace(data[p.pos]) && continuationLength(data[p.pos:]) == 0 {
+			if data[p.pos] == '\\' && p.pos+1 < len(data) && !isSpace(data[p.pos+1]) {
+				p.pos++
+			}
+			p.pos++
+		}
+		span := Span{Start: start, End: p.pos}
+		return Token{Value: string(data[start:p.pos]), Span: span}
+	}
+
+	p.pos++
+	end := -1
+	for p.pos < len(data) && end < 0 {
+		switch data[p.pos] {
+		case '\\':
+			if length := continuationLength(data[p.pos:]); length > 0 {
+				p.pos += length
+			} else {
+				p.pos = min(p.pos+2, len(data))
+			}
+		case quote:
+			end = p.pos
+			p.pos++
+		case '\r', '\n':
+			end = p.pos
+		default:
+			p.pos++
+		}
+	}
+	if end < 0 {
+		end = p.pos
+	}
+	span := Span{Start: start + 1, End: end}
+	return Token{Value: removeContinuations(string(data[span.Start:span.End])), Span: span}
+}
+
+// newRule interprets the arguments of a SecRule or SecAction directive
+func (p *parser) newRule(directive *Directive) (*Rule, error) {
+	rule := &Rule{Directive: directive, Actions: []Action{}}
+	arguments := directive.Arguments
+	var actions *Token
+	if strings.EqualFold(directive.Name, secAction) {
+		if len(arguments) != 1 {
+			return nil, p.errorAt(directive.Span.Start, fmt.Errorf("%s expects 1 argument, found %d", secAction, len(arguments)))
+		}
+		actions = &arguments[0]
+	} else {
+		if len(arguments) < 2 || len(arguments) > 3 {
+			return nil, p.errorAt(directive.Span.Start, fmt.Errorf("%s expects 2 or 3 arguments, found %d", secRule, len(arguments)))
+		}
+		rule.Variables = arguments[0]
+		rule.Operator = p.parseOperator(arguments[1])
+		if len(arguments) == 3 {
+			actions = &arguments[2]
+		}
+	}
+
+	if actions != nil {
+		parsed, err := ParseActions(string(p.file.Contents[actions.Span.Start:actions.Span.End]))
+		if err != nil {
+			return nil, p.errorAt(actions.Span.Start, err)
+		}
+		for _, action := range parsed {
+			action.Span.Start += actions.Span.Start
+			action.Span.End += actions.Span.Start
+			rule.Actions = append(rule.Actions, action)
+		}
+	}
+	return rule, nil
+}
+
+// parseOperator splits the operator token of a SecRule into the operator and its argument
+func (p *parser) parseOperator(token Token) Operator {
+	raw := string(p.file.Contents[token.Span.Start:token.Span.End])
+	i := 0
+	operator := Operator{Name: "rx"}
+	if strings.HasPrefix(raw, "!") {
+		operator.Negated = true
+		i++
+	}
+	if strings.HasPrefix(raw[i:], "@") {
+		end := i + 1
+		for end < len(raw) && !isSpace(raw[end]) {
+			end++
+		}
+		operator.Name = raw[i+1 : end]
+		i = end
+		for i < len(raw) && isSpace(raw[i]) {
+			i++
+		}
+	}
+	operator.Argument = Token{
+		Value: removeContinuations(raw[i:]),
+		Span:  Span{Start: token.Span.Start + i, End: token.Span.End},
+	}
+	return operator
+}
+
+func (p *parser) errorAt(offset int, err error) error {
+	line, column := p.file.Position(offset)
+	return &ParseError{File: p.file.Name, Line: line, Column: column, Err: err}
+}
+
+func isRule(directive *Directive) bool {
+	return strings.EqualFold(directive.Name, secRule) || strings.EqualFold(directive.Name, secAction)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// continuationLength returns the length of the line continuation (a backslash followed by a line
+// break) at the start of `data`, or 0 if there is none.
+func continuationLength(data []byte) int {
+	if len(data) >= 2 && data[0] == '\\' && data[1] == '\n' {
+		return 2
+	}
+	if len(data) >= 3 && data[0] == '\\' && data[1] == '\r' && data[2] == '\n' {
+		return 3
+	}
+	return 0
+}
+
+// removeContinuations removes line continuations from `value`
+func removeContinuations(value string) string {
+	return strings.NewReplacer("\\\r\n", "", "\\\n", "").Replace(value)
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package seclang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type parserTestSuite struct {
+	suite.Suite
+}
+
+func TestRunParserTestSuite(t *testing.T) {
+	suite.Run(t, new(parserTestSuite))
+}
+
+const ruleFile = `# ------------------------------------------------------------------------
+# OWASP CRS ver.4.0.0
+# ------------------------------------------------------------------------
+
+SecRule TX:DETECTION_PARANOIA_LEVEL "@lt 1" "id:932011,phase:1,pass,nolog,tag:'OWASP_CRS',skipAfter:END-REQUEST-932"
+
+#
+# -=[ Unix Command Injection ]=-
+#
+SecRule REQUEST_COOKIES|ARGS_NAMES|ARGS "@rx (?i)(?:;|\|)\s*(?:cat|id)\b" \
+    "id:932100,\
+    phase:2,\
+    block,\
+    t:none,t:cmdLine,\
+    msg:'Remote Command Execution: Unix Command Injection',\
+    chain"
+    SecRule MATCHED_VARS "!@pm foo bar" \
+        "t:none,\
+        chain"
+        SecRule REQUEST_HEADERS:User-Agent "^curl" "t:lowercase"
+
+SecMarker "END-REQUEST-932"
+SecAction "id:932999,pass,nolog"
+`
+
+func (s *parserTestSuite) TestParse() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+
+	s.Len(file.Directives, 6)
+	s.Equal("SecMarker", file.Directives[4].Name)
+	s.Equal("END-REQUEST-932", file.Directives[4].Arguments[0].Value)
+
+	s.Require().Len(file.Rules, 3)
+	s.Equal("932011", file.Rules[0].Id())
+	s.Equal("932100", file.Rules[1].Id())
+	s.Equal("932999", file.Rules[2].Id())
+	s.Equal(secAction, file.Rules[2].Directive.Name)
+}
+
+func (s *parserTestSuite) TestParse_Rule() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+
+	rule := file.Rules[1]
+	s.Equal(10, rule.Directive.Line)
+	s.Equal("REQUEST_COOKIES|ARGS_NAMES|ARGS", rule.Variables.Value)
+	s.Equal("rx", rule.Operator.Name)
+	s.False(rule.Operator.Negated)
+	s.Equal(`(?i)(?:;|\|)\s*(?:cat|id)\b`, rule.Operator.Argument.Value)
+	s.Equal(rule.Operator.Argument.Value, string(file.Contents[rule.Operator.Argument.Span.Start:rule.Operator.Argument.Span.End]))
+
+	names := []string{}
+	for _, action := range rule.Actions {
+		names = append(names, action.Name)
+	}
+	s.Equal([]string{"id", "phase", "block", "t", "t", "msg", "chain"}, names)
+	msg := rule.Action("msg")
+	s.Equal("Remote Command Execution: Unix Command Injection", msg.Argument)
+	s.Equal("msg:'Remote Command Execution: Unix Command Injection'", string(file.Contents[msg.Span.Start:msg.Span.End]))
+	s.Equal("cmdLine", rule.Action("t").Argument)
+}
+
+func (s *parserTestSuite) TestParse_Chain() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+
+	rule := file.Rules[1]
+	s.Require().Len(rule.Chain, 2)
+	s.Equal("", rule.Chain[0].Id())
+	s.Equal("pm", rule.Chain[0].Operator.Name)
+	s.True(rule.Chain[0].Operator.Negated)
+	s.Equal("foo bar", rule.Chain[0].Operator.Argument.Value)
+	s.Equal("rx", rule.Chain[1].Operator.Name)
+	s.Equal("^curl", rule.Chain[1].Operator.Argument.Value)
+	s.Empty(rule.Chain[1].Chain)
+}
+
+func (s *parserTestSuite) TestFindRule() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+
+	rule, err := file.FindRule("932100", 2)
+	s.Require().NoError(err)
+	s.Equal("^curl", rule.Operator.Argument.Value)
+
+	_, err = file.FindRule("932100", 3)
+	s.EqualError(err, "rule 932100 has 2 chained rules, chain offset 3 is out of range")
+
+	_, err = file.FindRule("932101", 0)
+	s.EqualError(err, "failed to find rule 932101 in rules.conf")
+}
+
+func (s *parserTestSuite) TestReplaceOperatorArgument() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+	rule, err := file.FindRule("932100", 0)
+	s.Require().NoError(err)
+
+	contents, err := file.ReplaceOperatorArgument(rule, "new")
+	s.Require().NoError(err)
+
+	expected := []byte(ruleFile)
+	start := rule.Operator.Argument.Span.Start
+	expected = append(append(expected[:start:start], "new"...), ruleFile[rule.Operator.Argument.Span.End:]...)
+	s.Equal(string(expected), string(contents))
+	s.Equal(ruleFile, string(file.Contents), "the file must not be modified")
+
+	updated, err := Parse("rules.conf", contents)
+	s.Require().NoError(err)
+	rule, err = updated.FindRule("932100", 2)
+	s.Require().NoError(err)
+	contents, err = updated.ReplaceOperatorArgument(rule, "^wget")
+	s.Require().NoError(err)
+	s.Contains(string(contents), `SecRule REQUEST_HEADERS:User-Agent "^wget" "t:lowercase"`)
+}
+
+func (s *parserTestSuite) TestReplaceOperatorArgument_SecAction() {
+	file, err := Parse("rules.conf", []byte(ruleFile))
+	s.Require().NoError(err)
+
+	_, err = file.ReplaceOperatorArgument(file.Rules[2], "new")
+	s.EqualError(err, "rules.conf:23: SecAction has no operator")
+}
+
+func (s *parserTestSuite) TestReplaceOperatorArgument_LowercaseDirective() {
+	file, err := Parse("rules.conf", []byte(`secrule ARGS "@rx foo" "id:1"`))
+	s.Require().NoError(err)
+
+	contents, err := file.ReplaceOperatorArgument(file.Rules[0], "bar")
+	s.Require().NoError(err)
+	s.Equal(`secrule ARGS "@rx bar" "id:1"`, string(contents))
+}
+
+func (s *parserTestSuite) TestParse_EscapedQuote() {
+	file, err := Parse("rules.conf", []byte(`SecRule ARGS "@rx a\"b" "id:1"`+"\r\n"))
+	s.Require().NoError(err)
+
+	s.Equal(`a\"b`, file.Rules[0].Operator.Argument.Value)
+}
+
+func (s *parserTestSuite) TestParse_ImplicitOperator() {
+	file, err := Parse("rules.conf", []byte(`SecRule ARGS "!foo" "id:1"`))
+	s.Require().NoError(err)
+
+	s.Equal("rx", file.Rules[0].Operator.Name)
+	s.True(file.Rules[0].Operator.Negated)
+	s.Equal("foo", file.Rules[0].Operator.Argument.Value)
+}
+
+func (s *parserTestSuite) TestParse_SingleQuotes() {
+	file, err := Parse("rules.conf", []byte(`SecRule ARGS '@rx a"b' 'id:1'`))
+	s.Require().NoError(err)
+
+	s.Equal(`a"b`, file.Rules[0].Operator.Argument.Value)
+	s.Equal("1", file.Rules[0].Id())
+}
+
+func (s *parserTestSuite) TestParse_UnterminatedQuoteEndsAtLineEnd() {
+	file, err := Parse("rules.conf", []byte("SecRule ARGS \"@rx foo\nSecAction \"id:1\"\n"))
+	s.Require().NoError(err)
+
+	s.Require().Len(file.Rules, 2)
+	s.Equal("foo", file.Rules[0].Operator.Argument.Value)
+	s.Equal("1", file.Rules[1].Id())
+}
+
+func (s *parserTestSuite) TestParse_UnterminatedChain() {
+	_, err := Parse("rules.conf", []byte(`SecRule ARGS "@rx foo" "id:1,chain"`))
+
+	s.EqualError(err, "rules.conf:1:1: chain of rule 1 is not terminated")
+}
+
+func (s *parserTestSuite) TestParse_WrongNumberOfArguments() {
+	_, err := Parse("rules.conf", []byte(`SecRule ARGS`))
+
+	s.EqualError(err, "rules.conf:1:1: SecRule expects 2 or 3 arguments, found 1")
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package seclang parses SecLang rule files into typed rules. All parts of a rule record their
+// byte offsets in the file, so that single parts, e.g., the argument of an operator, can be
+// rewritten while keeping the rest of the file byte for byte.
+package seclang
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Span is a range of bytes in a file, from Start (inclusive) to End (exclusive).
+type Span struct {
+	Start int
+	End   int
+}
+
+// Token is an argument of a directive. Value is the token without enclosing double quotes and
+// with line continuations removed. Escape sequences are kept as they are. Span covers the raw
+// bytes of the value, excluding the quotes.
+type Token struct {
+	Value string
+	Span  Span
+}
+
+// Directive is a configuration directive, e.g., SecRule or SecMarker, with its arguments.
+type Directive struct {
+	Name      string
+	Arguments []Token
+	Span      Span
+	// Line is the line of the start of the directive, starting at 1
+	Line int
+}
+
+// Operator is the operator of a SecRule, e.g., `!@rx foo`. Rules without an explicit operator
+// use @rx.
+type Operator struct {
+	Name     string
+	Negated  bool
+	Argument Token
+}
+
+// Action is a single action of a rule, e.g., `t:lowercase` or `msg:'foo'`. Argument is the
+// argument of the action without enclosing single quotes. Span covers the whole action.
+type Action struct {
+	Name     string
+	Argument string
+	Span     Span
+}
+
+// Rule is a SecRule or SecAction directive. Chain contains the rules chained to a chain starter,
+// in order; it is empty for all other rules.
+type Rule struct {
+	Directive *Directive
+	// Variables is empty for SecAction
+	Variables Token
+	// Operator is empty for SecAction
+	Operator Operator
+	Actions  []Action
+	Chain    []*Rule
+}
+
+// File is a parsed rule file. Rules contains the rules that aren't chained to other rules.
+type File struct {
+	Name       string
+	Contents   []byte
+	Directives []*Directive
+	Rules      []*Rule
+}
+
+// Id returns the argument of the id action, or an empty string if the rule has no id. Chained
+// rules don't have an id.
+func (r *Rule) Id() string {
+	action := r.Action("id")
+	if action == nil {
+		return ""
+	}
+	return action.Argument
+}
+
+// Action returns the last action called `name`, or nil if the rule has no such action.
+func (r *Rule) Action(name string) *Action {
+	for i := len(r.Actions) - 1; i >= 0; i-- {
+		if r.Actions[i].Name == name {
+			return &r.Actions[i]
+		}
+	}
+	return nil
+}
+
+// Chained returns the rule at `chainOffset` in the chain of the rule. Offset 0 is the rule
+// itself.
+func (r *Rule) Chained(chainOffset uint8) (*Rule, error) {
+	if chainOffset == 0 {
+		return r, nil
+	}
+	if int(chainOffset) > len(r.Chain) {
+		return nil, fmt.Errorf("rule %s has %d chained rules, chain offset %d is out of range", r.Id(), len(r.Chain), chainOffset)
+	}
+	return r.Chain[chainOffset-1], nil
+}
+
+// FindRule returns the rule with id `id` or, if `chainOffset` is greater than 0, the rule at that
+// offset in its chain.
+func (f *File) FindRule(id string, chainOffset uint8) (*Rule, error) {
+	for _, rule := range f.Rules {
+		if rule.Id() == id {
+			return rule.Chained(chainOffset)
+		}
+	}
+	return nil, fmt.Errorf("failed to find rule %s in %s", id, f.Name)
+}
+
+// Position returns the line and column of `offset`, both starting at 1.
+func (f *File) Position(offset int) (int, int) {
+	offset = min(offset, len(f.Contents))
+	line := bytes.Count(f.Contents[:offset], []byte("\n")) + 1
+	column := offset - (bytes.LastIndexByte(f.Contents[:offset], '\n') + 1) + 1
+	return line, column
+}
+
+// Replace returns the contents of the file with the bytes of `span` replaced by `replacement`.
+// The file itself is not modified.
+func (f *File) Replace(span Span, replacement string) []byte {
+	result := slices.Clip(f.Contents[:span.Start])
+	result = append(result, replacement...)
+	return append(result, f.Contents[span.End:]...)
+}
+
+// ReplaceOperatorArgument returns the contents of the file with the operator argument of `rule`
+// replaced by `argument`. `argument` is written as it is, it must be escaped by the caller.
+func (f *File) ReplaceOperatorArgument(rule *Rule, argument string) ([]byte, error) {
+	if !strings.EqualFold(rule.Directive.Name, secRule) {
+		return nil, fmt.Errorf("%s:%d: %s has no operator", f.Name, rule.Directive.Line, rule.Directive.Name)
+	}
+	return f.Replace(rule.Operator.Argument.Span, argument), nil
+}
+
+// ParseError is returned when a file isn't valid SecLang.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return e.File + ":" + strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column) + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
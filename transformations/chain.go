@@ -0,0 +1,66 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"strings"
+
+	"github.com/coreruleset/crs-toolchain/v2/seclang"
+)
+
+// Chain is a sequence of transformations, applied in order.
+type Chain struct {
+	// Names are the names of the transformations, as written in the rule
+	Names           []string
+	transformations []Transformation
+}
+
+// NewChain returns the chain of the transformations called `names`. Like in ModSecurity, 'none'
+// removes all previous transformations from the chain.
+func NewChain(names []string) (*Chain, error) {
+	chain := &Chain{Names: []string{}, transformations: []Transformation{}}
+	for _, name := range names {
+		if strings.EqualFold(name, none) {
+			chain.Names = chain.Names[:0]
+			chain.transformations = chain.transformations[:0]
+			continue
+		}
+		transformation, err := Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		chain.Names = append(chain.Names, name)
+		chain.transformations = append(chain.transformations, transformation)
+	}
+	return chain, nil
+}
+
+// ParseChain returns the chain of transformations in `actions`, the action list of a SecRule,
+// e.g., "id:932100,phase:2,t:none,t:cmdLine". The list may be enclosed in double quotes.
+func ParseChain(actions string) (*Chain, error) {
+	list, err := seclang.ParseActions(actions)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, action := range list {
+		if action.Name == "t" {
+			names = append(names, action.Argument)
+		}
+	}
+	return NewChain(names)
+}
+
+// Apply applies the transformations of the chain to `input`, in order.
+func (c *Chain) Apply(input string) string {
+	for _, transformation := range c.transformations {
+		input = transformation(input)
+	}
+	return input
+}
+
+// Len returns the number of transformations in the chain.
+func (c *Chain) Len() int {
+	return len(c.transformations)
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type chainTestSuite struct {
+	suite.Suite
+}
+
+func TestRunChainTestSuite(t *testing.T) {
+	suite.Run(t, new(chainTestSuite))
+}
+
+func (s *chainTestSuite) TestNewChain() {
+	chain, err := NewChain([]string{"urlDecodeUni", "lowercase"})
+	s.Require().NoError(err)
+
+	s.Equal([]string{"urlDecodeUni", "lowercase"}, chain.Names)
+	s.Equal(2, chain.Len())
+	s.Equal("<script>", chain.Apply("%3CSCRIPT%3e"))
+}
+
+func (s *chainTestSuite) TestNewChain_NoneResets() {
+	chain, err := NewChain([]string{"lowercase", "none", "urlDecode"})
+	s.Require().NoError(err)
+
+	s.Equal([]string{"urlDecode"}, chain.Names)
+	s.Equal("A B", chain.Apply("A%20B"))
+}
+
+func (s *chainTestSuite) TestNewChain_Unknown() {
+	_, err := NewChain([]string{"lowercase", "rot13"})
+
+	s.ErrorContains(err, "unknown transformation 'rot13'")
+}
+
+func (s *chainTestSuite) TestParseChain() {
+	chain, err := ParseChain(`"id:932100,phase:2,block,t:none,t:cmdLine, t:lowercase,msg:'Remote Command Execution: Unix, t:length',tag:'paranoia-level/1'"`)
+	s.Require().NoError(err)
+
+	s.Equal([]string{"cmdLine", "lowercase"}, chain.Names)
+}
+
+func (s *chainTestSuite) TestParseChain_EscapedQuote() {
+	chain, err := ParseChain(`msg:'it\'s, t:length',t:'removeNulls'`)
+	s.Require().NoError(err)
+
+	s.Equal([]string{"removeNulls"}, chain.Names)
+}
+
+func (s *chainTestSuite) TestParseChain_NoTransformations() {
+	chain, err := ParseChain("id:1,pass,nolog")
+	s.Require().NoError(err)
+
+	s.Equal(0, chain.Len())
+	s.Equal("ABC", chain.Apply("ABC"))
+}
+
+func (s *chainTestSuite) TestParseChain_UnterminatedQuote() {
+	_, err := ParseChain("id:1,msg:'foo")
+
+	s.ErrorContains(err, "unterminated quote")
+}
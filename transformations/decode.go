@@ -0,0 +1,346 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func urlDecode(input string) string {
+	return decodeUrl(input, false)
+}
+
+func urlDecodeUni(input string) string {
+	return decodeUrl(input, true)
+}
+
+// decodeUrl decodes %XX escapes and plus signs. Invalid escapes are kept as they are. If
+// `unicode` is set, %uXXXX escapes are decoded as well, see `unicodeByte`.
+func decodeUrl(input string, unicode bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '+':
+			sb.WriteByte(' ')
+		case c == '%' && unicode && i+5 < len(input) && (input[i+1] == 'u' || input[i+1] == 'U') && isHexString(input[i+2:i+6]):
+			sb.WriteByte(unicodeByte(input[i+2 : i+6]))
+			i += 5
+		case c == '%' && i+2 < len(input) && isHexString(input[i+1:i+3]):
+			sb.WriteByte(hexByte(input[i+1:]))
+			i += 2
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// unicodeByte returns the byte ModSecurity decodes the four hex digits of a Unicode escape
+// to: the low byte of the code point. Full width ASCII characters (U+FF01 - U+FF5E) are mapped
+// to their ASCII equivalents.
+func unicodeByte(digits string) byte {
+	c := hexByte(digits[2:])
+	if c > 0x00 && c < 0x5f && strings.EqualFold(digits[:2], "ff") {
+		c += 0x20
+	}
+	return c
+}
+
+func urlEncode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == ' ':
+			sb.WriteByte('+')
+		case c == '*' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteString(hex.EncodeToString([]byte{c}))
+		}
+	}
+	return sb.String()
+}
+
+// htmlEntityDecode decodes numeric entities (&#DDD; and &#xHH;) to a single byte, and the named
+// entities &quot;, &amp;, &lt;, &gt; and &nbsp;. The terminating semicolon is optional.
+func htmlEntityDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] == '&' {
+			if decoded, end, ok := decodeEntity(input, i+1); ok {
+				sb.WriteByte(decoded)
+				if end < len(input) && input[end] == ';' {
+					end++
+				}
+				i = end - 1
+				continue
+			}
+		}
+		sb.WriteByte(input[i])
+	}
+	return sb.String()
+}
+
+// decodeEntity decodes the HTML entity starting at `start`, just after the ampersand. Returns
+// the decoded byte and the index after the entity, excluding the semicolon.
+func decodeEntity(input string, start int) (byte, int, bool) {
+	if start < len(input) && input[start] == '#' {
+		j := start + 1
+		base := 10
+		if j < len(input) && (input[j] == 'x' || input[j] == 'X') {
+			base = 16
+			j++
+		}
+		isDigit := isDecimal
+		if base == 16 {
+			isDigit = isHex
+		}
+		k := j
+		for k < len(input) && isDigit(input[k]) {
+			k++
+		}
+		if k == j {
+			return 0, 0, false
+		}
+		// On overflow, the maximum value is returned, like strtol() does
+		value, _ := strconv.ParseUint(input[j:k], base, 64)
+		return byte(value), k, true
+	}
+
+	k := start
+	for k < len(input) && isAlphanumeric(input[k]) {
+		k++
+	}
+	switch strings.ToLower(input[start:k]) {
+	case "quot":
+		return '"', k, true
+	case "amp":
+		return '&', k, true
+	case "lt":
+		return '<', k, true
+	case "gt":
+		return '>', k, true
+	case "nbsp":
+		return 0xa0, k, true
+	}
+	return 0, 0, false
+}
+
+// jsDecode decodes JavaScript escapes: \uHHHH (see `unicodeByte`), \xHH, octal escapes and the
+// escapes of single characters. Unknown escapes decode to the escaped character.
+func jsDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\\' || i+1 >= len(input) {
+			sb.WriteByte(input[i])
+			continue
+		}
+		next := input[i+1]
+		switch {
+		case (next == 'u' || next == 'U') && i+5 < len(input) && isHexString(input[i+2:i+6]):
+			sb.WriteByte(unicodeByte(input[i+2 : i+6]))
+			i += 5
+		case (next == 'x' || next == 'X') && i+3 < len(input) && isHexString(input[i+2:i+4]):
+			sb.WriteByte(hexByte(input[i+2:]))
+			i += 3
+		case isOctal(next):
+			value, digits := decodeOctal(input[i+1:], true)
+			sb.WriteByte(value)
+			i += digits
+		default:
+			sb.WriteByte(singleCharacterEscape(next))
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// escapeSeqDecode decodes ANSI C escape sequences: \a, \b, \f, \n, \r, \t, \v, \\, \?, \', \",
+// \xHH and octal escapes. Unknown escapes are kept as they are.
+func escapeSeqDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\\' || i+1 >= len(input) {
+			sb.WriteByte(input[i])
+			continue
+		}
+		next := input[i+1]
+		switch {
+		case strings.IndexByte(`abfnrtv\?'"`, next) >= 0:
+			sb.WriteByte(singleCharacterEscape(next))
+			i++
+		case (next == 'x' || next == 'X') && i+3 < len(input) && isHexString(input[i+2:i+4]):
+			sb.WriteByte(hexByte(input[i+2:]))
+			i += 3
+		case isOctal(next):
+			value, digits := decodeOctal(input[i+1:], false)
+			sb.WriteByte(value)
+			i += digits
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return sb.String()
+}
+
+// singleCharacterEscape returns the character that the escape \`c` stands for, or `c` itself
+func singleCharacterEscape(c byte) byte {
+	switch c {
+	case 'a':
+		return '\a'
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'v':
+		return '\v'
+	}
+	return c
+}
+
+// decodeOctal decodes up to three octal digits at the start of `input`. Returns the value and
+// the number of digits. If `limitToByte` is set, three digits are only used if the value fits
+// into a byte.
+func decodeOctal(input string, limitToByte bool) (byte, int) {
+	digits := 0
+	for digits < 3 && digits < len(input) && isOctal(input[digits]) {
+		digits++
+	}
+	if limitToByte && digits == 3 && input[0] > '3' {
+		digits = 2
+	}
+	value, _ := strconv.ParseUint(input[:digits], 8, 16)
+	return byte(value), digits
+}
+
+// cssDecode decodes CSS escapes: a backslash followed by one to six hex digits, optionally
+// terminated by a white space character, decodes to the low byte of the code point (see
+// `unicodeByte`). Escaped newlines are removed, other escaped characters decode to themselves.
+func cssDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\\' {
+			sb.WriteByte(input[i])
+			continue
+		}
+		i++
+		if i >= len(input) {
+			// A trailing backslash is removed
+			break
+		}
+		digits := 0
+		for digits < 6 && i+digits < len(input) && isHex(input[i+digits]) {
+			digits++
+		}
+		switch {
+		case digits > 0:
+			sb.WriteByte(cssEscapeByte(input[i : i+digits]))
+			i += digits
+			if i < len(input) && isSpace(input[i]) {
+				i++
+			}
+			i--
+		case input[i] == '\n':
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return sb.String()
+}
+
+// cssEscapeByte returns the byte the hex digits of a CSS escape decode to
+func cssEscapeByte(digits string) byte {
+	if len(digits) == 1 {
+		return hexValue(digits[0])
+	}
+	// Only escapes of up to four significant digits can be full width ASCII characters
+	if len(digits) >= 4 && strings.Trim(digits[:len(digits)-4], "0") == "" {
+		return unicodeByte(digits[len(digits)-4:])
+	}
+	return hexByte(digits[len(digits)-2:])
+}
+
+// sqlHexDecode decodes SQL hex literals, e.g., 0x414243. Other input is kept as it is.
+func sqlHexDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] == '0' && i+3 < len(input) && (input[i+1] == 'x' || input[i+1] == 'X') && isHexString(input[i+2:i+4]) {
+			i += 2
+			for i+1 < len(input) && isHexString(input[i:i+2]) {
+				sb.WriteByte(hexByte(input[i:]))
+				i += 2
+			}
+			i--
+			continue
+		}
+		sb.WriteByte(input[i])
+	}
+	return sb.String()
+}
+
+// hexDecode decodes pairs of hex digits. Decoding stops at the first pair that isn't valid.
+func hexDecode(input string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(input) && isHexString(input[i:i+2]); i += 2 {
+		sb.WriteByte(hexByte(input[i:]))
+	}
+	return sb.String()
+}
+
+func hexEncode(input string) string {
+	return hex.EncodeToString([]byte(input))
+}
+
+// base64Decode decodes base64, up to the first character that isn't part of the base64 alphabet,
+// including padding. Incomplete trailing groups are decoded as far as possible.
+func base64Decode(input string) string {
+	end := 0
+	for end < len(input) && strings.IndexByte(base64Alphabet, input[end]) >= 0 {
+		end++
+	}
+	return decodeBase64(input[:end])
+}
+
+// base64DecodeExt decodes base64 like base64Decode, but skips characters that aren't part of the
+// base64 alphabet instead of stopping.
+func base64DecodeExt(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if strings.IndexByte(base64Alphabet, input[i]) >= 0 {
+			sb.WriteByte(input[i])
+		}
+	}
+	return decodeBase64(sb.String())
+}
+
+// decodeBase64 decodes `input`, which only contains characters of the base64 alphabet
+func decodeBase64(input string) string {
+	// A single character of a group doesn't make up a byte
+	if len(input)%4 == 1 {
+		input = input[:len(input)-1]
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(input)
+	if err != nil {
+		return ""
+	}
+	return string(decoded)
+}
+
+func base64Encode(input string) string {
+	return base64.StdEncoding.EncodeToString([]byte(input))
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// nbsp is the non-breaking space in ISO-8859-1, which ModSecurity treats as white space
+const nbsp = 0xa0
+
+// cmdLine normalizes command lines: backslashes, quotes and carets are removed, commas and
+// semicolons are replaced by spaces, runs of white space are compressed to a single space, spaces
+// before slashes and opening parentheses are removed and the input is converted to lowercase.
+func cmdLine(input string) string {
+	output := make([]byte, 0, len(input))
+	space := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch c {
+		case '"', '\'', '\\', '^':
+		case ' ', ',', ';', '\t', '\r', '\n':
+			if !space {
+				output = append(output, ' ')
+				space = true
+			}
+		case '/', '(':
+			if space {
+				output = output[:len(output)-1]
+			}
+			space = false
+			output = append(output, c)
+		default:
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			space = false
+			output = append(output, c)
+		}
+	}
+	return string(output)
+}
+
+// compressWhitespace replaces runs of white space, including non-breaking spaces, with a single
+// space.
+func compressWhitespace(input string) string {
+	var sb strings.Builder
+	space := false
+	for i := 0; i < len(input); i++ {
+		if isSpace(input[i]) || input[i] == nbsp {
+			if !space {
+				sb.WriteByte(' ')
+				space = true
+			}
+			continue
+		}
+		space = false
+		sb.WriteByte(input[i])
+	}
+	return sb.String()
+}
+
+// removeWhitespace removes all white space, including non-breaking spaces.
+func removeWhitespace(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if !isSpace(input[i]) && input[i] != nbsp {
+			sb.WriteByte(input[i])
+		}
+	}
+	return sb.String()
+}
+
+// normalizePath removes empty and '.' path segments and resolves '..' segments. A trailing slash
+// is kept.
+func normalizePath(input string) string {
+	if input == "" {
+		return input
+	}
+	cleaned := path.Clean(input)
+	if cleaned == "." {
+		return ""
+	}
+	if strings.HasSuffix(input, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// normalizePathWin behaves like normalizePath, after replacing backslashes with slashes.
+func normalizePathWin(input string) string {
+	return normalizePath(strings.ReplaceAll(input, `\`, "/"))
+}
+
+// removeComments removes C style comments (/* ... */) and HTML comments (<!-- ... -->). Unclosed
+// comments extend to the end of the input. Everything from '--' or '#' on is removed as well.
+func removeComments(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		rest := input[i:]
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			i = commentEnd(input, i+2, "*/")
+		case strings.HasPrefix(rest, "<!--"):
+			i = commentEnd(input, i+4, "-->")
+		case strings.HasPrefix(rest, "--"), rest[0] == '#':
+			return sb.String()
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return sb.String()
+}
+
+// replaceComments replaces C style comments (/* ... */) with a single space. Unclosed comments
+// extend to the end of the input.
+func replaceComments(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		if strings.HasPrefix(input[i:], "/*") {
+			i = commentEnd(input, i+2, "*/")
+			sb.WriteByte(' ')
+			continue
+		}
+		sb.WriteByte(input[i])
+	}
+	return sb.String()
+}
+
+// commentEnd returns the index of the last character of the comment whose contents start at
+// `start` and which is closed by `terminator`.
+func commentEnd(input string, start int, terminator string) int {
+	end := strings.Index(input[start:], terminator)
+	if end < 0 {
+		return len(input) - 1
+	}
+	return start + end + len(terminator) - 1
+}
+
+// removeCommentsChar removes the character sequences that start or end comments: '/*', '*/',
+// '<!--', '-->', '--' and '#'.
+func removeCommentsChar(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		rest := input[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i += 3
+		case strings.HasPrefix(rest, "-->"):
+			i += 2
+		case strings.HasPrefix(rest, "/*"), strings.HasPrefix(rest, "*/"), strings.HasPrefix(rest, "--"):
+			i++
+		case rest[0] == '#':
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return sb.String()
+}
+
+// utf8ToUnicode replaces multibyte UTF-8 characters with %uHHHH escapes. ASCII characters and
+// invalid UTF-8 sequences are kept as they are.
+func utf8ToUnicode(input string) string {
+	var sb strings.Builder
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if size > 1 {
+			fmt.Fprintf(&sb, "%%u%04x", r)
+		} else {
+			sb.WriteByte(input[i])
+		}
+		i += size
+	}
+	return sb.String()
+}
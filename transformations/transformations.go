@@ -0,0 +1,185 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+// Package transformations implements the transformation functions of SecLang (t:<name>), so that
+// rules can be evaluated without a WAF. The functions follow the behavior of ModSecurity v2,
+// which is also what CRS' regression tests are written against. Like ModSecurity, the functions
+// operate on bytes, not on runes.
+package transformations
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Transformation is a transformation function (t:<name>).
+type Transformation func(string) string
+
+// none is the name of the transformation that removes all previous transformations from a chain
+const none = "none"
+
+// transformations maps the lowercase names of the transformations to their implementations
+var transformations = map[string]Transformation{
+	"base64decode":       base64Decode,
+	"base64decodeext":    base64DecodeExt,
+	"base64encode":       base64Encode,
+	"cmdline":            cmdLine,
+	"compresswhitespace": compressWhitespace,
+	"cssdecode":          cssDecode,
+	"escapeseqdecode":    escapeSeqDecode,
+	"hexdecode":          hexDecode,
+	"hexencode":          hexEncode,
+	"htmlentitydecode":   htmlEntityDecode,
+	"jsdecode":           jsDecode,
+	"length":             length,
+	"lowercase":          lowercase,
+	"md5":                func(input string) string { sum := md5.Sum([]byte(input)); return string(sum[:]) },
+	none:                 func(input string) string { return input },
+	"normalisepath":      normalizePath,
+	"normalisepathwin":   normalizePathWin,
+	"normalizepath":      normalizePath,
+	"normalizepathwin":   normalizePathWin,
+	"removecomments":     removeComments,
+	"removecommentschar": removeCommentsChar,
+	"removenulls":        removeNulls,
+	"removewhitespace":   removeWhitespace,
+	"replacecomments":    replaceComments,
+	"replacenulls":       replaceNulls,
+	"sha1":               func(input string) string { sum := sha1.Sum([]byte(input)); return string(sum[:]) },
+	"sqlhexdecode":       sqlHexDecode,
+	"trim":               trim,
+	"trimleft":           trimLeft,
+	"trimright":          trimRight,
+	"uppercase":          uppercase,
+	"urldecode":          urlDecode,
+	"urldecodeuni":       urlDecodeUni,
+	"urlencode":          urlEncode,
+	"utf8tounicode":      utf8ToUnicode,
+}
+
+// Names returns the names of the supported transformations, lowercase and sorted.
+func Names() []string {
+	return slices.Sorted(maps.Keys(transformations))
+}
+
+// Lookup returns the transformation called `name`. Names are case insensitive, like in
+// ModSecurity.
+func Lookup(name string) (Transformation, error) {
+	transformation, ok := transformations[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown transformation '%s', expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return transformation, nil
+}
+
+func length(input string) string {
+	return strconv.Itoa(len(input))
+}
+
+func lowercase(input string) string {
+	return mapBytes(input, func(c byte) byte {
+		if c >= 'A' && c <= 'Z' {
+			return c + 'a' - 'A'
+		}
+		return c
+	})
+}
+
+func uppercase(input string) string {
+	return mapBytes(input, func(c byte) byte {
+		if c >= 'a' && c <= 'z' {
+			return c - ('a' - 'A')
+		}
+		return c
+	})
+}
+
+func removeNulls(input string) string {
+	return strings.ReplaceAll(input, "\x00", "")
+}
+
+func replaceNulls(input string) string {
+	return strings.ReplaceAll(input, "\x00", " ")
+}
+
+func trim(input string) string {
+	return trimRight(trimLeft(input))
+}
+
+func trimLeft(input string) string {
+	i := 0
+	for i < len(input) && isSpace(input[i]) {
+		i++
+	}
+	return input[i:]
+}
+
+func trimRight(input string) string {
+	i := len(input)
+	for i > 0 && isSpace(input[i-1]) {
+		i--
+	}
+	return input[:i]
+}
+
+// mapBytes returns `input` with every byte replaced by the result of `mapping`
+func mapBytes(input string, mapping func(byte) byte) string {
+	output := []byte(input)
+	for i, c := range output {
+		output[i] = mapping(c)
+	}
+	return string(output)
+}
+
+// isSpace reports whether `c` is white space, in the sense of C's isspace()
+func isSpace(c byte) bool {
+	return c == ' ' || (c >= '\t' && c <= '\r')
+}
+
+func isAlphanumeric(c byte) bool {
+	return isDecimal(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDecimal(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// hexValue returns the value of the hex digit `c`
+func hexValue(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	default:
+		return c - '0'
+	}
+}
+
+// hexByte returns the byte encoded by the two hex digits at the start of `input`
+func hexByte(input string) byte {
+	return hexValue(input[0])<<4 | hexValue(input[1])
+}
+
+// isHexString reports whether `input` consists of hex digits only
+func isHexString(input string) bool {
+	for i := 0; i < len(input); i++ {
+		if !isHex(input[i]) {
+			return false
+		}
+	}
+	return true
+}
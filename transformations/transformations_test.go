@@ -0,0 +1,187 @@
+// Copyright 2026 OWASP Core Rule Set Project
+// SPDX-License-Identifier: Apache-2.0
+
+package transformations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type transformationsTestSuite struct {
+	suite.Suite
+}
+
+func TestRunTransformationsTestSuite(t *testing.T) {
+	suite.Run(t, new(transformationsTestSuite))
+}
+
+// transformationTest is an input of a transformation and the expected output
+type transformationTest struct {
+	input    string
+	expected string
+}
+
+func (s *transformationsTestSuite) TestTransformations() {
+	tests := map[string][]transformationTest{
+		"base64Decode": {
+			{"VGVzdENhc2U=", "TestCase"},
+			{"VGVzdENhc2U", "TestCase"},
+			{"VGVzdA==Q2FzZQ==", "Test"},
+			{"VGVzd.ENhc2U=", "Tes"},
+			{"", ""},
+		},
+		"base64DecodeExt": {
+			{"VGVzd.ENhc2U=", "TestCase"},
+			{"VGVz dENh\nc2U", "TestCase"},
+		},
+		"base64Encode": {
+			{"TestCase", "VGVzdENhc2U="},
+		},
+		"cmdLine": {
+			{`C^OMMAND /c "dir"`, "command/c dir"},
+			{"ec\\h'o' 'te'st;\t ,id", "echo test id"},
+			{"cat  (/etc/passwd)", "cat(/etc/passwd)"},
+		},
+		"compressWhitespace": {
+			{"a \t b\n\nc\xa0 d", "a b c d"},
+			{" a ", " a "},
+		},
+		"cssDecode": {
+			{`\3c script\3e`, "<script>"},
+			{`\00003cscript`, "<script"},
+			{`\ff1cscript`, "<script"},
+			{`\1ff1cscript`, "\x1cscript"},
+			{"a\\\nb\\'c\\", "ab'c"},
+			{`\a`, "\x0a"},
+		},
+		"escapeSeqDecode": {
+			{`\a\b\f\n\r\t\v\\\?\'\"`, "\a\b\f\n\r\t\v\\?'\""},
+			{`\x41\101\0\8\z`, "AA\x00\\8\\z"},
+			{`\x4`, `\x4`},
+		},
+		"hexDecode": {
+			{"414243", "ABC"},
+			{"41424", "AB"},
+			{"41zz43", "A"},
+		},
+		"hexEncode": {
+			{"ABC", "414243"},
+		},
+		"htmlEntityDecode": {
+			{"&lt;script&gt;", "<script>"},
+			{"&#60&#x3C;&#X3c;&QUOT;&amp;&nbsp;", "<<<\"&\xa0"},
+			{"&#;&#x;&foo;&", "&#;&#x;&foo;&"},
+			{"&#256;&#99999999999999999999999;", "\x00\xff"},
+		},
+		"jsDecode": {
+			{`\u003cscript\x3e`, "<script>"},
+			{`\uff1c\101\1011\400\n\q\`, "<AA1\x200\nq\\"},
+			{`\u00`, "u00"},
+		},
+		"length": {
+			{"abc", "3"},
+			{"", "0"},
+		},
+		"lowercase": {
+			{"ABC def ÄÖ", "abc def ÄÖ"},
+		},
+		"md5": {
+			{"", "\xd4\x1d\x8c\xd9\x8f\x00\xb2\x04\xe9\x80\x09\x98\xec\xf8\x42\x7e"},
+		},
+		"none": {
+			{"ABC", "ABC"},
+		},
+		"normalizePath": {
+			{"/a/b/./c/../../d//e/", "/a/d/e/"},
+			{"../../etc/passwd", "../../etc/passwd"},
+			{"/../etc/passwd", "/etc/passwd"},
+			{"./", ""},
+			{"/", "/"},
+		},
+		"normalizePathWin": {
+			{`C:\windows\..\system32\cmd.exe`, "C:/system32/cmd.exe"},
+		},
+		"removeComments": {
+			{"sel/* comment */ect", "select"},
+			{"a<!-- comment -->b", "ab"},
+			{"a/* unclosed", "a"},
+			{"1 or 1=1-- x", "1 or 1=1"},
+			{"1 or 1=1# x", "1 or 1=1"},
+		},
+		"removeCommentsChar": {
+			{"sel/*x*/ect<!--y-->--#", "selxecty"},
+		},
+		"removeNulls": {
+			{"a\x00b\x00", "ab"},
+		},
+		"removeWhitespace": {
+			{"a \t b\n\nc\xa0d", "abcd"},
+		},
+		"replaceComments": {
+			{"sel/* comment */ect", "sel ect"},
+			{"a/* unclosed", "a "},
+		},
+		"replaceNulls": {
+			{"a\x00b", "a b"},
+		},
+		"sha1": {
+			{"", "\xda\x39\xa3\xee\x5e\x6b\x4b\x0d\x32\x55\xbf\xef\x95\x60\x18\x90\xaf\xd8\x07\x09"},
+		},
+		"sqlHexDecode": {
+			{"select 0x414243,0x4", "select ABC,0x4"},
+			{"0x4142x", "ABx"},
+		},
+		"trim": {
+			{" \t a b \n", "a b"},
+		},
+		"trimLeft": {
+			{" \t a b \n", "a b \n"},
+		},
+		"trimRight": {
+			{" \t a b \n", " \t a b"},
+		},
+		"uppercase": {
+			{"abc DEF", "ABC DEF"},
+		},
+		"urlDecode": {
+			{"a+b%3bc%3B", "a b;c;"},
+			{"%zz%4%", "%zz%4%"},
+			{"%u0041", "%u0041"},
+		},
+		"urlDecodeUni": {
+			{"%u0041%U0042%41", "ABA"},
+			{"%uff1c%u20ac%u00", "<\xac%u00"},
+		},
+		"urlEncode": {
+			{"a b*<é", "a+b*%3c%c3%a9"},
+		},
+		"utf8toUnicode": {
+			{"aé€😀\xff", "a%u00e9%u20ac%u1f600\xff"},
+		},
+	}
+
+	for name, cases := range tests {
+		transformation, err := Lookup(name)
+		s.Require().NoError(err)
+		for _, test := range cases {
+			s.Equal(test.expected, transformation(test.input), "%s(%q)", name, test.input)
+		}
+	}
+}
+
+func (s *transformationsTestSuite) TestLookup_CaseInsensitive() {
+	transformation, err := Lookup("URLDECODEUNI")
+	s.Require().NoError(err)
+	s.Equal("a b", transformation("a%20b"))
+
+	_, err = Lookup("normalisePath")
+	s.NoError(err)
+}
+
+func (s *transformationsTestSuite) TestLookup_Unknown() {
+	_, err := Lookup("rot13")
+
+	s.ErrorContains(err, "unknown transformation 'rot13'")
+}
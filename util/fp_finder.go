@@ -84,30 +84,33 @@ func getDictionaryPath() (string, error) {
 func (t *FpFinder) FpFinder(inputFilePath string, extendedDictionaryFilePath string) error {
 	dictionaryPath, err := getDictionaryPath()
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to prepare dictionary")
+		return fmt.Errorf("failed to prepare dictionary: %w", err)
 	}
 
 	var extendedDict map[string]struct{}
 	if extendedDictionaryFilePath != "" {
 		extendedDict, err = t.loadDictionary(extendedDictionaryFilePath, 0)
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to load extended dictionary")
+			return fmt.Errorf("failed to load extended dictionary: %w", err)
 		}
 	}
 
 	// Load input file into memory
 	inputFile, err := t.loadInput(inputFilePath)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to load input file")
+		return fmt.Errorf("failed to load input file: %w", err)
 	}
 
 	wn, err := wnram.New(dictionaryPath)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to load WordNet")
+		return fmt.Errorf("failed to load WordNet: %w", err)
 	}
 
 	// Process words from inputfile, sort the output and remove duplicates
-	filteredWords := t.processWords(inputFile, wn, extendedDict, minSize)
+	filteredWords, err := t.processWords(inputFile, wn, extendedDict, minSize)
+	if err != nil {
+		return err
+	}
 
 	for _, str := range filteredWords {
 		fmt.Println(str)
@@ -222,7 +225,7 @@ func (t *FpFinder) loadInputFromStdIn() ([]string, error) {
 	logger.Trace().Msg("Reading from stdin")
 	words, err := t.wordsFromInput(os.Stdin)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to read from stdin")
+		return nil, fmt.Errorf("failed to read from stdin: %w", err)
 	}
 	return words, nil
 }
@@ -253,9 +256,12 @@ func (t *FpFinder) wordsFromInput(reader io.Reader) ([]string, error) {
 	return content, nil
 }
 
-func (t *FpFinder) processWords(inputFile []string, wn WordNet, extendedDict map[string]struct{}, minSize int) []string {
+func (t *FpFinder) processWords(inputFile []string, wn WordNet, extendedDict map[string]struct{}, minSize int) ([]string, error) {
 	// Filter words not in the dictionary
-	filteredWords := t.filterContent(inputFile, wn, extendedDict, minSize)
+	filteredWords, err := t.filterContent(inputFile, wn, extendedDict, minSize)
+	if err != nil {
+		return nil, err
+	}
 
 	// Sort words alphabetically (case-insensitive)
 	slices.SortFunc(filteredWords, func(a, b string) int {
@@ -265,10 +271,10 @@ func (t *FpFinder) processWords(inputFile []string, wn WordNet, extendedDict map
 	// Remove adjacent duplicate words from the sorted list
 	filteredWords = slices.Compact(filteredWords)
 
-	return filteredWords
+	return filteredWords, nil
 }
 
-func (t *FpFinder) filterContent(inputFile []string, wn WordNet, extendedDict map[string]struct{}, minSize int) []string {
+func (t *FpFinder) filterContent(inputFile []string, wn WordNet, extendedDict map[string]struct{}, minSize int) ([]string, error) {
 	var commentPattern = regexp.MustCompile(`^\s*#`)
 	var filteredWords []string
 	for _, word := range inputFile {
@@ -282,7 +288,7 @@ func (t *FpFinder) filterContent(inputFile []string, wn WordNet, extendedDict ma
 		// Check if the word exists in WordNet
 		found, err := wn.Lookup(wnram.Criteria{Matching: word})
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to lookup word in WordNet")
+			return nil, fmt.Errorf("failed to lookup word %q in WordNet: %w", word, err)
 		}
 
 		// If the word is not in the dictionary and extended dictionary, add it to the filtered list
@@ -297,5 +303,5 @@ func (t *FpFinder) filterContent(inputFile []string, wn WordNet, extendedDict ma
 		}
 	}
 
-	return filteredWords
+	return filteredWords, nil
 }
@@ -56,7 +56,8 @@ func (s *fpFinderTestSuite) TestFpFinder_FilterContent() {
 	}
 	expected := []string{"banana"}
 
-	result := NewFpFinder().filterContent(input, mockWN, extendedDict, 3)
+	result, err := NewFpFinder().filterContent(input, mockWN, extendedDict, 3)
+	s.Require().NoError(err)
 	s.Equal(expected, result)
 }
 
@@ -74,7 +75,8 @@ func (s *fpFinderTestSuite) TestFpFinder_ProcessWords() {
 
 	expected := []string{"banana", "pear"}
 
-	result := NewFpFinder().processWords(input, mockWN, extendedDict, 3)
+	result, err := NewFpFinder().processWords(input, mockWN, extendedDict, 3)
+	s.Require().NoError(err)
 
 	s.Equal(expected, result)
 }
@@ -91,7 +93,8 @@ func (s *fpFinderTestSuite) TestFpFinder_ProcessWords_Sorting() {
 
 	expected := []string{".dotfruit", ".hiddenfruit", "Apple", "Banana", "banana", "kiwi", "pear"}
 
-	result := NewFpFinder().processWords(input, mockWN, extendedDict, 3)
+	result, err := NewFpFinder().processWords(input, mockWN, extendedDict, 3)
+	s.Require().NoError(err)
 
 	s.Equal(expected, result)
 }
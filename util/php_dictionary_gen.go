@@ -415,7 +415,10 @@ func (p *PhpDictionaryGen) Generate(ctx context.Context, ctxt *crsctx.Context, o
 
 	// Classify: English words vs. non-English
 	logger.Info().Msg("Classifying PHP function names")
-	englishWords, nonEnglishWords := p.classifyFunctions(functions, wn)
+	englishWords, nonEnglishWords, err := p.classifyFunctions(functions, wn)
+	if err != nil {
+		return fmt.Errorf("classifying functions: %w", err)
+	}
 	logger.Info().Msgf("Found %d English words and %d non-English function names",
 		len(englishWords), len(nonEnglishWords))
 
@@ -755,10 +758,13 @@ func (p *PhpDictionaryGen) ExtractFunctions(phpRepoPath string) ([]string, error
 
 // classifyFunctions separates functions into English words (for 933161) and
 // non-English words (for frequency-based classification into 933150/933151).
-func (p *PhpDictionaryGen) classifyFunctions(functions []string, wn WordNet) (english, nonEnglish []string) {
+func (p *PhpDictionaryGen) classifyFunctions(functions []string, wn WordNet) (english, nonEnglish []string, err error) {
 	fpf := NewFpFinder()
 	// filterContent retains words NOT in WordNet (non-English)
-	nonEnglish = fpf.filterContent(functions, wn, map[string]struct{}{}, 1)
+	nonEnglish, err = fpf.filterContent(functions, wn, map[string]struct{}{}, 1)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// English words are those in functions but not in nonEnglish
 	nonEnglishSet := make(map[string]struct{}, len(nonEnglish))
@@ -771,7 +777,7 @@ func (p *PhpDictionaryGen) classifyFunctions(functions []string, wn WordNet) (en
 		}
 	}
 
-	return english, nonEnglish
+	return english, nonEnglish, nil
 }
 
 // frequencyLookupParams bundles the tunable parameters for a single
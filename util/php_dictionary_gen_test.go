@@ -183,7 +183,8 @@ func (s *phpDictionaryGenTestSuite) TestClassifyFunctions() {
 	}
 
 	functions := []string{"apple", "preg_match", "array_map"}
-	english, nonEnglish := s.gen.classifyFunctions(functions, mockWN)
+	english, nonEnglish, err := s.gen.classifyFunctions(functions, mockWN)
+	s.Require().NoError(err)
 
 	s.Contains(english, "apple")
 	s.NotContains(nonEnglish, "apple")
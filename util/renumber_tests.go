@@ -16,6 +16,7 @@ import (
 
 	"github.com/coreruleset/crs-toolchain/v2/context"
 	"github.com/coreruleset/crs-toolchain/v2/regex"
+	"github.com/coreruleset/crs-toolchain/v2/utils"
 )
 
 var logger = log.With().Str("component", "renumber-tests").Logger()
@@ -26,12 +27,24 @@ func (t *TestNumberingError) Error() string {
 	return "Tests are not properly numbered"
 }
 
-type TestRenumberer struct{}
+type TestRenumberer struct {
+	processed   []string
+	misnumbered []MisnumberedFile
+}
+
+// MisnumberedFile is a test file that is not properly numbered. `Line` is the first line that
+// needs to be renumbered, starting at 1.
+type MisnumberedFile struct {
+	Path string
+	Line int
+}
 
 func NewTestRenumberer() *TestRenumberer {
 	return &TestRenumberer{}
 }
 
+// RenumberTests renumbers all test files. With `gitHubOutput`, misnumbered files are annotated
+// for GitHub. Printing a summary of the run is left to the caller.
 func (t *TestRenumberer) RenumberTests(checkOnly bool, gitHubOutput bool, ctxt *context.Context) error {
 	failed := false
 	err := filepath.WalkDir(ctxt.RegressionTestsDir(), func(path string, d fs.DirEntry, err error) error {
@@ -44,7 +57,7 @@ func (t *TestRenumberer) RenumberTests(checkOnly bool, gitHubOutput bool, ctxt *
 			return nil
 		}
 
-		if err := t.processFile(path, checkOnly, gitHubOutput); err != nil {
+		if err := t.processFile(path, checkOnly, gitHubOutput, ctxt.RootDir()); err != nil {
 			failed = true
 			// continue
 			return nil
@@ -57,26 +70,36 @@ func (t *TestRenumberer) RenumberTests(checkOnly bool, gitHubOutput bool, ctxt *
 		return err
 	}
 	if failed {
-		if gitHubOutput {
-			fmt.Println("::error::All test files need to be properly numbered.",
-				"Please run `crs-toolchain util renumber-tests --all`")
-		}
 		return &TestNumberingError{}
 	}
 	return nil
 }
 
+// ProcessedFiles returns the paths of the test files that were processed, in order.
+func (t *TestRenumberer) ProcessedFiles() []string {
+	return t.processed
+}
+
+// MisnumberedFiles returns the test files that were found not to be properly numbered when
+// checking only, in the order they were processed.
+func (t *TestRenumberer) MisnumberedFiles() []MisnumberedFile {
+	return t.misnumbered
+}
+
 func (t *TestRenumberer) RenumberTest(filePath string, checkOnly bool, ctxt *context.Context) error {
-	return t.processFile(filePath, checkOnly, false)
+	return t.processFile(filePath, checkOnly, false, ctxt.RootDir())
 }
 
-func (t *TestRenumberer) processFile(filePath string, checkOnly bool, gitHubOutput bool) error {
+// processFile renumbers the tests in the file at `filePath`. With `gitHubOutput`, a misnumbered
+// file is annotated at its first misnumbered line, relative to `rootDir`.
+func (t *TestRenumberer) processFile(filePath string, checkOnly bool, gitHubOutput bool, rootDir string) error {
 	found := regex.RuleIdTestFileNameRegex.FindStringSubmatch(path.Base(filePath))
 	if found == nil {
 		// Skip other files
 		return nil
 	}
 	ruleId := found[1]
+	t.processed = append(t.processed, filePath)
 
 	logger.Info().Msgf("Processing %s", ruleId)
 
@@ -94,11 +117,18 @@ func (t *TestRenumberer) processFile(filePath string, checkOnly bool, gitHubOutp
 		return nil
 	}
 
+	line := utils.FirstDifferentLine(contents, output)
 	if gitHubOutput {
-		fmt.Printf("::warning::Test file not properly numbered: %s\n", path.Base(filePath))
+		fileName, err := filepath.Rel(rootDir, filePath)
+		if err != nil {
+			fileName = filePath
+		}
+		fmt.Println(utils.GitHubAnnotation("warning", fileName, line, 0,
+			fmt.Sprintf("Test file not properly numbered: %s", path.Base(filePath))))
 	}
 
 	if checkOnly {
+		t.misnumbered = append(t.misnumbered, MisnumberedFile{Path: filePath, Line: line})
 		return &TestNumberingError{}
 	}
 
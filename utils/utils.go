@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-getter/v2"
@@ -27,6 +28,50 @@ func IsEscaped(input string, position int) bool {
 	return escapeCounter%2 != 0
 }
 
+// GitHubAnnotation returns a GitHub Actions workflow command that creates an annotation of kind
+// `command`, i.e., "error", "warning" or "notice", for `message`. The annotation points to
+// `file`, `line` and `column`, where empty values are omitted.
+func GitHubAnnotation(command string, file string, line int, column int, message string) string {
+	properties := []string{}
+	if file != "" {
+		properties = append(properties, "file="+escapeGitHubProperty(file))
+		if line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", line))
+			if column > 0 {
+				properties = append(properties, fmt.Sprintf("col=%d", column))
+			}
+		}
+	}
+	annotation := "::" + command
+	if len(properties) > 0 {
+		annotation += " " + strings.Join(properties, ",")
+	}
+	return annotation + "::" + escapeGitHubData(message)
+}
+
+// FirstDifferentLine returns the first line, starting at 1, in which `first` and `second` differ,
+// or 0 if they are equal.
+func FirstDifferentLine(first []byte, second []byte) int {
+	line := 1
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i >= len(first) || i >= len(second) || first[i] != second[i] {
+			return line
+		}
+		if first[i] == '\n' {
+			line++
+		}
+	}
+	return 0
+}
+
+func escapeGitHubData(data string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(data)
+}
+
+func escapeGitHubProperty(property string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeGitHubData(property))
+}
+
 func DownloadFile(filepath, url string) error {
 	request := &getter.Request{
 		Src:     url,
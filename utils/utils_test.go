@@ -204,3 +204,24 @@ func (s *utilsTestSuite) TestGetLatestGitHubRelease_Timeout() {
 
 	s.Error(err)
 }
+
+func (s *utilsTestSuite) TestGitHubAnnotation() {
+	s.Equal("::error file=rules/REQUEST-932.conf,line=12,col=20::Regex of 932100 has changed",
+		GitHubAnnotation("error", "rules/REQUEST-932.conf", 12, 20, "Regex of 932100 has changed"))
+	s.Equal("::warning file=regex-assembly/932100.ra::not formatted",
+		GitHubAnnotation("warning", "regex-assembly/932100.ra", 0, 3, "not formatted"))
+	s.Equal("::notice::all good", GitHubAnnotation("notice", "", 1, 1, "all good"))
+}
+
+func (s *utilsTestSuite) TestGitHubAnnotation_Escapes() {
+	s.Equal("::error file=a%3Ab%2Cc.ra,line=1::100%25%0Adone",
+		GitHubAnnotation("error", "a:b,c.ra", 1, 0, "100%\ndone"))
+}
+
+func (s *utilsTestSuite) TestFirstDifferentLine() {
+	s.Equal(0, FirstDifferentLine([]byte("a\nb\n"), []byte("a\nb\n")))
+	s.Equal(1, FirstDifferentLine([]byte("a\nb\n"), []byte("x\nb\n")))
+	s.Equal(2, FirstDifferentLine([]byte("a\nb\n"), []byte("a\nc\n")))
+	s.Equal(3, FirstDifferentLine([]byte("a\nb\n"), []byte("a\nb\nc")))
+	s.Equal(2, FirstDifferentLine([]byte("a\nb"), []byte("a\nb\n")))
+}